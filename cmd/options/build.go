@@ -3,7 +3,10 @@
 package options
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"lunchpail.io/pkg/build"
 )
@@ -14,11 +17,68 @@ func AddBuildOptions(cmd *cobra.Command) (*build.Options, error) {
 		return nil, err
 	}
 
-	AddCallingConventionOptionsTo(cmd, &options)
+	addBuildOptionsTo(cmd, &options)
+	return &options, nil
+}
+
+// Layer the build options given on the command line of cmd over those
+// of a prior build, e.g. one being re-built via build --from
+func OverlayBuildOptions(cmd *cobra.Command, prior build.Options) (build.Options, error) {
+	// Bind a second copy of our flags to the prior options, and
+	// replay onto it those the user changed
+	layered := &cobra.Command{}
+	addBuildOptionsTo(layered, &prior)
+
+	var err error
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		to := layered.Flags().Lookup(f.Name)
+		if to == nil || err != nil {
+			return
+		}
+
+		switch v := f.Value.(type) {
+		case pflag.SliceValue:
+			err = to.Value.(pflag.SliceValue).Replace(v.GetSlice())
+		default:
+			value := v.String()
+			if v.Type() == "stringToString" {
+				// e.g. [a=b,c=d]
+				value = strings.Trim(value, "[]")
+			}
+			err = to.Value.Set(value)
+		}
+	})
+	if err != nil {
+		return prior, err
+	}
+
+	// As with RestoreOptionsWithCliOverlay, `--set x=3 --set x=4`
+	// results in x having value 4, so the prior values go first
+	if set, err := cmd.Flags().GetStringSlice("set"); err != nil {
+		return prior, err
+	} else {
+		prior.OverrideValues = append(prior.OverrideValues, set...)
+	}
+	if setFile, err := cmd.Flags().GetStringSlice("set-file"); err != nil {
+		return prior, err
+	} else {
+		prior.OverrideFileValues = append(prior.OverrideFileValues, setFile...)
+	}
+
+	return prior, nil
+}
+
+func addBuildOptionsTo(cmd *cobra.Command, options *build.Options) {
+	AddCallingConventionOptionsTo(cmd, options)
 
 	cmd.Flags().StringVarP(&options.ImagePullSecret, "image-pull-secret", "s", options.ImagePullSecret, "Of the form <user>:<token>@ghcr.io")
 	cmd.Flags().StringVar(&options.Queue, "queue", options.Queue, "Use the queue defined by this Secret (data: accessKeyID, secretAccessKey, endpoint)")
-	cmd.Flags().BoolVar(&options.HasGpuSupport, "gpu", options.HasGpuSupport, "Run with GPUs (if supported by the application)")
+	cmd.Flags().StringVar(&options.QueueSecret, "queue-secret", options.QueueSecret, "Take the queue credentials (accessKeyID, secretAccessKey, and optionally endpoint and bucket) from this secret reference, i.e. k8s://[namespace/]name, vault://mount/path, age://file, or sops://file")
+	cmd.Flags().BoolVar(&options.QueueTLS, "queue-tls", options.QueueTLS, "Serve the internal queue over TLS, using a certificate authority generated for each run")
+	cmd.Flags().BoolVar(&options.Sandbox, "sandbox", options.Sandbox, "Confine each task handler to its workdir, its task files, and the queue port (Linux only)")
+	cmd.Flags().BoolVar(&options.SandboxUnconfinedNet, "sandbox-allow-unconfined-network", options.SandboxUnconfinedNet, "With --sandbox, run even on kernels that cannot confine network access (Landlock ABI < 4)")
+	cmd.Flags().BoolVar(&options.LocalContainers, "containers", options.LocalContainers, "On the local backend, run each component in a container using its application's image, via docker or podman (Linux only)")
+	cmd.Flags().BoolVar(&options.HasGpuSupport, "gpu", options.HasGpuSupport, "Run each worker with a GPU (the application must declare supportsGpu)")
 
 	cmd.Flags().StringSliceVar(&[]string{}, "set", []string{}, "[Advanced] override specific template values")
 	cmd.Flags().StringSliceVar(&[]string{}, "set-file", []string{}, "[Advanced] override specific template values with content from a file")
@@ -34,14 +94,28 @@ func AddBuildOptions(cmd *cobra.Command) (*build.Options, error) {
 	cmd.Flags().StringVar(&options.ImageID, "image-id", options.ImageID, "Identifier of a catalog or custom image to be used for instance creation")
 	cmd.Flags().BoolVarP(&options.CreateNamespace, "create-namespace", "N", options.CreateNamespace, "Create a new namespace, if needed")
 	cmd.Flags().IntVarP(&options.Workers, "workers", "W", options.Workers, "Number of workers in the initial worker pool")
+	cmd.Flags().StringVar(&options.KueueQueue, "kueue-queue", options.KueueQueue, "Submit worker pools on Kubernetes to this Kueue LocalQueue, which will admit them when quota is available")
+	cmd.Flags().StringVar(&options.SchedulerName, "scheduler-name", options.SchedulerName, "Gang-schedule worker pools on Kubernetes using this scheduler, e.g. scheduler-plugins or volcano")
+	cmd.Flags().BoolVar(&options.IndexedJobs, "indexed-jobs", options.IndexedJobs, "Run worker pools on Kubernetes as Indexed Jobs, with one completion index per worker")
+	cmd.Flags().DurationVar(&options.ReadyTimeout, "ready-timeout", options.ReadyTimeout, "How long to wait for worker pools on Kubernetes to become ready, before warning that they are not (default 5m)")
+	cmd.Flags().DurationVar(&options.AdmissionTimeout, "admission-timeout", options.AdmissionTimeout, "How long to report on the admission of worker pools on Kubernetes queued by --kueue-queue or --scheduler-name, before warning that they are still pending (default 10m)")
+
+	cmd.Flags().BoolVar(&options.Strict, "strict", options.Strict, "Reject unknown fields and kinds in the application YAML, rather than warning about them")
 
 	cmd.Flags().StringToStringVarP(&options.Env, "env", "e", options.Env, "Set environment variables")
 
 	cmd.Flags().IntVar(&options.Pack, "pack", options.Pack, "Run k concurrent tasks; if k=0 and machine has N cores, then k=N")
 	cmd.Flags().BoolVarP(&options.Gunzip, "gunzip", "z", options.Gunzip, "Gunzip inputs before passing them to the worker logic")
 	cmd.Flags().BoolVar(&options.AutoClean, "auto-clean", options.AutoClean, "Clean up any caches prior to exiting")
+	cmd.Flags().BoolVar(&options.Speculate, "speculate", options.Speculate, "Re-execute straggling tasks on idle workers, keeping whichever copy finishes first")
 
-	AddTargetOptionsTo(cmd, &options)
-	AddLogOptionsTo(cmd, &options)
-	return &options, nil
+	cmd.Flags().StringSliceVar(&options.Alerts.Webhooks, "alert-webhook", options.Alerts.Webhooks, "POST a JSON description of run events to this URL (not saved in built binaries)")
+	cmd.Flags().StringSliceVar(&options.Alerts.Slack, "alert-slack", options.Alerts.Slack, "POST a Slack-compatible message describing run events to this URL (not saved in built binaries)")
+	cmd.Flags().StringSliceVar(&options.Alerts.Exec, "alert-exec", options.Alerts.Exec, "Execute this command locally on run events; the event is passed as JSON on stdin")
+	cmd.Flags().StringSliceVar(&options.Alerts.On, "alert-on", options.Alerts.On, "Only alert on these events (start|first-failure|failure-rate|stall|completion)")
+	cmd.Flags().Float64Var(&options.Alerts.FailureRate, "alert-failure-rate", options.Alerts.FailureRate, "Alert if this fraction (0-1) of tasks have failed")
+	cmd.Flags().StringVar(&options.Alerts.StallTimeout, "alert-stall", options.Alerts.StallTimeout, "Alert if no task has completed in this long, e.g. 10m")
+
+	AddTargetOptionsTo(cmd, options)
+	AddLogOptionsTo(cmd, options)
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"lunchpail.io/cmd/options"
+	"lunchpail.io/pkg/build"
 	"lunchpail.io/pkg/fe/builder"
 	"lunchpail.io/pkg/fe/builder/overlay"
 )
@@ -18,6 +19,14 @@ func newBuildCmd() *cobra.Command {
 	var branchFlag string
 	var sourceIsYaml bool
 	var allFlag bool
+	var toolchainFlag bool
+	var prebuiltFlag string
+	var reproducibleFlag bool
+	var signFlag string
+	var generateKeyFlag bool
+	var fromFlag string
+	var offlinePythonFlag bool
+	var pythonPlatformsFlag []string
 
 	cmd := &cobra.Command{
 		Use:     "build [path-or-git]",
@@ -35,6 +44,16 @@ func newBuildCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&branchFlag, "branch", "b", branchFlag, "Git branch to pull from")
 	cmd.Flags().BoolVarP(&sourceIsYaml, "yaml", "y", sourceIsYaml, "The source directory contains high-level IR YAML")
 	cmd.Flags().BoolVarP(&allFlag, "all-platforms", "A", allFlag, "Generate binaries for all supported platform/arch combinations")
+	cmd.Flags().BoolVar(&toolchainFlag, "toolchain", toolchainFlag, "Compile the binary with the Go toolchain, rather than appending the application to a prebuilt lunchpail executable")
+	cmd.Flags().StringVar(&prebuiltFlag, "prebuilt", prebuiltFlag, "Directory containing prebuilt lunchpail-<os>-<arch> executables, for platforms other than this one; binaries for platforms without one (and for darwin, whose code signatures do not survive appending) are compiled with Go")
+	cmd.Flags().BoolVar(&reproducibleFlag, "reproducible", reproducibleFlag, "Produce the same binary given the same inputs, using SOURCE_DATE_EPOCH (if set) as the build date")
+	cmd.Flags().StringVar(&signFlag, "sign", signFlag, "Write a detached signature <output>.sig using the ed25519 private key in this file")
+	cmd.Flags().BoolVar(&generateKeyFlag, "generate-signing-key", generateKeyFlag, "With --sign, generate an ed25519 key pair (the public key in <key>.pub) if the key file does not exist")
+
+	cmd.Flags().StringVar(&fromFlag, "from", fromFlag, "Re-build this previously built binary, layering the given source and options over its own, and show how its application changes")
+
+	cmd.Flags().BoolVar(&offlinePythonFlag, "offline-python", offlinePythonFlag, "Bundle wheels for the application's Python requirements, so that workers install them without reaching a package index")
+	cmd.Flags().StringSliceVar(&pythonPlatformsFlag, "python-platform", pythonPlatformsFlag, "With --offline-python, bundle wheels for workers on this os/arch, e.g. linux/amd64 (default: the platforms being built for, and linux/amd64 and linux/arm64)")
 
 	var command string
 	cmd.Flags().StringVarP(&command, "command", "c", command, "Run the given program given as a string")
@@ -50,23 +69,48 @@ func newBuildCmd() *cobra.Command {
 			sourcePath = args[0]
 		}
 
-		overrideValues, err := cmd.Flags().GetStringSlice("set")
-		if err != nil {
-			return err
+		if fromFlag != "" {
+			// Re-build that binary, rather than ourselves
+			if err := build.LoadFrom(fromFlag); err != nil {
+				return err
+			} else if prior, err := build.RestoreOptions(); err != nil {
+				return err
+			} else if layered, err := options.OverlayBuildOptions(cmd, prior); err != nil {
+				return err
+			} else {
+				*buildOptions = layered
+			}
 		} else {
-			buildOptions.OverrideValues = overrideValues
+			overrideValues, err := cmd.Flags().GetStringSlice("set")
+			if err != nil {
+				return err
+			} else {
+				buildOptions.OverrideValues = overrideValues
+			}
+
+			overrideFileValues, err := cmd.Flags().GetStringSlice("set-file")
+			if err != nil {
+				return err
+			} else {
+				buildOptions.OverrideFileValues = overrideFileValues
+			}
 		}
 
-		overrideFileValues, err := cmd.Flags().GetStringSlice("set-file")
-		if err != nil {
-			return err
-		} else {
-			buildOptions.OverrideFileValues = overrideFileValues
-		}
+		// A re-build of an offline build stays offline, and so
+		// must bundle wheels for its (possibly new) requirements
+		buildOptions.OfflinePython = buildOptions.OfflinePython || offlinePythonFlag
 
 		return builder.Build(context.Background(), sourcePath, builder.Options{
-			Name:         outputFlag,
-			AllPlatforms: allFlag,
+			Name:               outputFlag,
+			AllPlatforms:       allFlag,
+			Toolchain:          toolchainFlag,
+			PrebuiltDir:        prebuiltFlag,
+			Reproducible:       reproducibleFlag,
+			SigningKey:         signFlag,
+			GenerateSigningKey: generateKeyFlag,
+			ShowDiff:           fromFlag != "",
+			OfflinePython:      buildOptions.OfflinePython,
+			PythonPlatforms:    pythonPlatformsFlag,
 			OverlayOptions: overlay.Options{
 				Branch:       branchFlag,
 				Command:      command,
@@ -18,4 +18,5 @@ func init() {
 	cmd.AddCommand(component.Worker())
 	cmd.AddCommand(component.WorkStealer())
 	cmd.AddCommand(component.RunLocally())
+	cmd.AddCommand(component.Sandbox())
 }
@@ -0,0 +1,33 @@
+package component
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/pkg/runtime/sandbox"
+)
+
+func Sandbox() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sandbox [-- command arg1 arg2 ...]",
+		Short: "Run a command confined to the given paths and ports",
+		Long:  "Run a command confined to the given paths and ports",
+	}
+
+	var policy sandbox.Policy
+	cmd.Flags().StringArrayVar(&policy.ReadWrite, "rw", nil, "Allow the command to read and write beneath this path")
+	cmd.Flags().StringArrayVar(&policy.ReadOnly, "ro", nil, "Allow the command to read beneath this path")
+	cmd.Flags().IntSliceVar(&policy.ConnectPorts, "port", nil, "Allow the command to connect to this TCP port")
+	cmd.Flags().BoolVar(&policy.AllowUnconfinedNetwork, "allow-unconfined-network", false, "Run the command even if this kernel cannot confine its network access")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("Nothing to run. Specify the command line after a --: %v", args)
+		}
+
+		return sandbox.Exec(policy, args)
+	}
+
+	return cmd
+}
@@ -44,6 +44,21 @@ func Run() *cobra.Command {
 	var gunzip bool
 	cmd.Flags().BoolVarP(&gunzip, "gunzip", "z", gunzip, "Gunzip inputs before passing them to the worker logic")
 
+	var speculate bool
+	cmd.Flags().BoolVar(&speculate, "speculate", speculate, "Claim tasks upon completion, discarding our output if a speculative copy finished first")
+
+	var dispatcher bool
+	cmd.Flags().BoolVar(&dispatcher, "dispatcher", dispatcher, "The handler dispatches tasks, and so may write to the unassigned queue")
+
+	var scopedCredentials bool
+	cmd.Flags().BoolVar(&scopedCredentials, "scoped-credentials", scopedCredentials, "Ask the queue server for scoped queue credentials for the handler, rather than letting it inherit ours")
+
+	var sandbox bool
+	cmd.Flags().BoolVar(&sandbox, "sandbox", sandbox, "Confine the handler to its workdir, its task files, and the queue")
+
+	var sandboxUnconfinedNet bool
+	cmd.Flags().BoolVar(&sandboxUnconfinedNet, "sandbox-allow-unconfined-network", sandboxUnconfinedNet, "With --sandbox, run even on kernels that cannot confine the handler's network access")
+
 	ccOpts := options.AddCallingConventionOptions(cmd)
 	logOpts := options.AddLogOptions(cmd)
 
@@ -58,14 +73,19 @@ func Run() *cobra.Command {
 		}
 
 		return worker.Run(context.Background(), args, worker.Options{
-			Pack:              pack,
-			Gunzip:            gunzip,
-			CallingConvention: ccOpts.CallingConvention,
-			StartupDelay:      startupDelay,
-			PollingInterval:   pollingInterval,
-			LogOptions:        *logOpts,
-			RunContext:        run.ForStep(step).ForPool(poolName).ForWorker(workerName),
-			WorkerStartTime:   time.Now(),
+			Pack:                 pack,
+			Gunzip:               gunzip,
+			Speculate:            speculate,
+			Dispatcher:           dispatcher,
+			ScopedCredentials:    scopedCredentials,
+			Sandbox:              sandbox,
+			SandboxUnconfinedNet: sandboxUnconfinedNet,
+			CallingConvention:    ccOpts.CallingConvention,
+			StartupDelay:         startupDelay,
+			PollingInterval:      pollingInterval,
+			LogOptions:           *logOpts,
+			RunContext:           run.ForStep(step).ForPool(poolName).ForWorker(workerName),
+			WorkerStartTime:      time.Now(),
 		})
 	}
 
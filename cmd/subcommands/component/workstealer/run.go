@@ -2,10 +2,13 @@ package workstealer
 
 import (
 	"context"
+	"os"
+
 	"github.com/spf13/cobra"
 
 	"lunchpail.io/cmd/options"
 	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/runtime/alerts"
 	"lunchpail.io/pkg/runtime/workstealer"
 )
 
@@ -23,6 +26,9 @@ func Run() *cobra.Command {
 	var selfDestruct bool
 	cmd.Flags().BoolVar(&selfDestruct, "self-destruct", false, "Automatically tear down the run when all output has been consumed?")
 
+	var speculate bool
+	cmd.Flags().BoolVar(&speculate, "speculate", false, "Re-execute straggling tasks on idle workers, keeping whichever copy finishes first")
+
 	lopts := options.AddLogOptions(cmd)
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
@@ -31,7 +37,12 @@ func Run() *cobra.Command {
 			return err
 		}
 
-		return workstealer.Run(context.Background(), run, workstealer.Options{PollingInterval: pollingInterval, SelfDestruct: selfDestruct, LogOptions: *lopts})
+		specs, err := alerts.DecodeSpecs(os.Getenv(alerts.SpecsEnvVar))
+		if err != nil {
+			return err
+		}
+
+		return workstealer.Run(context.Background(), run, workstealer.Options{PollingInterval: pollingInterval, SelfDestruct: selfDestruct, Alerts: specs, Speculate: speculate, LogOptions: *lopts})
 	}
 
 	return cmd
@@ -18,6 +18,10 @@ func init() {
 
 	cmd.AddCommand(dev.Init())
 	cmd.AddCommand(dev.Build())
+	cmd.AddCommand(dev.Lint())
+	cmd.AddCommand(dev.Validate())
+	cmd.AddCommand(dev.Schema())
+	cmd.AddCommand(dev.Migrate())
 
 	rootCmd.AddCommand(cmd)
 }
@@ -6,16 +6,42 @@ import (
 	"github.com/spf13/cobra"
 
 	"lunchpail.io/cmd/options"
+	"lunchpail.io/pkg/fe/scaffold"
+	"lunchpail.io/pkg/ir/hlir"
 	initialize "lunchpail.io/pkg/lunchpail/init"
 )
 
 func Init() *cobra.Command {
+	opts := scaffold.Options{Template: scaffold.Python, CallingConvention: hlir.CallingConventionFiles}
+
+	var cmd = &cobra.Command{
+		Use:   "init <dir>",
+		Short: "Create a new application",
+		Long:  "Create a new application, with source, test data, and requirements laid out as lunchpail build expects",
+		Args:  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	}
+
+	cmd.Flags().VarP(&opts.Template, "template", "t", "Application template [python, shell, parquet-transform]")
+	cmd.Flags().Var(&opts.CallingConvention, "calling-convention", "How the application receives each task [files, stdio]")
+	logOpts := options.AddLogOptions(cmd)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		opts.Verbose = logOpts.Verbose
+		return scaffold.Init(args[0], opts)
+	}
+
+	cmd.AddCommand(initLocal())
+	return cmd
+}
+
+func initLocal() *cobra.Command {
 	var buildFlag bool
 
 	var cmd = &cobra.Command{
-		Use:   "init",
+		Use:   "local",
 		Short: "Initialize a local control plane",
 		Long:  "Initialize a local control plane",
+		Args:  cobra.MatchAll(cobra.ExactArgs(0), cobra.OnlyValidArgs),
 	}
 
 	cmd.Flags().BoolVarP(&buildFlag, "build-images", "b", false, "Also build Lunchpail support images")
@@ -0,0 +1,46 @@
+package dev
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize/english"
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/pkg/fe/builder/overlay"
+)
+
+func Lint() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "lint [dir]",
+		Short: "Check an application source directory",
+		Long:  "Check an application source directory against the conventions of lunchpail build, e.g. for misnamed files",
+		Args:  cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		findings, err := overlay.Lint(dir)
+		if err != nil {
+			return err
+		}
+
+		nErrors := 0
+		for _, finding := range findings {
+			fmt.Println(finding)
+			if finding.IsError {
+				nErrors++
+			}
+		}
+
+		if nErrors > 0 {
+			return fmt.Errorf("Found %s in %s", english.Plural(nErrors, "error", ""), dir)
+		}
+		return nil
+	}
+
+	return cmd
+}
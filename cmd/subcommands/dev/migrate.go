@@ -0,0 +1,73 @@
+package dev
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/pkg/fe/builder/overlay"
+	"lunchpail.io/pkg/fe/migrate"
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+func Migrate() *cobra.Command {
+	var dryrunFlag bool
+
+	var cmd = &cobra.Command{
+		Use:   "migrate [file-or-dir]",
+		Short: "Rewrite application YAML to the latest apiVersion",
+		Long:  "Rewrite application YAML, in place, to the latest apiVersion (" + hlir.LatestApiVersion + "), preserving comments and templates",
+		Args:  cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	}
+
+	cmd.Flags().BoolVar(&dryrunFlag, "dry-run", dryrunFlag, "Print the migrated YAML to stdout, rather than rewriting any files")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		specs, err := overlay.YamlSpecs(path)
+		if err != nil {
+			return err
+		}
+
+		nMigrated := 0
+		for _, spec := range specs {
+			b, err := os.ReadFile(spec)
+			if err != nil {
+				return err
+			}
+
+			migrated, changed, err := migrate.Migrate(string(b))
+			if err != nil {
+				return fmt.Errorf("%s: %v", spec, err)
+			} else if !changed {
+				continue
+			}
+
+			nMigrated++
+			if dryrunFlag {
+				fmt.Printf("# %s\n%s\n", spec, migrated)
+				continue
+			}
+
+			info, err := os.Stat(spec)
+			if err != nil {
+				return err
+			} else if err := os.WriteFile(spec, []byte(migrated), info.Mode()); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Migrated %s to %s\n", spec, hlir.LatestApiVersion)
+		}
+
+		if nMigrated == 0 {
+			fmt.Fprintf(os.Stderr, "Nothing to migrate in %s\n", path)
+		}
+		return nil
+	}
+
+	return cmd
+}
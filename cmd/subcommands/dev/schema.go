@@ -0,0 +1,37 @@
+package dev
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/pkg/fe/parser"
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+func Schema() *cobra.Command {
+	apiVersionFlag := hlir.LatestApiVersion
+
+	var cmd = &cobra.Command{
+		Use:       "schema <kind>",
+		Short:     "Print the JSON Schema for a kind of application resource",
+		Long:      "Print the JSON Schema for a kind of application resource, one of " + strings.Join(parser.SchemaKinds(), ", "),
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: parser.SchemaKinds(),
+	}
+
+	cmd.Flags().StringVar(&apiVersionFlag, "api-version", apiVersionFlag, "The apiVersion of the schema, one of "+strings.Join(hlir.ApiVersions, ", "))
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		schema, err := parser.SchemaJson(apiVersionFlag, args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(schema))
+		return nil
+	}
+
+	return cmd
+}
@@ -0,0 +1,66 @@
+package dev
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dustin/go-humanize/english"
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/pkg/fe/builder/overlay"
+)
+
+func Validate() *cobra.Command {
+	var strictFlag bool
+
+	var cmd = &cobra.Command{
+		Use:   "validate [file-or-dir]",
+		Short: "Check application YAML, or an application source directory",
+		Long:  "Check application YAML against the published schemas (see dev schema), or an application source directory against the conventions of lunchpail build, without building",
+		Args:  cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	}
+
+	cmd.Flags().BoolVar(&strictFlag, "strict", strictFlag, "Treat unknown fields and kinds as errors, rather than warnings")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		var findings []overlay.Finding
+		if info, err := os.Stat(path); err != nil {
+			return err
+		} else if info.IsDir() && !overlay.HasYamlSpecs(path) {
+			// A source directory, as opposed to YAML
+			if findings, err = overlay.Lint(path); err != nil {
+				return err
+			}
+		} else {
+			f, templated, err := overlay.ValidateYaml(path, strictFlag)
+			if err != nil {
+				return err
+			}
+			findings = f
+
+			for _, spec := range templated {
+				fmt.Printf("%s: skipped, as it contains templates that are instantiated only by up\n", spec)
+			}
+		}
+
+		nErrors := 0
+		for _, finding := range findings {
+			fmt.Println(finding)
+			if finding.IsError {
+				nErrors++
+			}
+		}
+
+		if nErrors > 0 {
+			return fmt.Errorf("Found %s in %s", english.Plural(nErrors, "error", ""), path)
+		}
+		return nil
+	}
+
+	return cmd
+}
@@ -3,21 +3,52 @@
 package subcommands
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
+
 	"lunchpail.io/pkg/build"
 	"lunchpail.io/pkg/observe/info"
 )
 
 func newInfoCommand() *cobra.Command {
+	var sbomFlag bool
+	var verifyFlag string
+	var signatureFlag string
+	var diffFlag string
+
 	var cmd = &cobra.Command{
-		Use:     "info",
+		Use:     "info [binary]",
 		GroupID: applicationGroup.ID,
 		Short:   "Summary information of the application",
+		Args:    cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 && verifyFlag == "" {
+				return fmt.Errorf("A binary may be given only with --verify")
+			}
+
+			switch {
+			case verifyFlag != "":
+				binary := ""
+				if len(args) > 0 {
+					binary = args[0]
+				}
+				return info.Verify(binary, signatureFlag, verifyFlag)
+			case diffFlag != "":
+				return info.Diff(diffFlag)
+			case sbomFlag:
+				return info.BillOfMaterials()
+			}
 			return info.UI()
 		},
 	}
 
+	cmd.Flags().BoolVar(&sbomFlag, "sbom", sbomFlag, "Show the bill of materials of the application")
+	cmd.Flags().StringVar(&verifyFlag, "verify", verifyFlag, "Verify the signature of the given binary (default this one) against the trusted ed25519 public key in this file")
+	cmd.Flags().StringVar(&signatureFlag, "signature", signatureFlag, "Detached signature to verify (default <binary>.sig)")
+
+	cmd.Flags().StringVar(&diffFlag, "diff", diffFlag, "Compare the application with the one built into this other binary")
+
 	return cmd
 }
 
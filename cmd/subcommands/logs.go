@@ -5,6 +5,8 @@ package subcommands
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -13,12 +15,16 @@ import (
 	"lunchpail.io/pkg/build"
 	comp "lunchpail.io/pkg/lunchpail"
 	"lunchpail.io/pkg/observe"
+	"lunchpail.io/pkg/runtime/logs"
 )
 
 func newLogsCommand() *cobra.Command {
 	var componentsFlag []string
 	var followFlag bool
 	var tailFlag int
+	var grepFlag string
+	var taskFlag string
+	var sinceFlag time.Duration
 
 	var cmd = &cobra.Command{
 		Use:     "logs",
@@ -31,6 +37,9 @@ func newLogsCommand() *cobra.Command {
 	cmd.Flags().StringSliceVarP(&componentsFlag, "component", "c", []string{"workers"}, "Components to track (workers|dispatcher|workstealer|minio)")
 	cmd.Flags().BoolVarP(&followFlag, "follow", "f", false, "Stream the logs")
 	cmd.Flags().IntVarP(&tailFlag, "tail", "T", -1, "Lines of recent log file to display, with -1 showing all available log data")
+	cmd.Flags().StringVar(&grepFlag, "grep", "", "Search the logs archived in the run's queue for lines matching this regular expression")
+	cmd.Flags().StringVar(&taskFlag, "task", "", "Show only the output of this task, falling back to its recorded stdout and stderr if no archived logs are found")
+	cmd.Flags().DurationVar(&sinceFlag, "since", 0, "Search the logs archived in the run's queue for lines emitted within this duration, e.g. 10m")
 
 	opts, err := options.RestoreBuildOptions()
 	if err != nil {
@@ -69,6 +78,27 @@ func newLogsCommand() *cobra.Command {
 			}
 		}
 
+		if grepFlag != "" || taskFlag != "" || sinceFlag != 0 {
+			if followFlag {
+				return fmt.Errorf("The --follow option cannot be combined with --grep, --task, or --since")
+			}
+
+			search := logs.SearchOptions{Task: taskFlag, Verbose: opts.Log.Verbose}
+			if grepFlag != "" {
+				if search.Grep, err = regexp.Compile(grepFlag); err != nil {
+					return fmt.Errorf("Invalid --grep pattern: %v", err)
+				}
+			}
+			if sinceFlag != 0 {
+				search.Since = time.Now().Add(-sinceFlag)
+			}
+			if cmd.Flags().Changed("component") {
+				search.Components = comps
+			}
+
+			return observe.SearchLogs(ctx, runOpts.Run, backend, observe.SearchLogsOptions{SearchOptions: search})
+		}
+
 		return observe.Logs(ctx, runOpts.Run, backend, observe.LogsOptions{Follow: followFlag, Tail: tailFlag, Verbose: opts.Log.Verbose, Components: comps})
 	}
 
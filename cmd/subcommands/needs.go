@@ -15,6 +15,13 @@ func init() {
 	}
 
 	rootCmd.AddCommand(cmd)
+	cmd.AddCommand(needs.Cache())
+	cmd.AddCommand(needs.Conda())
+	cmd.AddCommand(needs.Hold())
+	cmd.AddCommand(needs.Java())
 	cmd.AddCommand(needs.Minio())
+	cmd.AddCommand(needs.Node())
 	cmd.AddCommand(needs.Python())
+	cmd.AddCommand(needs.R())
+	cmd.AddCommand(needs.Rclone())
 }
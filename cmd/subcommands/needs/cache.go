@@ -0,0 +1,171 @@
+package needs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/dustin/go-humanize/english"
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/pkg/runtime/needs"
+)
+
+func Cache() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Commands for managing the cache of Python virtual environments and conda environments",
+		Long:  "Commands for managing the cache of Python virtual environments and conda environments",
+	}
+
+	cmd.AddCommand(cacheLs())
+	cmd.AddCommand(cachePrune())
+	cmd.AddCommand(cacheVerify())
+	return cmd
+}
+
+func cacheLs() *cobra.Command {
+	var pathsFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List the cached Python virtual environments and conda environments",
+		Long:  "List the cached Python virtual environments and conda environments",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.Flags().BoolVarP(&pathsFlag, "paths", "p", pathsFlag, "Show the full path of each environment")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		venvs, err := needs.CachedVenvs()
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(writer, "KEY\tTYPE\tPYTHON\tSIZE\tLAST USED\tSTATUS\tREQUIREMENTS")
+		for _, venv := range venvs {
+			key := shortKey(venv)
+			if pathsFlag {
+				key = venv.Path
+			}
+
+			status := "ok"
+			if !venv.Complete {
+				status = "incomplete"
+			}
+
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", key, kind(venv), venv.Python, humanize.Bytes(uint64(venv.Bytes)), humanize.Time(venv.LastUsed), status, summarize(venv.Requirements))
+		}
+		return writer.Flush()
+	}
+
+	return cmd
+}
+
+// e.g. numpy==2.1.0, pandas (+3 more)
+func summarize(requirements string) string {
+	reqs := []string{}
+	for _, line := range strings.Split(requirements, "\n") {
+		// Drop any markers and hashes, and skip options, e.g. --index-url
+		if fields := strings.Fields(strings.Split(line, ";")[0]); len(fields) > 0 && !strings.HasPrefix(fields[0], "#") && !strings.HasPrefix(fields[0], "-") {
+			reqs = append(reqs, fields[0])
+		}
+	}
+
+	if len(reqs) > 2 {
+		return fmt.Sprintf("%s (+%d more)", strings.Join(reqs[:2], ", "), len(reqs)-2)
+	}
+	return strings.Join(reqs, ", ")
+}
+
+func kind(venv needs.CachedVenv) string {
+	if venv.Conda {
+		return "conda"
+	}
+	return "venv"
+}
+
+func shortKey(venv needs.CachedVenv) string {
+	return venv.Key[:min(12, len(venv.Key))]
+}
+
+func cachePrune() *cobra.Command {
+	var olderThanFlag time.Duration = 30 * 24 * time.Hour
+	var allFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove stale and failed Python virtual environments and conda environments from the cache",
+		Long:  "Remove stale and failed Python virtual environments and conda environments from the cache",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.Flags().DurationVar(&olderThanFlag, "older-than", olderThanFlag, "Remove environments not used in this long")
+	cmd.Flags().BoolVarP(&allFlag, "all", "a", allFlag, "Remove all environments, other than those in use")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		pruned, err := needs.PruneVenvs(needs.PruneOptions{OlderThan: olderThanFlag, All: allFlag})
+		report(pruned)
+		return err
+	}
+
+	return cmd
+}
+
+func report(removed []needs.CachedVenv) {
+	var bytes int64
+	for _, venv := range removed {
+		fmt.Printf("Removed %s %s\n", shortKey(venv), summarize(venv.Requirements))
+		bytes += venv.Bytes
+	}
+	fmt.Printf("Reclaimed %s from %s\n", humanize.Bytes(uint64(bytes)), english.Plural(len(removed), "environment", ""))
+}
+
+func cacheVerify() *cobra.Command {
+	var pruneFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check that the cached Python virtual environments and conda environments are usable",
+		Long:  "Check that the cached Python virtual environments and conda environments are usable",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.Flags().BoolVar(&pruneFlag, "prune", pruneFlag, "Remove any environments that are not usable")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		venvs, err := needs.CachedVenvs()
+		if err != nil {
+			return err
+		}
+
+		broken := []needs.CachedVenv{}
+		for _, venv := range venvs {
+			key := shortKey(venv)
+			if err := venv.Verify(context.Background()); err != nil {
+				fmt.Printf("%s broken: %v\n", key, err)
+				broken = append(broken, venv)
+			} else {
+				fmt.Printf("%s ok\n", key)
+			}
+		}
+
+		switch {
+		case len(broken) == 0:
+			return nil
+		case pruneFlag:
+			removed, err := needs.RemoveVenvs(broken)
+			report(removed)
+			return err
+		}
+
+		return fmt.Errorf("%d of %d cached environments are not usable; remove them with `needs cache verify --prune`", len(broken), len(venvs))
+	}
+
+	return cmd
+}
@@ -0,0 +1,43 @@
+package needs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/cmd/options"
+	"lunchpail.io/pkg/runtime/needs"
+)
+
+func Conda() *cobra.Command {
+	var requirements string
+	var holdFor int
+	cmd := &cobra.Command{
+		Use:   "conda <version> -r base64EncodedEnvironmentYml",
+		Short: "Install conda environment",
+		Long:  "Install conda environment, using micromamba, mamba, or conda. The version is that of micromamba, should it need to be installed.",
+		Args:  cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	}
+
+	logOpts := options.AddLogOptions(cmd)
+	cmd.Flags().StringVarP(&requirements, "requirements", "r", requirements, "Create an environment from the given environment.yml")
+	cmd.Flags().IntVar(&holdFor, "hold-for", holdFor, "Keep the environment from being pruned until the process with this pid exits")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		version := "latest"
+		if len(args) >= 1 {
+			version = args[0]
+		}
+
+		path, err := needs.InstallConda(context.Background(), version, requirements, needs.Options{LogOptions: *logOpts, HoldFor: holdFor})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(path)
+		return nil
+	}
+
+	return cmd
+}
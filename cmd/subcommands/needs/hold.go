@@ -0,0 +1,31 @@
+package needs
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/pkg/runtime/needs"
+)
+
+func Hold() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "hold <pid>",
+		Short:  "Keep a cached virtual environment in use until the given process exits",
+		Long:   "Keep a cached virtual environment in use until the given process exits",
+		Args:   cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		Hidden: true,
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		pid, err := strconv.Atoi(args[0])
+		if err != nil {
+			return err
+		}
+
+		needs.Hold(pid)
+		return nil
+	}
+
+	return cmd
+}
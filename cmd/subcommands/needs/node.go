@@ -0,0 +1,41 @@
+package needs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/cmd/options"
+	"lunchpail.io/pkg/runtime/needs"
+)
+
+func Node() *cobra.Command {
+	var requirements string
+	cmd := &cobra.Command{
+		Use:   "node <version> [-r base64EncodedPackageJson]",
+		Short: "Install node environment",
+		Long:  "Install node environment",
+		Args:  cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	}
+
+	logOpts := options.AddLogOptions(cmd)
+	cmd.Flags().StringVarP(&requirements, "requirements", "r", requirements, "Install the packages of the given package.json")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		version := "latest"
+		if len(args) >= 1 {
+			version = args[0]
+		}
+
+		path, err := needs.InstallNode(context.Background(), version, requirements, needs.Options{LogOptions: *logOpts})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(path)
+		return nil
+	}
+
+	return cmd
+}
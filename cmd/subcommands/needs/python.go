@@ -2,16 +2,23 @@ package needs
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	"lunchpail.io/cmd/options"
+	"lunchpail.io/pkg/build"
+	q "lunchpail.io/pkg/ir/queue"
 	"lunchpail.io/pkg/runtime/needs"
+	"lunchpail.io/pkg/runtime/queue"
 )
 
 func Python() *cobra.Command {
 	var requirements string
+	var offline bool
+	var holdFor int
 	cmd := &cobra.Command{
 		Use:   "python <version> [-r base64EncodedRequirements]",
 		Short: "Install python environment",
@@ -21,6 +28,8 @@ func Python() *cobra.Command {
 
 	logOpts := options.AddLogOptions(cmd)
 	cmd.Flags().StringVarP(&requirements, "requirements", "r", requirements, "Install the given requirements")
+	cmd.Flags().BoolVar(&offline, "offline", offline, "Install the requirements only from the wheels bundled with the application, never from a package index")
+	cmd.Flags().IntVar(&holdFor, "hold-for", holdFor, "Keep the virtual environment from being pruned until the process with this pid exits")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		version := "latest"
@@ -28,7 +37,22 @@ func Python() *cobra.Command {
 			version = args[0]
 		}
 
-		path, err := needs.InstallPython(context.Background(), version, requirements, needs.Options{LogOptions: *logOpts})
+		ctx := context.Background()
+		opts := needs.Options{LogOptions: *logOpts, Offline: offline, HoldFor: holdFor}
+
+		if offline && requirements != "" && !build.IsBuilt() {
+			// We do not carry the application, e.g. we are the
+			// generic lunchpail of a Kubernetes pod, so fetch
+			// its wheels from the run's queue
+			wheelhouse, cleanup, err := downloadWheelhouse(ctx, requirements, *logOpts)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			opts.Wheelhouse = wheelhouse
+		}
+
+		path, err := needs.InstallPython(ctx, version, requirements, opts)
 		if err != nil {
 			return err
 		}
@@ -39,3 +63,29 @@ func Python() *cobra.Command {
 
 	return cmd
 }
+
+func downloadWheelhouse(ctx context.Context, requirements string, opts build.LogOptions) (string, func(), error) {
+	reqmts, err := base64.StdEncoding.DecodeString(requirements)
+	if err != nil {
+		return "", nil, err
+	}
+
+	run, err := q.LoadRunContextInsideComponent("")
+	if err != nil {
+		return "", nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "lunchpail-wheelhouse-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	wheelhouse, err := queue.DownloadWheelhouse(ctx, run, reqmts, dir, opts)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return wheelhouse, cleanup, nil
+}
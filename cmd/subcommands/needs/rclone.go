@@ -0,0 +1,39 @@
+package needs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/cmd/options"
+	"lunchpail.io/pkg/runtime/needs"
+)
+
+func Rclone() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rclone <version>",
+		Short: "Install rclone",
+		Long:  "Install rclone",
+		Args:  cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	}
+
+	logOpts := options.AddLogOptions(cmd)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		version := "latest"
+		if len(args) > 0 {
+			version = args[0]
+		}
+
+		path, err := needs.InstallRclone(context.Background(), version, needs.Options{LogOptions: *logOpts})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(path)
+		return nil
+	}
+
+	return cmd
+}
@@ -0,0 +1,33 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/cmd/options"
+	q "lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/runtime/queue"
+)
+
+func DownloadCode() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "download-code <application> <localDir>",
+		Short: "Copy the binary code of an application out of the queue",
+		Long:  "Copy the binary code (e.g. compiled executables) of an application out of the queue, once it has all been uploaded",
+		Args:  cobra.MatchAll(cobra.ExactArgs(2), cobra.OnlyValidArgs),
+	}
+
+	logOpts := options.AddLogOptions(cmd)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		run, err := q.LoadRunContextInsideComponent("")
+		if err != nil {
+			return err
+		}
+
+		return queue.DownloadCode(context.Background(), run, args[0], args[1], *logOpts)
+	}
+
+	return cmd
+}
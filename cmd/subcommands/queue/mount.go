@@ -0,0 +1,27 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/cmd/options"
+	"lunchpail.io/pkg/runtime/queue"
+)
+
+func Mount() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "mount <bucket/path> <localDir>",
+		Short: "Expose data in the queue as a read-only filesystem",
+		Long:  "Expose data in the queue as a read-only filesystem, fetching it lazily as it is read",
+		Args:  cobra.MatchAll(cobra.ExactArgs(2), cobra.OnlyValidArgs),
+	}
+
+	logOpts := options.AddLogOptions(cmd)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return queue.Mount(context.Background(), args[0], args[1], *logOpts)
+	}
+
+	return cmd
+}
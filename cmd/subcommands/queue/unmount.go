@@ -0,0 +1,27 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/cmd/options"
+	"lunchpail.io/pkg/runtime/queue"
+)
+
+func Unmount() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "unmount <localDir>",
+		Short: "Tear down a filesystem made by queue mount",
+		Long:  "Tear down a filesystem made by queue mount",
+		Args:  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	}
+
+	logOpts := options.AddLogOptions(cmd)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return queue.Unmount(context.Background(), args[0], *logOpts)
+	}
+
+	return cmd
+}
@@ -32,4 +32,7 @@ func init() {
 	cmd.AddCommand(queue.Add())
 	cmd.AddCommand(queue.Done())
 	cmd.AddCommand(queue.Download())
+	cmd.AddCommand(queue.DownloadCode())
+	cmd.AddCommand(queue.Mount())
+	cmd.AddCommand(queue.Unmount())
 }
@@ -4,8 +4,7 @@ package runs
 
 import (
 	"context"
-	"fmt"
-	"sort"
+	"os"
 
 	"github.com/spf13/cobra"
 
@@ -18,6 +17,7 @@ func ListRuns() *cobra.Command {
 	var all bool
 	var name bool
 	var latest bool
+	var tags []string
 
 	var cmd = &cobra.Command{
 		Use:   "runs",
@@ -27,6 +27,7 @@ func ListRuns() *cobra.Command {
 	cmd.Flags().BoolVarP(&all, "all", "a", false, "Include terminated runs (if false, include only live runs)")
 	cmd.Flags().BoolVarP(&name, "name", "N", false, "Show only the run name")
 	cmd.Flags().BoolVarP(&latest, "latest", "l", false, "Show only the most recent run")
+	cmd.Flags().StringSliceVar(&tags, "tag", []string{}, "Show only runs with all of these tags")
 	opts, err := options.RestoreBuildOptions()
 	if err != nil {
 		panic(err)
@@ -41,45 +42,14 @@ func ListRuns() *cobra.Command {
 			return err
 		}
 
-		runs, err := backend.ListRuns(ctx, all)
+		list, err := backend.ListRuns(ctx, all)
 		if err != nil {
 			return err
 		}
 
-		return ui(runs, name, latest)
-	}
-
-	return cmd
-}
-
-// TODO move to a pkg/observe/runs/ui?
-func ui(runs []runs.Run, name, latest bool) error {
-	if len(runs) == 0 {
+		runs.Table(os.Stdout, runs.WithTags(list, tags), name, latest)
 		return nil
 	}
 
-	sort.Slice(runs, func(i, j int) bool { return runs[i].CreationTimestamp.After(runs[j].CreationTimestamp) })
-
-	if latest {
-		runs = runs[:1]
-	}
-
-	maxlen := 0
-	if !name {
-		for _, run := range runs {
-			l := len(run.Name)
-			if l > maxlen {
-				maxlen = l
-			}
-		}
-	}
-	for _, run := range runs {
-		if name {
-			fmt.Println(run.Name)
-		} else {
-			fmt.Printf("%*s %s\n", maxlen, run.Name, run.CreationTimestamp)
-		}
-	}
-
-	return nil
+	return cmd
 }
@@ -0,0 +1,34 @@
+//go:build full || manage || observe
+
+package subcommands
+
+import (
+	"github.com/spf13/cobra"
+
+	"lunchpail.io/pkg/observe/info"
+)
+
+func newVerifyCommand() *cobra.Command {
+	var keyFlag string
+	var signatureFlag string
+
+	var cmd = &cobra.Command{
+		Use:     "verify <binary>",
+		GroupID: applicationGroup.ID,
+		Short:   "Verify the signature of a built application",
+		Args:    cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return info.Verify(args[0], signatureFlag, keyFlag)
+		},
+	}
+
+	cmd.Flags().StringVarP(&keyFlag, "key", "k", keyFlag, "The trusted ed25519 public key of whoever signed the binary")
+	cmd.MarkFlagRequired("key")
+	cmd.Flags().StringVar(&signatureFlag, "signature", signatureFlag, "Detached signature to verify (default <binary>.sig)")
+
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newVerifyCommand())
+}
@@ -8,11 +8,12 @@ import (
 )
 
 // Queue properties for a given run, plus ensure access to the endpoint from this client
-func (backend Backend) AccessQueue(ctx context.Context, run queue.RunContext, queue queue.Spec, opts build.LogOptions) (endpoint, accessKeyID, secretAccessKey, bucket string, stop func(), err error) {
+func (backend Backend) AccessQueue(ctx context.Context, run queue.RunContext, queue queue.Spec, opts build.LogOptions) (endpoint, accessKeyID, secretAccessKey, bucket, ca string, stop func(), err error) {
 	endpoint = queue.Endpoint
 	accessKeyID = queue.AccessKey
 	secretAccessKey = queue.SecretKey
 	bucket = queue.Bucket
+	ca = queue.CA()
 	stop = func() {}
 	return
 }
@@ -2,10 +2,24 @@ package kubernetes
 
 import (
 	"context"
-	"io/ioutil"
+	"errors"
+	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"slices"
 	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
 
 	"lunchpail.io/pkg/ir/llir"
 	util "lunchpail.io/pkg/util/yaml"
@@ -18,65 +32,252 @@ const (
 	DeleteIt           = "delete"
 )
 
-func apply(ctx context.Context, yaml, namespace, context string, operation Operation) error {
-	yaml = strings.TrimSpace(yaml)
-	if len(yaml) == 0 {
-		// Nothing to do. If we don't short-circuit this path,
-		// kubectl complains with "error: no objects passed to
-		// apply".
-		return nil
+// We identify ourselves to the API server with this field manager
+const fieldManager = "lunchpail"
+
+// Labels we stamp on every resource we manage
+const (
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "lunchpail.io"
+	instanceLabel  = "app.kubernetes.io/instance"
+)
+
+// How long, by default, we wait for applied resources to become
+// ready before giving up on them (with a warning, not an error)
+const defaultReadinessTimeout = 5 * time.Minute
+
+// How long, by default, we watch for worker Jobs to be admitted
+// before giving up on them (with a warning, not an error)
+const defaultAdmissionTimeout = 10 * time.Minute
+
+// The yaml may be self-referential, e.g. it may include a namespace
+// spec and also use that namespace spec; same for service
+// accounts. Thus, we apply in this order, and delete in reverse.
+var kindOrder = []string{
+	"Namespace",
+	"CustomResourceDefinition",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"SecurityContextConstraints",
+	"ClusterRole",
+	"Role",
+	"ClusterRoleBinding",
+	"RoleBinding",
+	"PersistentVolumeClaim",
+	"Service",
+	"PodGroup",
+}
+
+// Applies resources to a cluster via server-side apply
+type applier struct {
+	dynamic   dynamic.Interface
+	clientset kubernetes.Interface
+	mapper    meta.ResettableRESTMapper
+	namespace string
+	runname   string
+	timeout   time.Duration
+	verbose   bool
+}
+
+func newApplier(dyn dynamic.Interface, clientset kubernetes.Interface, namespace, runname string, timeout time.Duration, verbose bool) applier {
+	if timeout <= 0 {
+		timeout = defaultReadinessTimeout
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(clientset.Discovery()))
+	return applier{dyn, clientset, mapper, namespace, runname, timeout, verbose}
+}
+
+// Parse a multi-document yaml string into a list of resources, in the
+// order they should be applied
+func parse(yaml string) ([]*unstructured.Unstructured, error) {
+	objs := []*unstructured.Unstructured{}
+
+	decoder := yamlutil.NewYAMLOrJSONDecoder(strings.NewReader(yaml), 4096)
+	for {
+		var content map[string]any
+		if err := decoder.Decode(&content); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("Invalid Kubernetes resource: %v", err)
+		}
+		if len(content) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: content}
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("Kubernetes resource is missing a kind or name: %v", content)
+		}
+		objs = append(objs, obj)
 	}
 
-	file, err := ioutil.TempFile("", "lunchpail")
+	slices.SortStableFunc(objs, func(a, b *unstructured.Unstructured) int {
+		return rank(a) - rank(b)
+	})
+
+	return objs, nil
+}
+
+func rank(obj *unstructured.Unstructured) int {
+	if idx := slices.Index(kindOrder, obj.GetKind()); idx >= 0 {
+		return idx
+	}
+	return len(kindOrder)
+}
+
+// Stamp our ownership labels on the given resource
+func (a applier) label(obj *unstructured.Unstructured) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+
+	if _, ok := labels[managedByLabel]; !ok {
+		labels[managedByLabel] = managedByValue
+	}
+	if _, ok := labels[instanceLabel]; !ok && a.runname != "" && obj.GetKind() != "Namespace" {
+		// Namespaces may be shared across runs
+		labels[instanceLabel] = a.runname
+	}
+
+	obj.SetLabels(labels)
+}
+
+// The API endpoint for the given resource
+func (a applier) resourceFor(obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		// Maybe we just applied the CustomResourceDefinition
+		a.mapper.Reset()
+		mapping, err = a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer os.Remove(file.Name())
 
-	if err := os.WriteFile(file.Name(), []byte(yaml), 0644); err != nil {
-		return err
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return a.dynamic.Resource(mapping.Resource), nil
 	}
 
-	args := []string{string(operation), "-f", file.Name(), "-n", namespace}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(a.namespace)
+	}
+	return a.dynamic.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+}
+
+func (a applier) apply(ctx context.Context, objs []*unstructured.Unstructured) error {
+	for _, obj := range objs {
+		a.label(obj)
+
+		ri, err := a.resourceFor(obj)
+		if err != nil {
+			return fmt.Errorf("Unable to apply %s %s: %v", obj.GetKind(), obj.GetName(), err)
+		}
+
+		if _, err := ri.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{FieldManager: fieldManager, Force: true}); err != nil {
+			return fmt.Errorf("Unable to apply %s %s: %v", obj.GetKind(), obj.GetName(), err)
+		}
 
-	if namespace != "" {
-		args = append(args, "-n="+namespace)
+		if a.verbose {
+			fmt.Fprintf(os.Stderr, "Applied %s %s\n", obj.GetKind(), obj.GetName())
+		}
 	}
-	if context != "" {
-		args = append(args, "--context="+context)
+
+	return a.waitForReady(ctx, objs)
+}
+
+func (a applier) delete(ctx context.Context, objs []*unstructured.Unstructured) error {
+	propagation := metav1.DeletePropagationBackground
+
+	var errs []error
+	for _, obj := range slices.Backward(objs) {
+		ri, err := a.resourceFor(obj)
+		if meta.IsNoMatchError(err) {
+			// The resource type does not exist, so neither does the resource
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("Unable to delete %s %s: %v", obj.GetKind(), obj.GetName(), err))
+			continue
+		}
+
+		if err := ri.Delete(ctx, obj.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("Unable to delete %s %s: %v", obj.GetKind(), obj.GetName(), err))
+		}
 	}
 
-	switch operation {
-	case ApplyIt:
-		// args = append(args, "--server-side")
-	case DeleteIt:
-		args = append(args, "--ignore-not-found")
+	return errors.Join(errs...)
+}
+
+// Wait for the workloads among the given resources to become
+// ready. We give them all one deadline, so that a slow trickle of
+// progress cannot keep us waiting forever. Workloads subject to queued
+// admission may take arbitrarily long to start, e.g. until quota is
+// available, so we do not wait for them here; see waitForAdmissions().
+func (a applier) waitForReady(ctx context.Context, objs []*unstructured.Unstructured) error {
+	wctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	for _, obj := range objs {
+		if pendingOn, _ := a.admissionOf(obj); pendingOn != "" {
+			continue
+		}
+
+		err := wait.PollUntilContextCancel(wctx, time.Second, true, a.isReady(obj))
+		if err != nil && ctx.Err() == nil && wctx.Err() != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s %s is not yet ready\n", obj.GetKind(), obj.GetName())
+		} else if err != nil {
+			return err
+		}
 	}
 
-	// The yaml may be self-referential, e.g. it may include a
-	// namespace spec and also use that namespace spec; same for
-	// service accounts. Thus, we may need to apply twice (n=2)
-	cmd := exec.CommandContext(ctx, "kubectl", args...)
-	cmd.Stdout = os.Stderr // intentionally so as to avoid polluting pipeline output
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return nil
 }
 
-func applyOperation(ctx context.Context, ir llir.LLIR, namespace, context string, operation Operation, copts llir.Options) error {
+// The resources of the given run, and an applier for them; no
+// resources, and a zero applier, if the run has none
+func applierFor(ctx context.Context, ir llir.LLIR, namespace string, copts llir.Options) (applier, []*unstructured.Unstructured, error) {
 	opts, err := k8sOptions(ctx, copts)
 	if err != nil {
-		return err
+		return applier{}, nil, err
 	}
 
 	yamls, err := MarshalAllComponents(ir, namespace, opts)
 	if err != nil {
-		return err
+		return applier{}, nil, err
+	}
+
+	objs, err := parse(util.Join(yamls))
+	if err != nil || len(objs) == 0 {
+		return applier{}, nil, err
+	}
+
+	clientset, config, err := Client()
+	if err != nil {
+		return applier{}, nil, err
+	}
+
+	dyn, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return applier{}, nil, err
 	}
 
-	yaml := util.Join(yamls)
-	if err := apply(ctx, yaml, namespace, context, operation); err != nil {
+	return newApplier(dyn, clientset, namespace, ir.RunName(), copts.ReadyTimeout, copts.Log != nil && copts.Log.Verbose), objs, nil
+}
+
+func applyOperation(ctx context.Context, ir llir.LLIR, namespace string, operation Operation, copts llir.Options) error {
+	a, objs, err := applierFor(ctx, ir, namespace, copts)
+	if err != nil || len(objs) == 0 {
 		return err
 	}
 
-	return nil
+	switch operation {
+	case DeleteIt:
+		return a.delete(ctx, objs)
+	default:
+		return a.apply(ctx, objs)
+	}
 }
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"encoding/base64"
 	"fmt"
 
 	"lunchpail.io/pkg/be/kubernetes/names"
@@ -24,6 +25,18 @@ func Values(ir llir.LLIR, opts Options) ([]string, error) {
 		return nil, err
 	}
 
+	queueTLSResource, err := names.QueueTLS(ir.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	var ca, cert, key string
+	if tls := ir.Queue().TLS; tls != nil {
+		ca = base64.StdEncoding.EncodeToString([]byte(tls.CA))
+		cert = base64.StdEncoding.EncodeToString([]byte(tls.Cert))
+		key = base64.StdEncoding.EncodeToString([]byte(tls.Key))
+	}
+
 	return []string{
 		"lunchpail.name=" + ir.RunName(),
 		"lunchpail.partOf=" + ir.AppName,
@@ -37,6 +50,11 @@ func Values(ir llir.LLIR, opts Options) ([]string, error) {
 		"lunchpail.taskqueue.bucket=" + ir.Queue().Bucket,
 		"lunchpail.taskqueue.accessKey=" + ir.Queue().AccessKey,
 		"lunchpail.taskqueue.secretKey=" + ir.Queue().SecretKey,
+		"lunchpail.taskqueue.secretRef=" + ir.Queue().SecretRef,
+		"lunchpail.taskqueue.tls.name=" + queueTLSResource,
+		"lunchpail.taskqueue.tls.ca=" + ca,
+		"lunchpail.taskqueue.tls.cert=" + cert,
+		"lunchpail.taskqueue.tls.key=" + key,
 		"lunchpail.image.registry=" + lunchpail.ImageRegistry,
 		"lunchpail.image.repo=" + lunchpail.ImageRepo,
 		"lunchpail.image.version=" + lunchpail.Version(),
@@ -23,6 +23,11 @@ func QueueHash(ctx llir.Context) (string, error) {
 // envPrefix for secrets injected into the containers. Since dashes
 // are not valid in bash variable names, so we avoid those here.
 func Queue(ctx llir.Context) (string, error) {
+	if ctx.Queue.SecretRef != "" {
+		// The run consumes a user-provided Secret by reference
+		return ctx.Queue.SecretRef, nil
+	}
+
 	hash, err := QueueHash(ctx)
 	if err != nil {
 		return "", err
@@ -33,3 +38,14 @@ func Queue(ctx llir.Context) (string, error) {
 	}
 	return "lp-" + hash, nil
 }
+
+// This will be used to name the Secret that holds the TLS certificate
+// and key of the internal queue
+func QueueTLS(ctx llir.Context) (string, error) {
+	queueResource, err := Queue(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return queueResource + "-tls", nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -26,7 +27,13 @@ func (backend Backend) ChangeWorkers(ctx context.Context, poolName, poolContext
 		return err
 	}
 
-	patch := []byte(fmt.Sprintf(`{"spec": {"parallelism": %d}}`, *job.Spec.Parallelism+int32(delta)))
+	parallelism := *job.Spec.Parallelism + int32(delta)
+	patch := []byte(fmt.Sprintf(`{"spec": {"parallelism": %d}}`, parallelism))
+	if job.Spec.CompletionMode != nil && *job.Spec.CompletionMode == batchv1.IndexedCompletion {
+		// Indexed Jobs have one completion per worker, and
+		// these must be scaled in lockstep
+		patch = []byte(fmt.Sprintf(`{"spec": {"parallelism": %d, "completions": %d}}`, parallelism, parallelism))
+	}
 	if _, err := jobsClient.Patch(ctx, k8sName, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
 		return err
 	}
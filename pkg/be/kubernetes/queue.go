@@ -16,8 +16,8 @@ import (
 )
 
 // Queue properties for a given run, plus ensure access to the endpoint from this client
-func (backend Backend) AccessQueue(ctx context.Context, run queue.RunContext, queue queue.Spec, opts build.LogOptions) (endpoint, accessKeyID, secretAccessKey, bucket string, stop func(), err error) {
-	endpoint, accessKeyID, secretAccessKey, bucket, err = backend.queue(ctx, run)
+func (backend Backend) AccessQueue(ctx context.Context, run queue.RunContext, queue queue.Spec, opts build.LogOptions) (endpoint, accessKeyID, secretAccessKey, bucket, ca string, stop func(), err error) {
+	endpoint, accessKeyID, secretAccessKey, bucket, ca, err = backend.queue(ctx, run)
 	if err != nil {
 		return
 	}
@@ -66,6 +66,11 @@ func (backend Backend) AccessQueue(ctx context.Context, run queue.RunContext, qu
 
 		oendpoint := endpoint
 		endpoint = fmt.Sprintf("http://localhost:%d", localPort)
+		if ca != "" {
+			// The server certificate names localhost, so
+			// that we may verify it via the port forward
+			endpoint = fmt.Sprintf("https://localhost:%d", localPort)
+		}
 
 		if opts.Verbose {
 			fmt.Fprintf(os.Stderr, "Port forwarding with endpoint=%s -> %s\n", oendpoint, endpoint)
@@ -89,11 +94,12 @@ func portFromEndpoint(endpoint string) (int, error) {
 	return port, nil
 }
 
-func (backend Backend) queue(ctx context.Context, run queue.RunContext) (endpoint, accessKeyID, secretAccessKey, bucket string, err error) {
+func (backend Backend) queue(ctx context.Context, run queue.RunContext) (endpoint, accessKeyID, secretAccessKey, bucket, ca string, err error) {
 	endpoint = os.Getenv("lunchpail_queue_endpoint")
 	accessKeyID = os.Getenv("lunchpail_queue_accessKeyID")
 	secretAccessKey = os.Getenv("lunchpail_queue_secretAccessKey")
 	bucket = os.Getenv("LUNCHPAIL_QUEUE_BUCKET")
+	ca = os.Getenv("lunchpail_queue_ca")
 	err = nil
 
 	if endpoint == "" {
@@ -146,12 +152,18 @@ func (backend Backend) queue(ctx context.Context, run queue.RunContext) (endpoin
 			secretAccessKey = string(bytes)
 		}
 
-		if bytes, ok := secret.Data["bucket"]; !ok {
+		if bytes, ok := secret.Data["bucket"]; !ok && run.Bucket == "" {
 			err = fmt.Errorf("Secret is missing 'bucket'")
 			return
+		} else if !ok {
+			// User-provided Secrets need not specify the bucket
+			bucket = run.Bucket
 		} else {
 			bucket = string(bytes)
 		}
+
+		// Present only if the internal queue serves TLS
+		ca = string(secret.Data["ca"])
 	}
 
 	return
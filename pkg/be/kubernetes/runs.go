@@ -2,7 +2,9 @@ package kubernetes
 
 import (
 	"context"
+	"slices"
 	"sort"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -10,20 +12,29 @@ import (
 
 	"lunchpail.io/pkg/be/runs"
 	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/hlir"
 )
 
 func groupByRun(pods *v1.PodList) []runs.Run {
 	runsLookup := make(map[string]runs.Run)
 	for _, pod := range pods.Items {
 		if runname, exists := pod.Labels["app.kubernetes.io/instance"]; exists {
-			if _, alreadySeen := runsLookup[runname]; !alreadySeen {
-				runsLookup[runname] = runs.Run{Name: runname, CreationTimestamp: pod.CreationTimestamp.Time}
+			run, alreadySeen := runsLookup[runname]
+			if !alreadySeen {
+				run = runs.Run{Name: runname, CreationTimestamp: pod.CreationTimestamp.Time, Tags: []string{}}
 			}
+			for label := range pod.Labels {
+				if tag, ok := strings.CutPrefix(label, hlir.TagLabelPrefix); ok && !slices.Contains(run.Tags, tag) {
+					run.Tags = append(run.Tags, tag)
+				}
+			}
+			runsLookup[runname] = run
 		}
 	}
 
 	runs := []runs.Run{}
 	for _, run := range runsLookup {
+		slices.Sort(run.Tags)
 		runs = append(runs, run)
 	}
 
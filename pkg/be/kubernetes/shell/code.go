@@ -4,6 +4,9 @@ import (
 	"encoding/base64"
 	"fmt"
 	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/dustin/go-humanize"
 
 	"lunchpail.io/pkg/ir/hlir"
 	"lunchpail.io/pkg/util"
@@ -11,11 +14,35 @@ import (
 
 type data map[string]string
 
+// The most that a ConfigMap may hold
+const maxConfigMapBytes = 1024 * 1024
+
+// Error if the given data is more than a ConfigMap may hold
+func checkConfigMapSize(application hlir.Application, d data) error {
+	total := 0
+	for _, v := range d {
+		total += len(v)
+	}
+
+	if total > maxConfigMapBytes {
+		return fmt.Errorf("Application %s has %s of code and data, more than the %s that the Kubernetes backend can carry in a ConfigMap. Consider moving it into the application's image", application.Metadata.Name, humanize.IBytes(uint64(total)), humanize.IBytes(maxConfigMapBytes))
+	}
+
+	return nil
+}
+
+// Binary code, e.g. compiled executables, does not travel in the
+// ConfigMap; rather, workers download it from the run's queue (see
+// withBinaryCode)
 func codeFromLiteral(codeSpecs []hlir.Code) (data, string) {
 	cm_data := data{}
 	cm_mount_path := ""
 
 	for _, codeSpec := range codeSpecs {
+		if codeSpec.IsBinary() {
+			continue
+		}
+
 		key := filepath.Base(codeSpec.Name)
 		cm_mount_path = filepath.Dir(codeSpec.Name) // TODO error checking for differences
 		cm_data[key] = codeSpec.Source
@@ -79,12 +106,62 @@ func code(application hlir.Application) (data, string, string, error) {
 	return d, mount_path, blob_path, nil
 }
 
-func codeB64(application hlir.Application) (string, string, string, error) {
-	data, mountPath, blobPath, err := code(application)
+// ConfigMap data must be UTF-8, so binary data blobs travel as
+// base64-encoded binaryData
+func splitBinary(d data) (data, data) {
+	text := data{}
+	binary := data{}
+
+	for k, v := range d {
+		if utf8.ValidString(v) {
+			text[k] = v
+		} else {
+			binary[k] = base64.StdEncoding.EncodeToString([]byte(v))
+		}
+	}
+
+	return text, binary
+}
+
+// return (data, binaryData, mountPath, blobPath, error)
+func codeB64(application hlir.Application) (string, string, string, string, error) {
+	d, mountPath, blobPath, err := code(application)
 	if err != nil {
-		return "", "", "", err
+		return "", "", "", "", err
+	}
+
+	if err := checkConfigMapSize(application, d); err != nil {
+		return "", "", "", "", err
+	}
+
+	text, binary := splitBinary(d)
+
+	ds, err := util.ToJsonB64(text)
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	bs := ""
+	if len(binary) > 0 {
+		if bs, err = util.ToJsonB64(binary); err != nil {
+			return "", "", "", "", err
+		}
+	}
+
+	return ds, bs, mountPath, blobPath, nil
+}
+
+// Prefix the given command with a download, from the run's queue, of
+// the binary code of the given application, if it has any
+func withBinaryCode(application hlir.Application, mountPath, command string, verbose bool) string {
+	if !hlir.HasBinaryCode(application.Spec.Code) {
+		return command
+	}
+
+	if mountPath == "" {
+		mountPath = "."
 	}
 
-	ds, err := util.ToJsonB64(data)
-	return ds, mountPath, blobPath, err
+	return fmt.Sprintf(`$LUNCHPAIL_EXE queue download-code %s %s --verbose=%v || exit $?
+%s`, application.Metadata.Name, mountPath, verbose, command)
 }
@@ -0,0 +1,58 @@
+package shell
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+func TestOversizeCodeIsAnError(t *testing.T) {
+	var app hlir.Application
+	app.Metadata.Name = "big"
+	app.Spec.Code = []hlir.Code{
+		{Name: "main", Source: "#!/bin/sh"},
+		{Name: "data.txt", Source: strings.Repeat("x", maxConfigMapBytes)},
+	}
+
+	if _, _, _, _, err := codeB64(app); err == nil || !strings.Contains(err.Error(), "ConfigMap") {
+		t.Fatalf("expected an error for code too large for a ConfigMap, got %v", err)
+	}
+}
+
+func TestBinaryCodeTravelsViaTheQueue(t *testing.T) {
+	var app hlir.Application
+	app.Metadata.Name = "app"
+	app.Spec.Code = []hlir.Code{
+		{Name: "main", Source: "#!/bin/sh"},
+		{Name: "main-linux-amd64", Source: "\x7fELF" + strings.Repeat("\xff", maxConfigMapBytes)},
+	}
+
+	d, mountPath, _, err := code(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := d["main-linux-amd64"]; ok || d["main"] == "" {
+		t.Fatalf("expected only the text code in the ConfigMap, got %v", slices.Collect(maps.Keys(d)))
+	}
+
+	// Nor does it count against the size of the ConfigMap
+	if _, bs, _, _, err := codeB64(app); err != nil || bs != "" {
+		t.Fatalf("expected no binaryData, got %q (error %v)", bs, err)
+	}
+
+	if command := withBinaryCode(app, mountPath, "./main", false); !strings.HasPrefix(command, "$LUNCHPAIL_EXE queue download-code app . ") || !strings.HasSuffix(command, "\n./main") {
+		t.Errorf("expected the command to download the binary code first, got %q", command)
+	}
+}
+
+func TestTextCodeNeedsNoDownload(t *testing.T) {
+	var app hlir.Application
+	app.Spec.Code = []hlir.Code{{Name: "main.sh", Source: "#!/bin/sh"}}
+
+	if command := withBinaryCode(app, ".", "./main.sh", false); command != "./main.sh" {
+		t.Errorf("expected the command unchanged, got %q", command)
+	}
+}
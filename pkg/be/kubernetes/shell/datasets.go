@@ -1,14 +1,17 @@
 package shell
 
 import (
+	ctx "context"
 	"fmt"
 	"strings"
 
 	"lunchpail.io/pkg/be/kubernetes/names"
 	q "lunchpail.io/pkg/fe/linker/queue"
+	s "lunchpail.io/pkg/fe/linker/secrets"
 	"lunchpail.io/pkg/ir/hlir"
 	"lunchpail.io/pkg/ir/llir"
 	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/lunchpail"
 	"lunchpail.io/pkg/util"
 )
 
@@ -21,12 +24,21 @@ type pvc struct {
 	ClaimName string `json:"claimName"`
 }
 
+type hostPath struct {
+	Path string `json:"path"`
+	Type string `json:"type,omitempty"`
+}
+
 type volume struct {
-	Name                  string `json:"name"`
-	Nfs                   *nfs   `json:"nfs,omitempty"`
-	PersistentVolumeClaim *pvc   `json:"persistentVolumeClaim,omitempty"`
+	Name                  string    `json:"name"`
+	Nfs                   *nfs      `json:"nfs,omitempty"`
+	PersistentVolumeClaim *pvc      `json:"persistentVolumeClaim,omitempty"`
+	HostPath              *hostPath `json:"hostPath,omitempty"`
 }
 
+// The device that S3 mounts need, in lieu of a privileged container
+const fuseDevice = "/dev/fuse"
+
 type volumeMount struct {
 	Name      string `json:"name"`
 	MountPath string `json:"mountPath,omitempty"`
@@ -54,7 +66,7 @@ type envFrom struct {
 	Prefix string `json:"prefix,omitempty"`
 }
 
-func datasets(app hlir.Application, context llir.Context) ([]volume, []volumeMount, []envFrom, []initContainer, []map[string]string, error) {
+func datasets(app hlir.Application, component lunchpail.Component, context llir.Context, namespace string) ([]volume, []volumeMount, []envFrom, []initContainer, []map[string]string, error) {
 	queueEnv, err := envForQueue(context)
 	if err != nil {
 		return nil, nil, nil, nil, nil, err
@@ -63,9 +75,23 @@ func datasets(app hlir.Application, context llir.Context) ([]volume, []volumeMou
 	volumes := []volume{}
 	volumeMounts := []volumeMount{}
 	envFroms := []envFrom{queueEnv}
+
+	if component == lunchpail.MinioComponent && context.Queue.TLS != nil {
+		// Only the minio component needs the server certificate and key
+		queueTLSEnv, err := envForQueueTLS(context)
+		if err != nil {
+			return nil, nil, nil, nil, nil, err
+		}
+		envFroms = append(envFroms, queueTLSEnv)
+	}
 	secrets := []map[string]string{}
 	initContainers := []initContainer{}
 
+	if hlir.HasS3Mount(app.Spec.Datasets) {
+		volumes = append(volumes, volume{Name: "fuse", HostPath: &hostPath{fuseDevice, "CharDevice"}})
+		volumeMounts = append(volumeMounts, volumeMount{"fuse", fuseDevice})
+	}
+
 	for didx, dataset := range app.Spec.Datasets {
 		name := dataset.Name
 
@@ -83,13 +109,14 @@ func datasets(app hlir.Application, context llir.Context) ([]volume, []volumeMou
 			volumes = append(volumes, v)
 			volumeMounts = append(volumeMounts, volumeMount{name, dataset.MountPath})
 		}
-		if dataset.S3.Rclone.RemoteName != "" {
-			isValid, remoteSpec, err := q.SpecFromRcloneRemoteName(dataset.S3.Rclone.RemoteName, "", context.Run.RunName, context.Queue.Port)
+		if ns, secretName, ok := s.Ref(dataset.S3.Secret).KubernetesSecret(); ok && dataset.S3.EnvFrom.Prefix != "" && (ns == "" || ns == namespace) {
+			// Consume the Secret by reference
+			envFroms = append(envFroms, envFrom{secretRef{secretName}, dataset.S3.EnvFrom.Prefix})
+		} else if q.HasS3Credentials(dataset) {
+			remoteSpec, err := q.SpecFromDataset(ctx.Background(), dataset, context.Run.RunName, context.Queue.Port)
 
 			if err != nil {
-				return nil, nil, nil, nil, secrets, err
-			} else if !isValid {
-				return nil, nil, nil, nil, secrets, fmt.Errorf("Error: invalid or missing rclone config for given remote=%s for Application=%s", dataset.S3.Rclone.RemoteName, app.Metadata.Name)
+				return nil, nil, nil, nil, secrets, fmt.Errorf("Error: dataset %s of Application=%s: %v", name, app.Metadata.Name, err)
 			} else if dataset.S3.EnvFrom.Prefix != "" {
 				secretName := fmt.Sprintf("%s-%d", context.Run.RunName, didx)
 				secrets = append(secrets, map[string]string{
@@ -105,10 +132,10 @@ func datasets(app hlir.Application, context llir.Context) ([]volume, []volumeMou
 	return volumes, volumeMounts, envFroms, initContainers, secrets, nil
 }
 
-func datasetsB64(app hlir.Application, context llir.Context) (string, string, string, string, []string, error) {
+func datasetsB64(app hlir.Application, component lunchpail.Component, context llir.Context, namespace string) (string, string, string, string, []string, error) {
 	secretsB64 := []string{}
 
-	volumes, volumeMounts, envFroms, initContainers, secrets, err := datasets(app, context)
+	volumes, volumeMounts, envFroms, initContainers, secrets, err := datasets(app, component, context, namespace)
 	if err != nil {
 		return "", "", "", "", secretsB64, err
 	}
@@ -157,6 +184,19 @@ func envForQueue(context llir.Context) (envFrom, error) {
 	}, nil
 }
 
+// Inject the queue's TLS certificate and key
+func envForQueueTLS(context llir.Context) (envFrom, error) {
+	queueTLSResource, err := names.QueueTLS(context)
+	if err != nil {
+		return envFrom{}, err
+	}
+
+	return envFrom{
+		Prefix:    "lunchpail_queue_",
+		SecretRef: secretRef{queueTLSResource},
+	}, nil
+}
+
 func updateTestQueueEndpoint(s string, queue queue.Spec) string {
 	return strings.Replace(s, "$TEST_QUEUE_ENDPOINT", queue.Endpoint, -1)
 }
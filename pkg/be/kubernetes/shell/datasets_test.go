@@ -0,0 +1,61 @@
+package shell
+
+import (
+	"slices"
+	"testing"
+
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/llir"
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/lunchpail"
+)
+
+func TestS3MountGetsFuseDevice(t *testing.T) {
+	var app hlir.Application
+	var dataset hlir.Dataset
+	dataset.Name = "data"
+	dataset.S3.Mount.Path = "bucket/prefix"
+	app.Spec.Datasets = []hlir.Dataset{dataset}
+
+	volumes, volumeMounts, _, _, _, err := datasets(app, lunchpail.WorkersComponent, llir.Context{Run: queue.RunContext{RunName: "r"}}, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(volumes) != 1 || volumes[0].HostPath == nil || volumes[0].HostPath.Path != fuseDevice || volumes[0].HostPath.Type != "CharDevice" {
+		t.Fatalf("expected a %s hostPath volume, got %+v", fuseDevice, volumes)
+	}
+	if len(volumeMounts) != 1 || volumeMounts[0].MountPath != fuseDevice {
+		t.Fatalf("expected %s to be mounted, got %+v", fuseDevice, volumeMounts)
+	}
+}
+
+func TestNoFuseDeviceWithoutS3Mount(t *testing.T) {
+	volumes, _, _, _, _, err := datasets(hlir.Application{}, lunchpail.WorkersComponent, llir.Context{Run: queue.RunContext{RunName: "r"}}, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(volumes) != 0 {
+		t.Fatalf("expected no volumes, got %+v", volumes)
+	}
+}
+
+func TestS3MountGetsSysAdminNotPrivileged(t *testing.T) {
+	var app hlir.Application
+	app.Spec.ContainerSecurityContext.Capabilities.Add = []string{"NET_ADMIN"}
+	var dataset hlir.Dataset
+	dataset.S3.Mount.Path = "bucket/prefix"
+	app.Spec.Datasets = []hlir.Dataset{dataset}
+
+	csc := containerSecurityContextFor(app)
+	if !slices.Equal(csc.Capabilities.Add, []string{"NET_ADMIN", "SYS_ADMIN"}) {
+		t.Fatalf("expected SYS_ADMIN to be added to the given capabilities, got %v", csc.Capabilities.Add)
+	}
+	if !slices.Equal(app.Spec.ContainerSecurityContext.Capabilities.Add, []string{"NET_ADMIN"}) {
+		t.Fatalf("expected the application to be left as it was, got %v", app.Spec.ContainerSecurityContext.Capabilities.Add)
+	}
+
+	if csc := containerSecurityContextFor(hlir.Application{}); len(csc.Capabilities.Add) != 0 {
+		t.Fatalf("expected no added capabilities without an S3 mount, got %v", csc.Capabilities.Add)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"lunchpail.io/pkg/be/helm"
 	"lunchpail.io/pkg/be/kubernetes/common"
 	"lunchpail.io/pkg/be/kubernetes/names"
+	"lunchpail.io/pkg/ir/hlir"
 	"lunchpail.io/pkg/ir/llir"
 	"lunchpail.io/pkg/lunchpail"
 	"lunchpail.io/pkg/util"
@@ -36,17 +37,17 @@ func Template(ir llir.LLIR, c llir.ShellComponent, namespace string, opts common
 		return "", err
 	}
 
-	containerSecurityContext, err := util.ToYamlB64(c.Application.Spec.ContainerSecurityContext)
+	containerSecurityContext, err := util.ToYamlB64(containerSecurityContextFor(c.Application))
 	if err != nil {
 		return "", err
 	}
 
-	volumes, volumeMounts, envFroms, initContainers, secrets, err := datasetsB64(c.Application, ir.Context)
+	volumes, volumeMounts, envFroms, initContainers, secrets, err := datasetsB64(c.Application, c.C(), ir.Context, namespace)
 	if err != nil {
 		return "", err
 	}
 
-	workdirCmData, workdirCmMountPath, blobCmMountPath, err := codeB64(c.Application)
+	workdirCmData, workdirCmBinaryData, workdirCmMountPath, blobCmMountPath, err := codeB64(c.Application)
 	if err != nil {
 		return "", err
 	}
@@ -61,6 +62,15 @@ func Template(ir llir.LLIR, c llir.ShellComponent, namespace string, opts common
 		return "", err
 	}
 
+	// Admission and scheduling policies apply only to worker Jobs
+	isWorkerJob := c.RunAsJob && c.C() == lunchpail.WorkersComponent
+	ifWorkerJob := func(value string) string {
+		if isWorkerJob {
+			return value
+		}
+		return ""
+	}
+
 	// values for this component
 	myValues := []string{
 		fmt.Sprintf("lunchpail.step=%d", ir.Context.Run.Step),
@@ -68,12 +78,14 @@ func Template(ir llir.LLIR, c llir.ShellComponent, namespace string, opts common
 		"lunchpail.instanceName=" + util.TrimToMax(c.InstanceName, 63), // in kubernetes, labels must have a max length of 63 chars
 		"lunchpail.component=" + string(c.Component),
 		"image=" + c.Application.Spec.Image,
-		"command=" + updateTestQueueEndpoint(c.Application.Spec.Command, ir.Queue()),
+		"command=" + updateTestQueueEndpoint(withBinaryCode(c.Application, workdirCmMountPath, c.Application.Spec.Command, opts.Log.Verbose), ir.Queue()),
 		fmt.Sprintf("lunchpail.runAsJob=%v", c.RunAsJob),
+		fmt.Sprintf("lunchpail.indexed=%v", isWorkerJob && opts.IndexedJobs),
+		"lunchpail.kueue.queueName=" + ifWorkerJob(opts.KueueQueue),
+		"lunchpail.gang.schedulerName=" + ifWorkerJob(opts.SchedulerName),
 		"lunchpail.terminationGracePeriodSeconds=" + strconv.Itoa(terminationGracePeriodSeconds),
 		"workers.count=" + strconv.Itoa(c.InitialWorkers),
-		"workers.cpu=auto",
-		// "workers.gpu=" + strconv.Itoa(c.Sizing.Gpu),
+		"workers.gpu=" + strconv.Itoa(c.Scheduling.Gpu),
 		"securityContext=" + securityContext,
 		"containerSecurityContext=" + containerSecurityContext,
 		"taskqueue.bucket=" + ir.Queue().Bucket,
@@ -83,10 +95,47 @@ func Template(ir llir.LLIR, c llir.ShellComponent, namespace string, opts common
 		"initContainers=" + initContainers,
 		"envFroms=" + envFroms,
 		"workdir.cm.data=" + workdirCmData,
+		"workdir.cm.binaryData=" + workdirCmBinaryData,
 		"workdir.cm.mount_path=" + workdirCmMountPath,
 		"workdir.cm.blob_path=" + blobCmMountPath,
 	}
 
+	if c.Scheduling.Cpu != "" {
+		myValues = append(myValues, "workers.cpu="+c.Scheduling.Cpu)
+	} else {
+		myValues = append(myValues, "workers.cpu=auto")
+	}
+
+	if len(c.Application.Spec.Tags) > 0 {
+		if tags, err := util.ToYamlB64(hlir.TagLabels(c.Application.Spec.Tags)); err != nil {
+			return "", err
+		} else {
+			myValues = append(myValues, "lunchpail.tags="+tags)
+		}
+	}
+
+	if len(c.Scheduling.NodeSelector) > 0 {
+		if nodeSelector, err := util.ToYamlB64(c.Scheduling.NodeSelector); err != nil {
+			return "", err
+		} else {
+			myValues = append(myValues, "nodeSelector="+nodeSelector)
+		}
+	}
+	if len(c.Scheduling.Tolerations) > 0 {
+		if tolerations, err := util.ToYamlB64(c.Scheduling.Tolerations); err != nil {
+			return "", err
+		} else {
+			myValues = append(myValues, "tolerations="+tolerations)
+		}
+	}
+	if len(c.Scheduling.Affinity) > 0 {
+		if affinity, err := util.ToYamlB64(c.Scheduling.Affinity); err != nil {
+			return "", err
+		} else {
+			myValues = append(myValues, "affinity="+affinity)
+		}
+	}
+
 	if c.MinMemoryBytes > 0 {
 		myValues = append(myValues, "workers.memory="+strconv.FormatUint(c.MinMemoryBytes, 10))
 	} else {
@@ -136,3 +185,16 @@ func Template(ir llir.LLIR, c llir.ShellComponent, namespace string, opts common
 		helm.TemplateOptions{Verbose: opts.Log.Verbose, OverrideValues: values},
 	)
 }
+
+// The security context of the main container of the given application
+func containerSecurityContextFor(app hlir.Application) hlir.ContainerSecurityContext {
+	csc := app.Spec.ContainerSecurityContext
+	if hlir.HasS3Mount(app.Spec.Datasets) && !slices.Contains(csc.Capabilities.Add, "SYS_ADMIN") {
+		// Mounting an S3 prefix requires the capability to mount,
+		// along with /dev/fuse (see datasets.go), but not a
+		// privileged container. Note that hosts whose AppArmor
+		// profile forbids mounts will still refuse.
+		csc.Capabilities.Add = append(slices.Clone(csc.Capabilities.Add), "SYS_ADMIN")
+	}
+	return csc
+}
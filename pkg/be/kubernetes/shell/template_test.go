@@ -0,0 +1,271 @@
+package shell
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+
+	"lunchpail.io/pkg/be/kubernetes/common"
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/llir"
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/lunchpail"
+)
+
+// A worker pool Job with the given number of workers
+func workerJob(workers int) llir.ShellComponent {
+	c := llir.ShellComponent{
+		Component:      lunchpail.WorkersComponent,
+		RunAsJob:       true,
+		InitialWorkers: workers,
+		GroupName:      "pool",
+		InstanceName:   "pool-r",
+	}
+	c.Application.Metadata.Name = "app"
+	c.Application.Spec.Image = "docker.io/alpine:3"
+	c.Application.Spec.Command = "./main.sh"
+	return c
+}
+
+// Render the chart for the given component, returning its resources
+func render(t *testing.T, c llir.ShellComponent, opts build.Options) []*unstructured.Unstructured {
+	t.Helper()
+
+	if opts.Log == nil {
+		opts.Log = &build.LogOptions{}
+	}
+	ir := llir.LLIR{
+		AppName:    "app",
+		Context:    llir.Context{Run: queue.RunContext{RunName: "r", Bucket: "r"}},
+		Components: []llir.ShellComponent{c},
+	}
+
+	yaml, err := Template(ir, c, "default", common.Options{Options: llir.Options{Options: opts}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objs := []*unstructured.Unstructured{}
+	decoder := yamlutil.NewYAMLOrJSONDecoder(strings.NewReader(yaml), 4096)
+	for {
+		var content map[string]any
+		if err := decoder.Decode(&content); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		} else if len(content) > 0 {
+			objs = append(objs, &unstructured.Unstructured{Object: content})
+		}
+	}
+	return objs
+}
+
+// The one Job among the given resources
+func jobIn(t *testing.T, objs []*unstructured.Unstructured) batchv1.Job {
+	t.Helper()
+
+	jobs := []batchv1.Job{}
+	for _, obj := range objs {
+		if obj.GetKind() == "Job" {
+			var job batchv1.Job
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &job); err != nil {
+				t.Fatal(err)
+			}
+			jobs = append(jobs, job)
+		}
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected one Job, got %d", len(jobs))
+	}
+	return jobs[0]
+}
+
+func TestIndexedJob(t *testing.T) {
+	job := jobIn(t, render(t, workerJob(3), build.Options{IndexedJobs: true}))
+
+	if job.Spec.CompletionMode == nil || *job.Spec.CompletionMode != batchv1.IndexedCompletion {
+		t.Errorf("expected an Indexed Job, got %v", job.Spec.CompletionMode)
+	}
+	if job.Spec.Completions == nil || *job.Spec.Completions != 3 {
+		t.Errorf("expected one completion per worker, got %v", job.Spec.Completions)
+	}
+	if job.Spec.Parallelism == nil || *job.Spec.Parallelism != 3 {
+		t.Errorf("expected a parallelism of 3, got %v", job.Spec.Parallelism)
+	}
+
+	policy := job.Spec.PodFailurePolicy
+	if policy == nil || len(policy.Rules) != 1 {
+		t.Fatalf("expected one pod failure policy rule, got %+v", policy)
+	}
+	rule := policy.Rules[0]
+	if rule.Action != batchv1.PodFailurePolicyActionIgnore || len(rule.OnPodConditions) != 1 || rule.OnPodConditions[0].Type != corev1.DisruptionTarget {
+		t.Errorf("expected disruptions to be ignored, got %+v", rule)
+	}
+}
+
+func TestNonIndexedJob(t *testing.T) {
+	job := jobIn(t, render(t, workerJob(3), build.Options{}))
+
+	if job.Spec.CompletionMode != nil || job.Spec.Completions != nil || job.Spec.PodFailurePolicy != nil {
+		t.Errorf("expected a NonIndexed Job, got completionMode=%v completions=%v podFailurePolicy=%+v", job.Spec.CompletionMode, job.Spec.Completions, job.Spec.PodFailurePolicy)
+	}
+}
+
+func TestOnlyWorkerJobsAreIndexed(t *testing.T) {
+	c := workerJob(1)
+	c.Component = lunchpail.DispatcherComponent
+
+	job := jobIn(t, render(t, c, build.Options{IndexedJobs: true}))
+	if job.Spec.CompletionMode != nil {
+		t.Errorf("expected a dispatcher Job not to be Indexed, got %v", *job.Spec.CompletionMode)
+	}
+}
+
+// The resources of the given kind
+func ofKind(objs []*unstructured.Unstructured, kind string) []*unstructured.Unstructured {
+	matches := []*unstructured.Unstructured{}
+	for _, obj := range objs {
+		if obj.GetKind() == kind {
+			matches = append(matches, obj)
+		}
+	}
+	return matches
+}
+
+func TestGangScheduling(t *testing.T) {
+	tests := []struct {
+		scheduler  string
+		apiVersion string
+	}{
+		{"scheduler-plugins-scheduler", "scheduling.x-k8s.io/v1alpha1"},
+		{"volcano", "scheduling.volcano.sh/v1beta1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scheduler, func(t *testing.T) {
+			objs := render(t, workerJob(4), build.Options{SchedulerName: tt.scheduler})
+
+			groups := ofKind(objs, "PodGroup")
+			if len(groups) != 1 {
+				t.Fatalf("expected one PodGroup, got %d", len(groups))
+			}
+			group := groups[0]
+			if group.GetAPIVersion() != tt.apiVersion {
+				t.Errorf("expected a %s PodGroup, got %s", tt.apiVersion, group.GetAPIVersion())
+			}
+			if minMember, _, _ := unstructured.NestedFieldNoCopy(group.Object, "spec", "minMember"); fmt.Sprint(minMember) != "4" {
+				t.Errorf("expected minMember to be the number of workers, got %v", minMember)
+			}
+
+			job := jobIn(t, objs)
+			pod := job.Spec.Template
+			if pod.Spec.SchedulerName != tt.scheduler {
+				t.Errorf("expected scheduler %s, got %q", tt.scheduler, pod.Spec.SchedulerName)
+			}
+			if pod.Labels["scheduling.x-k8s.io/pod-group"] != group.GetName() || pod.Annotations["scheduling.k8s.io/group-name"] != group.GetName() {
+				t.Errorf("expected the pods to be members of PodGroup %s, got labels=%v annotations=%v", group.GetName(), pod.Labels, pod.Annotations)
+			}
+		})
+	}
+}
+
+func TestNoGangScheduling(t *testing.T) {
+	if groups := ofKind(render(t, workerJob(4), build.Options{}), "PodGroup"); len(groups) != 0 {
+		t.Errorf("expected no PodGroup without a scheduler, got %d", len(groups))
+	}
+
+	c := workerJob(1)
+	c.Component = lunchpail.DispatcherComponent
+	objs := render(t, c, build.Options{SchedulerName: "volcano"})
+	if groups := ofKind(objs, "PodGroup"); len(groups) != 0 {
+		t.Errorf("expected no PodGroup for a dispatcher, got %d", len(groups))
+	}
+	if job := jobIn(t, objs); job.Spec.Template.Spec.SchedulerName != "" {
+		t.Errorf("expected a dispatcher to use the default scheduler, got %s", job.Spec.Template.Spec.SchedulerName)
+	}
+}
+
+func TestKueueQueue(t *testing.T) {
+	job := jobIn(t, render(t, workerJob(2), build.Options{KueueQueue: "team-a"}))
+	if job.Labels["kueue.x-k8s.io/queue-name"] != "team-a" {
+		t.Errorf("expected the Job to be in LocalQueue team-a, got labels %v", job.Labels)
+	}
+}
+
+// The main container of the given Job
+func mainContainer(t *testing.T, job batchv1.Job) corev1.Container {
+	t.Helper()
+
+	for _, c := range job.Spec.Template.Spec.Containers {
+		if c.Name == "main" {
+			return c
+		}
+	}
+	t.Fatalf("expected a main container, got %+v", job.Spec.Template.Spec.Containers)
+	return corev1.Container{}
+}
+
+func TestResources(t *testing.T) {
+	tests := []struct {
+		name   string
+		cpu    string
+		memory uint64
+		gpu    int
+		want   corev1.ResourceList
+	}{
+		{"auto", "", 0, 0, corev1.ResourceList{}},
+		{"cpu", "500m", 0, 0, corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}},
+		{"memory", "", 2 << 30, 0, corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")}},
+		{"gpu", "", 0, 2, corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("2")}},
+		{"all", "4", 1 << 30, 1, corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+			"nvidia.com/gpu":      resource.MustParse("1"),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := workerJob(1)
+			c.Scheduling.Cpu = tt.cpu
+			c.MinMemoryBytes = tt.memory
+			c.Scheduling.Gpu = tt.gpu
+
+			resources := mainContainer(t, jobIn(t, render(t, c, build.Options{}))).Resources
+			for what, got := range map[string]corev1.ResourceList{"limits": resources.Limits, "requests": resources.Requests} {
+				if len(got) != len(tt.want) {
+					t.Errorf("expected %s %v, got %v", what, tt.want, got)
+					continue
+				}
+				for name, want := range tt.want {
+					if q, ok := got[name]; !ok || q.Cmp(want) != 0 {
+						t.Errorf("expected %s %s=%s, got %v", what, name, want.String(), got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPlacement(t *testing.T) {
+	c := workerJob(1)
+	c.Scheduling.NodeSelector = map[string]string{"accelerator": "a100"}
+	c.Scheduling.Tolerations = []hlir.Toleration{{Key: "gpu", Operator: "Exists", Effect: "NoSchedule"}}
+
+	pod := jobIn(t, render(t, c, build.Options{})).Spec.Template.Spec
+	if pod.NodeSelector["accelerator"] != "a100" {
+		t.Errorf("expected a node selector, got %v", pod.NodeSelector)
+	}
+	if len(pod.Tolerations) != 1 || pod.Tolerations[0].Key != "gpu" || pod.Tolerations[0].Effect != corev1.TaintEffectNoSchedule {
+		t.Errorf("expected a gpu toleration, got %+v", pod.Tolerations)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	q "lunchpail.io/pkg/fe/linker/queue"
 	"lunchpail.io/pkg/ir/llir"
 	"lunchpail.io/pkg/util"
 )
@@ -14,16 +15,31 @@ func (backend Backend) Up(ctx context.Context, ir llir.LLIR, opts llir.Options,
 			Run:   ir.Context.Run,
 			Queue: ir.Queue().UpdateEndpoint(fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", util.Dns1035(ir.RunName()+"-minio"), backend.namespace, ir.Queue().Port)),
 		}
+
+		if opts.QueueTLS && ir.Queue().TLS == nil {
+			service := util.Dns1035(ir.RunName() + "-minio")
+			spec, err := q.WithTLS(ir.Queue(), service+"."+backend.namespace+".svc.cluster.local", service+"."+backend.namespace+".svc", service, "localhost", "127.0.0.1")
+			if err != nil {
+				return err
+			}
+			ir.Context.Queue = spec
+		}
 	}
 
-	if err := applyOperation(ctx, ir, backend.namespace, "", ApplyIt, opts); err != nil {
+	a, objs, err := applierFor(ctx, ir, backend.namespace, opts)
+	if err != nil {
+		return err
+	} else if err := a.apply(ctx, objs); err != nil {
 		return err
 	}
 
-	// Indicate that we are off to the races
+	// Indicate that we are off to the races. Worker Jobs subject to
+	// queued admission (e.g. by Kueue) or gang scheduling may not
+	// have started yet, but their tasks can be enqueued meanwhile
 	if isRunning != nil {
 		isRunning <- ir.Context
 	}
 
-	return nil
+	// Then report on their admission
+	return a.waitForAdmissions(ctx, objs, opts.AdmissionTimeout)
 }
@@ -2,15 +2,27 @@ package kubernetes
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"slices"
 	"time"
 
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/kubernetes/pkg/client/conditions"
 )
 
+// Kueue identifies the LocalQueue of a Job by this label
+const kueueQueueLabel = "kueue.x-k8s.io/queue-name"
+
+// Container waiting reasons from which a pod will not recover on its own
+var fatalWaitingReasons = []string{"ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull", "CreateContainerConfigError"}
+
 // return a condition function that indicates whether the given pod is
 // currently running
 func isPodRunning(ctx context.Context, c kubernetes.Interface, podName, namespace string) wait.ConditionFunc {
@@ -35,3 +47,213 @@ func isPodRunning(ctx context.Context, c kubernetes.Interface, podName, namespac
 func waitForPodRunning(ctx context.Context, c kubernetes.Interface, namespace, podName string, timeout time.Duration) error {
 	return wait.PollImmediate(time.Second, timeout, isPodRunning(ctx, c, podName, namespace))
 }
+
+// Is the given pod running, or already done? Returns an error if the
+// pod has failed, or is stuck in a way it will not recover from.
+func isPodReady(pod *v1.Pod) (bool, error) {
+	switch pod.Status.Phase {
+	case v1.PodRunning, v1.PodSucceeded:
+		return true, nil
+	case v1.PodFailed:
+		return false, fmt.Errorf("Pod %s failed: %s", pod.Name, pod.Status.Message)
+	}
+
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		if waiting := status.State.Waiting; waiting != nil && slices.Contains(fatalWaitingReasons, waiting.Reason) {
+			return false, fmt.Errorf("Pod %s is stuck with %s: %s", pod.Name, waiting.Reason, waiting.Message)
+		}
+	}
+
+	return false, nil
+}
+
+// return a condition function that indicates whether the given
+// resource is ready; resources other than workloads are always
+// ready. Resources that have already been torn down, e.g. because the
+// run has already completed, are also considered to be ready.
+func (a applier) isReady(obj *unstructured.Unstructured) wait.ConditionWithContextFunc {
+	return func(ctx context.Context) (bool, error) {
+		ready, err := a.checkReady(ctx, obj)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return ready, err
+	}
+}
+
+func (a applier) checkReady(ctx context.Context, obj *unstructured.Unstructured) (bool, error) {
+	namespace := obj.GetNamespace()
+	name := obj.GetName()
+
+	switch obj.GetKind() {
+	case "Pod":
+		pod, err := a.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return isPodReady(pod)
+
+	case "Job":
+		job, err := a.clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, c := range job.Status.Conditions {
+			if c.Status != v1.ConditionTrue {
+				continue
+			}
+			switch c.Type {
+			case batchv1.JobComplete:
+				return true, nil
+			case batchv1.JobFailed:
+				return false, fmt.Errorf("Job %s failed: %s", name, c.Message)
+			}
+		}
+
+		// A Job is ready once any of its pods is. The Job may
+		// retry failed pods, so only its Failed condition (above)
+		// tells us that the Job has failed.
+		pods, err := a.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + name})
+		if err != nil {
+			return false, err
+		}
+		ready := false
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == v1.PodFailed {
+				continue
+			} else if ok, err := isPodReady(&pod); err != nil {
+				return false, err
+			} else if ok {
+				ready = true
+			}
+		}
+		return ready, nil
+
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		ri, err := a.resourceFor(obj)
+		if err != nil {
+			return false, err
+		}
+		live, err := ri.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		replicas, found, _ := unstructured.NestedInt64(live.Object, "spec", "replicas")
+		if !found {
+			replicas = 1
+		}
+		ready, _, _ := unstructured.NestedInt64(live.Object, "status", "readyReplicas")
+		return ready >= replicas, nil
+	}
+
+	return true, nil
+}
+
+// If the given resource is a Job subject to queued admission, what
+// it is pending on, and how to tell whether it has been admitted: by
+// Kueue, which it signals by unsuspending the Job; or by a gang
+// scheduler, which it signals by scheduling all of the Job's workers
+// at once
+func (a applier) admissionOf(obj *unstructured.Unstructured) (string, func(context.Context, *batchv1.Job) (bool, error)) {
+	if obj.GetKind() != "Job" {
+		return "", nil
+	}
+
+	if queue, ok := obj.GetLabels()[kueueQueueLabel]; ok {
+		return "admission to Kueue LocalQueue " + queue, func(_ context.Context, job *batchv1.Job) (bool, error) {
+			return job.Spec.Suspend == nil || !*job.Spec.Suspend, nil
+		}
+	} else if scheduler, ok, _ := unstructured.NestedString(obj.Object, "spec", "template", "spec", "schedulerName"); ok && scheduler != "" {
+		return "gang scheduling by " + scheduler, a.isGangScheduled
+	}
+
+	return "", nil
+}
+
+// Watch for the admission of those of the given resources that are
+// subject to queued admission, reporting which are pending and when
+// they are admitted. Admission may never come, e.g. if quota is never
+// available, so we give them all one deadline, after which we warn
+// that they are still pending, and stop watching.
+func (a applier) waitForAdmissions(ctx context.Context, objs []*unstructured.Unstructured, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultAdmissionTimeout
+	}
+
+	wctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for _, obj := range objs {
+		err := a.waitForAdmission(wctx, obj)
+		if err != nil && ctx.Err() != nil {
+			// We are being torn down
+			return nil
+		} else if err != nil && wctx.Err() != nil {
+			fmt.Fprintf(os.Stderr, "Warning: worker pool %s is still pending after %s; its tasks will wait in the queue until it is admitted\n", obj.GetName(), timeout)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Wait for the given Job to be admitted, if it is subject to queued
+// admission
+func (a applier) waitForAdmission(ctx context.Context, obj *unstructured.Unstructured) error {
+	pendingOn, isAdmitted := a.admissionOf(obj)
+	if pendingOn == "" {
+		return nil
+	}
+
+	pending := false
+	return wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		job, err := a.clientset.BatchV1().Jobs(obj.GetNamespace()).Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+
+		if admitted, err := isAdmitted(ctx, job); err != nil {
+			return false, err
+		} else if !admitted {
+			if !pending {
+				fmt.Fprintf(os.Stderr, "Worker pool %s is pending %s\n", obj.GetName(), pendingOn)
+				pending = true
+			}
+			return false, nil
+		}
+
+		if pending {
+			fmt.Fprintf(os.Stderr, "Worker pool %s has been admitted\n", obj.GetName())
+		}
+		return true, nil
+	})
+}
+
+// Have all of the workers of the given Job been scheduled? A gang
+// scheduler schedules all of them, or none of them
+func (a applier) isGangScheduled(ctx context.Context, job *batchv1.Job) (bool, error) {
+	want := int32(1)
+	if job.Spec.Parallelism != nil {
+		want = *job.Spec.Parallelism
+	}
+
+	pods, err := a.clientset.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + job.Name})
+	if err != nil {
+		return false, err
+	}
+
+	scheduled := int32(0)
+	for _, pod := range pods.Items {
+		for _, c := range pod.Status.Conditions {
+			if c.Type == v1.PodScheduled && c.Status == v1.ConditionTrue {
+				scheduled++
+				break
+			}
+		}
+	}
+
+	return scheduled >= want, nil
+}
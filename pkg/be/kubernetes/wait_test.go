@@ -0,0 +1,236 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testNamespace = "test"
+
+func job(name string, conditions ...batchv1.JobCondition) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Status:     batchv1.JobStatus{Conditions: conditions},
+	}
+}
+
+func jobPod(jobName, podName string, phase v1.PodPhase) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: testNamespace, Labels: map[string]string{"job-name": jobName}},
+		Status:     v1.PodStatus{Phase: phase},
+	}
+}
+
+func ref(kind, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace(testNamespace)
+	return obj
+}
+
+func testApplier(timeout time.Duration, objs ...runtime.Object) applier {
+	return newApplier(nil, fake.NewSimpleClientset(objs...), testNamespace, "run", timeout, false)
+}
+
+func TestJobReadiness(t *testing.T) {
+	failed := batchv1.JobCondition{Type: batchv1.JobFailed, Status: v1.ConditionTrue, Message: "BackoffLimitExceeded"}
+	complete := batchv1.JobCondition{Type: batchv1.JobComplete, Status: v1.ConditionTrue}
+
+	tests := []struct {
+		name    string
+		objs    []runtime.Object
+		ready   bool
+		wantErr bool
+	}{
+		{"no pods yet", []runtime.Object{job("j")}, false, false},
+		{"running pod", []runtime.Object{job("j"), jobPod("j", "p0", v1.PodRunning)}, true, false},
+		{"failed pod, being retried", []runtime.Object{job("j"), jobPod("j", "p0", v1.PodFailed)}, false, false},
+		{"failed pod and running retry", []runtime.Object{job("j"), jobPod("j", "p0", v1.PodFailed), jobPod("j", "p1", v1.PodRunning)}, true, false},
+		{"job failed", []runtime.Object{job("j", failed), jobPod("j", "p0", v1.PodFailed)}, false, true},
+		{"job complete", []runtime.Object{job("j", complete)}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := testApplier(time.Minute, tt.objs...)
+			ready, err := a.checkReady(context.Background(), ref("Job", "j"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if ready != tt.ready {
+				t.Fatalf("expected ready=%v, got %v", tt.ready, ready)
+			}
+		})
+	}
+}
+
+func TestPodReadiness(t *testing.T) {
+	stuck := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "stuck", Namespace: testNamespace},
+		Status: v1.PodStatus{
+			Phase: v1.PodPending,
+			ContainerStatuses: []v1.ContainerStatus{
+				{State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			},
+		},
+	}
+	failed := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "failed", Namespace: testNamespace}, Status: v1.PodStatus{Phase: v1.PodFailed}}
+	running := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: testNamespace}, Status: v1.PodStatus{Phase: v1.PodRunning}}
+
+	a := testApplier(time.Minute, stuck, failed, running)
+	if _, err := a.checkReady(context.Background(), ref("Pod", "stuck")); err == nil {
+		t.Fatal("expected an error for a pod stuck in ImagePullBackOff")
+	}
+	if _, err := a.checkReady(context.Background(), ref("Pod", "failed")); err == nil {
+		t.Fatal("expected an error for a failed pod")
+	}
+	if ready, err := a.checkReady(context.Background(), ref("Pod", "running")); err != nil || !ready {
+		t.Fatalf("expected running pod to be ready, got ready=%v err=%v", ready, err)
+	}
+
+	// Resources that have already been torn down count as ready
+	if ready, err := a.isReady(ref("Pod", "gone"))(context.Background()); err != nil || !ready {
+		t.Fatalf("expected deleted pod to be ready, got ready=%v err=%v", ready, err)
+	}
+}
+
+func TestWaitForReadyHasOneDeadline(t *testing.T) {
+	timeout := 2 * time.Second
+	a := testApplier(timeout, job("j1"), job("j2"), job("j3"))
+
+	start := time.Now()
+	if err := a.waitForReady(context.Background(), []*unstructured.Unstructured{ref("Job", "j1"), ref("Job", "j2"), ref("Job", "j3")}); err != nil {
+		t.Fatalf("expected a warning rather than an error, got %v", err)
+	}
+
+	// Each Job on its own would take the full timeout
+	if elapsed := time.Since(start); elapsed > timeout+time.Second {
+		t.Fatalf("expected to give up after %s, but waited %s", timeout, elapsed)
+	}
+}
+
+func TestWaitForReadyFailsOnJobFailure(t *testing.T) {
+	failed := batchv1.JobCondition{Type: batchv1.JobFailed, Status: v1.ConditionTrue, Message: "BackoffLimitExceeded"}
+	a := testApplier(time.Minute, job("j", failed))
+
+	if err := a.waitForReady(context.Background(), []*unstructured.Unstructured{ref("Job", "j")}); err == nil {
+		t.Fatal("expected an error for a failed Job")
+	}
+}
+
+func TestDefaultReadinessTimeout(t *testing.T) {
+	if a := testApplier(0); a.timeout != defaultReadinessTimeout {
+		t.Fatalf("expected default timeout %s, got %s", defaultReadinessTimeout, a.timeout)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func scheduledPod(jobName, podName string) *v1.Pod {
+	pod := jobPod(jobName, podName, v1.PodPending)
+	pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodScheduled, Status: v1.ConditionTrue}}
+	return pod
+}
+
+func TestWaitForAdmission(t *testing.T) {
+	suspended := job("j")
+	suspended.Spec.Suspend = ptr(true)
+	unsuspended := job("j")
+	unsuspended.Spec.Suspend = ptr(false)
+
+	gang := job("j")
+	gang.Spec.Parallelism = ptr(int32(2))
+
+	kueueRef := ref("Job", "j")
+	kueueRef.SetLabels(map[string]string{kueueQueueLabel: "q"})
+
+	gangRef := ref("Job", "j")
+	if err := unstructured.SetNestedField(gangRef.Object, "scheduler-plugins-scheduler", "spec", "template", "spec", "schedulerName"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		ref      *unstructured.Unstructured
+		objs     []runtime.Object
+		admitted bool
+	}{
+		{"neither kueue nor gang", ref("Job", "j"), []runtime.Object{suspended}, true},
+		{"not a job", ref("Pod", "j"), nil, true},
+		{"kueue pending", kueueRef, []runtime.Object{suspended}, false},
+		{"kueue admitted", kueueRef, []runtime.Object{unsuspended}, true},
+		{"kueue job gone", kueueRef, nil, true},
+		{"gang pending without pods", gangRef, []runtime.Object{gang}, false},
+		{"gang pending with some pods scheduled", gangRef, []runtime.Object{gang, scheduledPod("j", "p0"), jobPod("j", "p1", v1.PodPending)}, false},
+		{"gang scheduled", gangRef, []runtime.Object{gang, scheduledPod("j", "p0"), scheduledPod("j", "p1")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := testApplier(time.Minute, tt.objs...)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+			defer cancel()
+
+			err := a.waitForAdmission(ctx, tt.ref)
+			if tt.admitted && err != nil {
+				t.Fatalf("expected admission, got %v", err)
+			} else if !tt.admitted && err == nil {
+				t.Fatal("expected to be left pending")
+			}
+		})
+	}
+}
+
+func TestWaitForReadySkipsJobsPendingAdmission(t *testing.T) {
+	suspended := job("j")
+	suspended.Spec.Suspend = ptr(true)
+	kueueRef := ref("Job", "j")
+	kueueRef.SetLabels(map[string]string{kueueQueueLabel: "q"})
+
+	a := testApplier(time.Minute, suspended)
+
+	start := time.Now()
+	if err := a.waitForReady(context.Background(), []*unstructured.Unstructured{kueueRef}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected not to wait for a Job pending admission, but waited %s", elapsed)
+	}
+}
+
+func TestWaitForAdmissionsHasOneDeadline(t *testing.T) {
+	timeout := 2 * time.Second
+	objs := []runtime.Object{}
+	refs := []*unstructured.Unstructured{}
+	for _, name := range []string{"j1", "j2", "j3"} {
+		suspended := job(name)
+		suspended.Spec.Suspend = ptr(true)
+		objs = append(objs, suspended)
+
+		kueueRef := ref("Job", name)
+		kueueRef.SetLabels(map[string]string{kueueQueueLabel: "q"})
+		refs = append(refs, kueueRef)
+	}
+
+	a := testApplier(time.Minute, objs...)
+
+	start := time.Now()
+	if err := a.waitForAdmissions(context.Background(), refs, timeout); err != nil {
+		t.Fatalf("expected a warning rather than an error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > timeout+time.Second {
+		t.Fatalf("expected to give up after %s, but waited %s", timeout, elapsed)
+	}
+}
@@ -8,7 +8,7 @@ import (
 
 // Bring down the linked application
 func (backend Backend) Down(ctx context.Context, ir llir.LLIR, opts llir.Options) error {
-	if err := backend.IsCompatible(ir); err != nil {
+	if err := backend.IsCompatible(ir, opts); err != nil {
 		return err
 	}
 
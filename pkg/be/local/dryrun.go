@@ -6,7 +6,7 @@ import (
 
 // Return a string to convey relevant dry-run info
 func (backend Backend) DryRun(ir llir.LLIR, opts llir.Options) (string, error) {
-	if err := backend.IsCompatible(ir); err != nil {
+	if err := backend.IsCompatible(ir, opts); err != nil {
 		return "", err
 	}
 
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"lunchpail.io/pkg/build"
 	"lunchpail.io/pkg/ir/queue"
@@ -171,3 +172,74 @@ func QueueFile(run queue.RunContext) (string, error) {
 
 	return filepath.Join(dir, "queue.json"), nil
 }
+
+// Components read the queue credentials from this file, so that
+// they need not appear in their environment
+func QueueCredentialsFile(run queue.RunContext) (string, error) {
+	dir, err := runDir(run)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "credentials.json"), nil
+}
+
+// The minio component reads its TLS certificate and key from this
+// directory
+func QueueCertsDir(run queue.RunContext) (string, error) {
+	dir, err := runDir(run)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "certs"), nil
+}
+
+// Where we record the tags of a run
+func TagsFile(runname string) (string, error) {
+	dir, err := RunsDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, runname, "tags.txt"), nil
+}
+
+// Where `up` archives the logs that components shipped to the queue,
+// so that they can be searched after the run is torn down. Note: not
+// under RunsDir(), as runs of any backend may be archived here.
+func LogArchiveDir(runname string) (string, error) {
+	dir, err := thisAppDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "logs", runname), nil
+}
+
+// The run whose logs were most recently archived
+func LatestLogArchive() (string, error) {
+	dir, err := thisAppDir()
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "logs"))
+	if err != nil {
+		return "", err
+	}
+
+	latest := ""
+	var latestTime time.Time
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && info.IsDir() && info.ModTime().After(latestTime) {
+			latest = e.Name()
+			latestTime = info.ModTime()
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("No archived logs found")
+	}
+	return latest, nil
+}
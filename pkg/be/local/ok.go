@@ -3,6 +3,7 @@ package local
 import (
 	"context"
 	"fmt"
+	"runtime"
 
 	"lunchpail.io/pkg/be/local/shell"
 	"lunchpail.io/pkg/build"
@@ -15,13 +16,17 @@ func (backend Backend) Ok(ctx context.Context, initOk bool, opts build.Options)
 }
 
 // Is the given IR compatible with this backend?
-func (backend Backend) IsCompatible(ir llir.LLIR) error {
+func (backend Backend) IsCompatible(ir llir.LLIR, opts llir.Options) error {
 	if ir.AppProvidedKubernetesResources != "" {
 		return fmt.Errorf("Unable to target the local backend due to application-provided Kubernetes resources")
 	}
 
+	if opts.LocalContainers && runtime.GOOS != "linux" {
+		return fmt.Errorf("Running components in containers on the local backend is supported only on Linux, not %s", runtime.GOOS)
+	}
+
 	for _, c := range ir.Components {
-		if err := shell.IsCompatible(c); err != nil {
+		if err := shell.IsCompatible(c, opts.LocalContainers); err != nil {
 			return err
 		}
 	}
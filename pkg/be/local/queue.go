@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"path/filepath"
 	"time"
 
 	"lunchpail.io/pkg/be/local/files"
@@ -14,24 +15,41 @@ import (
 )
 
 // Queue properties for a given run, plus ensure access to the endpoint from this client
-func (backend Backend) AccessQueue(ctx context.Context, run queue.RunContext, queue queue.Spec, opts build.LogOptions) (endpoint, accessKeyID, secretAccessKey, bucket string, stop func(), err error) {
-	endpoint, accessKeyID, secretAccessKey, bucket, err = backend.queue(ctx, run)
+func (backend Backend) AccessQueue(ctx context.Context, run queue.RunContext, queue queue.Spec, opts build.LogOptions) (endpoint, accessKeyID, secretAccessKey, bucket, ca string, stop func(), err error) {
+	endpoint, accessKeyID, secretAccessKey, bucket, ca, err = backend.queue(ctx, run)
 	stop = func() {}
 	return
 }
 
-func (backend Backend) queue(ctx context.Context, run queue.RunContext) (endpoint, accessKeyID, secretAccessKey, bucket string, err error) {
+func (backend Backend) queue(ctx context.Context, run queue.RunContext) (endpoint, accessKeyID, secretAccessKey, bucket, ca string, err error) {
 	spec, rerr := restoreContext(run)
 	if rerr != nil {
 		err = rerr
 		return
 	}
 
+	f, ferr := files.QueueCredentialsFile(run)
+	if ferr != nil {
+		err = ferr
+		return
+	}
+
+	// Runs started before we kept the credentials separately have
+	// them in the queue spec
+	creds, cerr := queue.ReadCredentials(f)
+	if errors.Is(cerr, os.ErrNotExist) {
+		creds = spec.Queue.Credentials()
+	} else if cerr != nil {
+		err = cerr
+		return
+	}
+
 	// TODO this is hard-wired to local minio
 	endpoint = spec.Queue.Endpoint
-	accessKeyID = spec.Queue.AccessKey
-	secretAccessKey = spec.Queue.SecretKey
+	accessKeyID = creds.AccessKeyID
+	secretAccessKey = creds.SecretAccessKey
 	bucket = spec.Queue.Bucket
+	ca = spec.Queue.CA()
 	return
 }
 
@@ -41,7 +59,23 @@ func saveContext(ir llir.LLIR) error {
 		return err
 	}
 
-	b, err := json.Marshal(ir.Context)
+	// The credentials are kept separately, readable only by us
+	if cf, err := files.QueueCredentialsFile(ir.Context.Run); err != nil {
+		return err
+	} else if err := queue.WriteCredentials(cf, ir.Queue().Credentials()); err != nil {
+		return err
+	}
+
+	// As is the server certificate and key, if the queue uses TLS
+	if tls := ir.Queue().TLS; tls != nil {
+		if err := saveCerts(ir.Context.Run, *tls); err != nil {
+			return err
+		}
+	}
+
+	ctx := ir.Context
+	ctx.Queue = ctx.Queue.WithoutCredentials()
+	b, err := json.Marshal(ctx)
 	if err != nil {
 		return err
 	}
@@ -49,6 +83,24 @@ func saveContext(ir llir.LLIR) error {
 	return os.WriteFile(f, b, 0644)
 }
 
+// Write the TLS certificate and key in the layout expected by `minio --certs-dir`
+func saveCerts(run queue.RunContext, tls queue.TLS) error {
+	dir, err := files.QueueCertsDir(run)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "public.crt"), []byte(tls.Cert), 0644); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, "private.key"), []byte(tls.Key), 0600)
+}
+
 func restoreContext(run queue.RunContext) (llir.Context, error) {
 	var spec llir.Context
 
@@ -0,0 +1,87 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lunchpail.io/pkg/be/local/files"
+	"lunchpail.io/pkg/ir/llir"
+	"lunchpail.io/pkg/ir/queue"
+)
+
+var testRun = queue.RunContext{RunName: "r"}
+
+var testQueue = queue.Spec{Bucket: "test", Endpoint: "localhost:9000", AccessKey: "ak", SecretKey: "sk"}
+
+// Create the directory of the test run, as `up` does, returning the path to its queue file
+func mkRunDir(t *testing.T) string {
+	t.Helper()
+
+	f, err := files.QueueFile(testRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestQueueCredentialsFromFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	mkRunDir(t)
+
+	if err := saveContext(llir.LLIR{Context: llir.Context{Run: testRun, Queue: testQueue}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The credentials are kept out of the queue file...
+	f, err := files.QueueFile(testRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved llir.Context
+	if b, err := os.ReadFile(f); err != nil {
+		t.Fatal(err)
+	} else if err := json.Unmarshal(b, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Queue.AccessKey != "" || saved.Queue.SecretKey != "" {
+		t.Fatalf("expected no credentials in %s, got %+v", f, saved.Queue)
+	}
+
+	// ...but are found nonetheless
+	_, ak, sk, bucket, _, err := Backend{}.queue(context.Background(), testRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ak != "ak" || sk != "sk" || bucket != "test" {
+		t.Fatalf("unexpected queue access ak=%s sk=%s bucket=%s", ak, sk, bucket)
+	}
+}
+
+func TestQueueCredentialsOfOlderRuns(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// Runs started before we kept a separate credentials file
+	// have the credentials in the queue file
+	f := mkRunDir(t)
+	b, err := json.Marshal(llir.Context{Run: testRun, Queue: testQueue})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(f, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, ak, sk, _, _, err := Backend{}.queue(context.Background(), testRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ak != "ak" || sk != "sk" {
+		t.Fatalf("unexpected queue credentials ak=%s sk=%s", ak, sk)
+	}
+}
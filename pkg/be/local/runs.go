@@ -6,11 +6,13 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/shirou/gopsutil/v4/process"
 
 	"lunchpail.io/pkg/be/local/files"
 	"lunchpail.io/pkg/be/runs"
+	"lunchpail.io/pkg/ir/llir"
 	"lunchpail.io/pkg/ir/queue"
 	"lunchpail.io/pkg/lunchpail"
 )
@@ -52,13 +54,44 @@ func (backend Backend) ListRuns(ctx context.Context, all bool) ([]runs.Run, erro
 		}
 
 		if running {
-			L = append(L, runs.Run{Name: e.Name(), CreationTimestamp: info.ModTime()})
+			tags, err := restoreTags(runname)
+			if err != nil {
+				return nil, err
+			}
+			L = append(L, runs.Run{Name: e.Name(), CreationTimestamp: info.ModTime(), Tags: tags})
 		}
 	}
 
 	return L, nil
 }
 
+func saveTags(ir llir.LLIR) error {
+	f, err := files.TagsFile(ir.RunName())
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(f, []byte(strings.Join(ir.Tags(), "\n")), 0644)
+}
+
+func restoreTags(runname string) ([]string, error) {
+	f, err := files.TagsFile(runname)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(f)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Runs from before we recorded tags
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	return strings.Fields(string(b)), nil
+}
+
 func isRunning(runname string) (bool, error) {
 	pidfile, err := files.PidfileForMain(queue.RunContext{RunName: runname})
 	if err != nil {
@@ -0,0 +1,137 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"lunchpail.io/pkg/be/local/files"
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/llir"
+	"lunchpail.io/pkg/ir/queue"
+	images "lunchpail.io/pkg/lunchpail/images/build"
+)
+
+// Where we mount this lunchpail executable inside of component containers
+const containerExe = "/usr/local/bin/lunchpail"
+
+// Host environment variables that we do not pass through to the
+// container, which has its own
+var hostOnlyEnv = []string{"PATH", "HOME"}
+
+// Characters not allowed in container names
+var notContainerName = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// The name of the container of the given component. Worker instance
+// names (w0, w1, ...) are unique only within their pool, so this
+// includes the run and pool, lest we e.g. remove the container of
+// another run.
+func containerName(c llir.ShellComponent, run queue.RunContext) string {
+	parts := []string{"lunchpail", run.RunName, strconv.Itoa(run.Step)}
+	if c.GroupName != "" {
+		parts = append(parts, c.GroupName)
+	}
+	parts = append(parts, string(c.C()), c.InstanceName)
+
+	return notContainerName.ReplaceAllString(strings.Join(parts, "-"), "-")
+}
+
+// A command that runs the given component in a container using its
+// application's image, mounting its workdir and passing through the
+// given env
+func containerize(ctx context.Context, cli images.ContainerCli, c llir.ShellComponent, ir llir.LLIR, workdir, command string, env []string) (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	// The queue credentials and certificates live here
+	credentials, err := files.QueueCredentialsFile(ir.Context.Run)
+	if err != nil {
+		return nil, err
+	}
+	rundir := filepath.Dir(credentials)
+
+	args := []string{
+		"run", "--rm",
+		"--name", containerName(c, ir.Context.Run),
+		"--network", "host", // so that the queue endpoint is reachable via localhost
+		"--workdir", workdir,
+		"--volume", workdir + ":" + workdir,
+		"--volume", rundir + ":" + rundir + ":ro",
+		"--volume", exe + ":" + containerExe + ":ro",
+		"--entrypoint", "/bin/sh",
+
+		// The image may not know about our uid, so give the
+		// component a writable home, e.g. for `lunchpail needs`
+		"--env", "HOME=" + workdir,
+	}
+
+	// Run as ourselves, so that the component can write to its workdir
+	switch cli {
+	case images.Podman:
+		args = append(args, "--userns=keep-id")
+	default:
+		args = append(args, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()))
+	}
+
+	if venvs := os.Getenv("LUNCHPAIL_VENV_CACHEDIR"); venvs != "" {
+		args = append(args, "--volume", venvs+":"+venvs)
+	}
+
+	if cores, err := c.Scheduling.CpuCores(); err != nil {
+		return nil, err
+	} else if cores > 0 {
+		args = append(args, "--cpus", fmt.Sprintf("%g", cores))
+	}
+
+	if c.Scheduling.Gpu > 0 {
+		switch cli {
+		case images.Podman:
+			args = append(args, "--device", "nvidia.com/gpu=all")
+		default:
+			args = append(args, "--gpus", fmt.Sprintf("%d", c.Scheduling.Gpu))
+		}
+	}
+
+	// Pass env by name only, so that the values (e.g. multi-line
+	// certificates) are taken from the cli's own environment
+	cliEnv := os.Environ()
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		if slices.Contains(hostOnlyEnv, name) {
+			continue
+		}
+		if name == "LUNCHPAIL_EXE" {
+			value = containerExe
+		}
+		args = append(args, "--env", name)
+		cliEnv = append(cliEnv, name+"="+value)
+	}
+
+	args = append(args, c.Application.Spec.Image, "-c", command)
+
+	cmd := exec.CommandContext(ctx, string(cli), args...)
+	cmd.Env = cliEnv
+	return cmd, nil
+}
+
+// The cli may exit without taking the container down with it, e.g. if
+// we are cancelled, or if the cli itself fails or is killed, so clean
+// up after ourselves unless the component ran to completion
+func removeContainerOnFailure(ctx context.Context, cli images.ContainerCli, c llir.ShellComponent, run queue.RunContext, err error, opts build.LogOptions) {
+	if err == nil && ctx.Err() == nil {
+		return
+	}
+
+	name := containerName(c, run)
+	if err := exec.Command(string(cli), "rm", "--force", name).Run(); err != nil && opts.Debug {
+		fmt.Fprintf(os.Stderr, "Unable to remove container %s: %v\n", name, err)
+	}
+}
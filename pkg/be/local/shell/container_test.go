@@ -0,0 +1,228 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"lunchpail.io/pkg/be/local/files"
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/llir"
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/lunchpail"
+	images "lunchpail.io/pkg/lunchpail/images/build"
+)
+
+func TestContainerNamesAreUniqueAcrossRunsAndPools(t *testing.T) {
+	worker := func(pool string) llir.ShellComponent {
+		return llir.ShellComponent{Component: lunchpail.WorkersComponent, GroupName: pool, InstanceName: "w0"}
+	}
+
+	names := map[string]bool{}
+	for _, run := range []string{"run1", "run2"} {
+		for _, pool := range []string{"pool1", "pool2"} {
+			name := containerName(worker(pool), queue.RunContext{RunName: run})
+			if names[name] {
+				t.Fatalf("expected a unique container name for run %s pool %s, got %s", run, pool, name)
+			}
+			names[name] = true
+		}
+	}
+}
+
+func TestContainerNameIsValid(t *testing.T) {
+	c := llir.ShellComponent{Component: lunchpail.WorkersComponent, GroupName: "my pool/1", InstanceName: "w0"}
+	if name := containerName(c, queue.RunContext{RunName: "r"}); notContainerName.MatchString(name) {
+		t.Fatalf("expected a valid container name, got %s", name)
+	}
+}
+
+// A worker of an application with an image, in the given run
+func containerized(run string) (llir.ShellComponent, llir.LLIR) {
+	c := llir.ShellComponent{Component: lunchpail.WorkersComponent, GroupName: "pool", InstanceName: "w0"}
+	c.Application.Spec.Image = "docker.io/alpine:3"
+	return c, llir.LLIR{Context: llir.Context{Run: queue.RunContext{RunName: run}}}
+}
+
+// The value following each occurrence of the given flag
+func flagValues(args []string, flag string) []string {
+	values := []string{}
+	for i, arg := range args {
+		if arg == flag && i+1 < len(args) {
+			values = append(values, args[i+1])
+		}
+	}
+	return values
+}
+
+// Do the given args include want, in order and adjacent?
+func isSubsequence(args, want []string) bool {
+	for i := range args {
+		if i+len(want) <= len(args) && slices.Equal(args[i:i+len(want)], want) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestContainerize(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("LUNCHPAIL_VENV_CACHEDIR", "")
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, ir := containerized("r")
+	c.Scheduling.Cpu = "1500m"
+	c.Scheduling.Gpu = 2
+
+	credentials, err := files.QueueCredentialsFile(ir.Context.Run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rundir := filepath.Dir(credentials)
+
+	tests := []struct {
+		cli  images.ContainerCli
+		user []string
+		gpus []string
+	}{
+		{images.Docker, []string{"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())}, []string{"--gpus", "2"}},
+		{images.Podman, []string{"--userns=keep-id"}, []string{"--device", "nvidia.com/gpu=all"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.cli), func(t *testing.T) {
+			env := []string{"PATH=/host/bin", "HOME=/host/home", "LUNCHPAIL_EXE=/host/lunchpail", "lunchpail_queue_ca=line1\nline2"}
+			cmd, err := containerize(context.Background(), tt.cli, c, ir, "/work", "./main.sh", env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			args := cmd.Args[1:]
+
+			if cmd.Args[0] != string(tt.cli) || args[0] != "run" || !slices.Contains(args, "--rm") {
+				t.Errorf("expected %s run --rm, got %v", tt.cli, cmd.Args)
+			}
+			if !slices.Equal(args[len(args)-3:], []string{c.Application.Spec.Image, "-c", "./main.sh"}) {
+				t.Errorf("expected to end with the image and command, got %v", args)
+			}
+			if name := flagValues(args, "--name"); !slices.Equal(name, []string{containerName(c, ir.Context.Run)}) {
+				t.Errorf("expected the container to be named, got %v", name)
+			}
+			if entrypoint := flagValues(args, "--entrypoint"); !slices.Equal(entrypoint, []string{"/bin/sh"}) {
+				t.Errorf("expected a /bin/sh entrypoint, got %v", entrypoint)
+			}
+			if cpus := flagValues(args, "--cpus"); !slices.Equal(cpus, []string{"1.5"}) {
+				t.Errorf("expected 1.5 cpus, got %v", cpus)
+			}
+			if !isSubsequence(args, tt.user) {
+				t.Errorf("expected %v, got %v", tt.user, args)
+			}
+			if !isSubsequence(args, tt.gpus) {
+				t.Errorf("expected %v, got %v", tt.gpus, args)
+			}
+
+			wantVolumes := []string{"/work:/work", rundir + ":" + rundir + ":ro", exe + ":" + containerExe + ":ro"}
+			if volumes := flagValues(args, "--volume"); !slices.Equal(volumes, wantVolumes) {
+				t.Errorf("expected volumes %v, got %v", wantVolumes, volumes)
+			}
+
+			// Env is passed by name, with values in the cli's own env
+			wantEnv := []string{"HOME=/work", "LUNCHPAIL_EXE", "lunchpail_queue_ca"}
+			if names := flagValues(args, "--env"); !slices.Equal(names, wantEnv) {
+				t.Errorf("expected env %v, got %v", wantEnv, names)
+			}
+			if !slices.Contains(cmd.Env, "LUNCHPAIL_EXE="+containerExe) || !slices.Contains(cmd.Env, "lunchpail_queue_ca=line1\nline2") {
+				t.Errorf("expected the values of the env to be given to the cli, got %v", cmd.Env)
+			}
+			if slices.Contains(cmd.Env, "PATH=/host/bin") || slices.Contains(cmd.Env, "HOME=/host/home") {
+				t.Errorf("expected the host PATH and HOME not to be passed through, got %v", cmd.Env)
+			}
+		})
+	}
+}
+
+func TestContainerizeWithoutGpusOrCpus(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	c, ir := containerized("r")
+	cmd, err := containerize(context.Background(), images.Docker, c, ir, "/work", "./main.sh", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, flag := range []string{"--cpus", "--gpus", "--device"} {
+		if slices.Contains(cmd.Args, flag) {
+			t.Errorf("expected no %s, got %v", flag, cmd.Args)
+		}
+	}
+}
+
+func TestContainerizeWithVenvCache(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("LUNCHPAIL_VENV_CACHEDIR", "/cache/venvs")
+
+	c, ir := containerized("r")
+	cmd, err := containerize(context.Background(), images.Docker, c, ir, "/work", "./main.sh", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if volumes := flagValues(cmd.Args, "--volume"); !slices.Contains(volumes, "/cache/venvs:/cache/venvs") {
+		t.Errorf("expected the venv cache to be mounted, got %v", volumes)
+	}
+}
+
+// A container cli that records the command lines it is given
+func fakeCli(t *testing.T) (images.ContainerCli, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	cli := filepath.Join(dir, "cli")
+	if err := os.WriteFile(cli, []byte("#!/bin/sh\necho \"$@\" >> "+log+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return images.ContainerCli(cli), log
+}
+
+func TestRemoveContainerOnFailure(t *testing.T) {
+	c, ir := containerized("r")
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		err     error
+		removed bool
+	}{
+		{"success", context.Background(), nil, false},
+		{"failure", context.Background(), fmt.Errorf("signal: killed"), true},
+		{"cancelled", cancelled, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, log := fakeCli(t)
+			removeContainerOnFailure(tt.ctx, cli, c, ir.Context.Run, tt.err, build.LogOptions{})
+
+			b, err := os.ReadFile(log)
+			if !tt.removed {
+				if err == nil {
+					t.Errorf("expected the container to be left alone, got %s", b)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if want := "rm --force " + containerName(c, ir.Context.Run) + "\n"; string(b) != want {
+				t.Errorf("expected %q, got %q", want, b)
+			}
+		})
+	}
+}
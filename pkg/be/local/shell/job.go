@@ -6,12 +6,11 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
-	"lunchpail.io/pkg/build"
 	"lunchpail.io/pkg/ir/llir"
 )
 
 // Run the component as a "job", with multiple workers
-func SpawnJob(ctx context.Context, c llir.ShellComponent, ir llir.LLIR, opts build.LogOptions) error {
+func SpawnJob(ctx context.Context, c llir.ShellComponent, ir llir.LLIR, opts SpawnOptions) error {
 	if c.InitialWorkers < 1 {
 		return fmt.Errorf("Invalid worker count %d for %v", c.InitialWorkers, c.C())
 	}
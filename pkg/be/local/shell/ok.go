@@ -2,9 +2,15 @@ package shell
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
 	"slices"
 	"strings"
 
+	"github.com/dustin/go-humanize"
+	"github.com/shirou/gopsutil/v4/mem"
+
 	"lunchpail.io/pkg/ir/hlir"
 	"lunchpail.io/pkg/ir/llir"
 	"lunchpail.io/pkg/lunchpail"
@@ -17,9 +23,12 @@ func isCompatibleImage(image string) bool {
 		strings.Contains(image, "python")
 }
 
-func IsCompatible(c llir.ShellComponent) error {
+// Is the given component compatible with the local backend? If
+// inContainer, the component will run in a container using its
+// application's image, if it has one.
+func IsCompatible(c llir.ShellComponent, inContainer bool) error {
 	switch {
-	case c.Application.Spec.Image != "" && !isCompatibleImage(c.Application.Spec.Image):
+	case !inContainer && c.Application.Spec.Image != "" && !isCompatibleImage(c.Application.Spec.Image):
 		if slices.IndexFunc(c.Application.Spec.Needs, func(need hlir.Needs) bool { return need.Requirements != "" }) < 0 {
 			return fmt.Errorf("Unable to target the local backend because a component '%s' needs to run in a container: %s", c.C(), c.Application.Spec.Image)
 		}
@@ -39,5 +48,45 @@ func IsCompatible(c llir.ShellComponent) error {
 		return fmt.Errorf("Unable to target local backend because a component '%s' to mount data as a filesystem", c.C())
 	}
 
+	return fitsHost(c)
+}
+
+// Can this host satisfy the resource requirements of the given component?
+func fitsHost(c llir.ShellComponent) error {
+	cores, err := c.Scheduling.CpuCores()
+	if err != nil {
+		return err
+	}
+	if ncpu := runtime.NumCPU(); cores > float64(ncpu) {
+		return fmt.Errorf("Unable to target local backend because a component '%s' needs %s cores per worker, and this host has only %d", c.C(), c.Scheduling.Cpu, ncpu)
+	} else if total := cores * float64(max(1, c.InitialWorkers)); total > float64(ncpu) {
+		fmt.Fprintf(os.Stderr, "Warning: component '%s' needs %.1f cores across its workers, and this host has only %d\n", c.C(), total, ncpu)
+	}
+
+	if c.Scheduling.Gpu > 0 {
+		if ngpu := numGpus(); c.Scheduling.Gpu > ngpu {
+			return fmt.Errorf("Unable to target local backend because a component '%s' needs %d GPUs per worker, and this host has %d", c.C(), c.Scheduling.Gpu, ngpu)
+		}
+	}
+
+	if c.MinMemoryBytes > 0 {
+		if vm, err := mem.VirtualMemory(); err == nil && c.MinMemoryBytes > vm.Total {
+			return fmt.Errorf("Unable to target local backend because a component '%s' needs %s of memory, and this host has only %s", c.C(), humanize.IBytes(c.MinMemoryBytes), humanize.IBytes(vm.Total))
+		}
+	}
+
+	if c.Scheduling.HasPlacement() {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring node selectors, tolerations, and affinity of component '%s' on the local backend\n", c.C())
+	}
+
 	return nil
 }
+
+// The number of NVIDIA GPUs on this host
+func numGpus() int {
+	out, err := exec.Command("nvidia-smi", "-L").Output()
+	if err != nil {
+		return 0
+	}
+	return strings.Count(string(out), "GPU ")
+}
@@ -12,12 +12,24 @@ import (
 
 	"lunchpail.io/pkg/be/local/files"
 	"lunchpail.io/pkg/build"
-	"lunchpail.io/pkg/fe/linker/queue"
+	q "lunchpail.io/pkg/fe/linker/queue"
 	"lunchpail.io/pkg/ir/hlir"
 	"lunchpail.io/pkg/ir/llir"
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/lunchpail"
+	images "lunchpail.io/pkg/lunchpail/images/build"
 )
 
-func Spawn(ctx context.Context, c llir.ShellComponent, ir llir.LLIR, opts build.LogOptions) error {
+type SpawnOptions struct {
+	build.LogOptions
+
+	// Run components that have an image in a container, using
+	// this cli; if "", run them directly on this host
+	ContainerCli images.ContainerCli
+}
+
+func Spawn(ctx context.Context, c llir.ShellComponent, ir llir.LLIR, sopts SpawnOptions) (err error) {
+	opts := sopts.LogOptions
 	pidfile, err := files.Pidfile(ir.Context.Run, c.InstanceName, c.C(), true)
 	if err != nil {
 		return err
@@ -46,7 +58,21 @@ func Spawn(ctx context.Context, c llir.ShellComponent, ir llir.LLIR, opts build.
 		fmt.Fprintf(os.Stderr, "Launching process for component %v instance %s with commandline: %s\n", c.C(), c.InstanceName, command)
 	}
 
-	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	env, err := addEnv(c, ir)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	if sopts.ContainerCli != "" && c.Application.Spec.Image != "" {
+		if cmd, err = containerize(ctx, sopts.ContainerCli, c, ir, workdir, command, env); err != nil {
+			return err
+		}
+		defer func() { removeContainerOnFailure(ctx, sopts.ContainerCli, c, ir.Context.Run, err, opts) }()
+	} else {
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", command)
+		cmd.Env = env
+	}
 	cmd.Dir = workdir
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
@@ -62,12 +88,6 @@ func Spawn(ctx context.Context, c llir.ShellComponent, ir llir.LLIR, opts build.
 		cmd.Stderr = errfile
 	}
 
-	if env, err := addEnv(c, ir); err != nil {
-		return err
-	} else {
-		cmd.Env = env
-	}
-
 	if err := cmd.Start(); err != nil {
 		return err
 	}
@@ -117,7 +137,7 @@ func addEnv(c llir.ShellComponent, ir llir.LLIR) ([]string, error) {
 		return env, err
 	}
 
-	env, err = addQueueEnv(env, ir)
+	env, err = addQueueEnv(env, c, ir)
 	if err != nil {
 		return env, err
 	}
@@ -133,12 +153,30 @@ func addAppEnv(env []string, c llir.ShellComponent) ([]string, error) {
 	return env, nil
 }
 
-func addQueueEnv(env []string, ir llir.LLIR) ([]string, error) {
+func addQueueEnv(env []string, c llir.ShellComponent, ir llir.LLIR) ([]string, error) {
 	prefix := "lunchpail_queue_" // TODO share with be/kubernetes/shell.envForQueue()
 
 	env = append(env, prefix+"endpoint="+ir.Context.Queue.Endpoint)
-	env = append(env, prefix+"accessKeyID="+ir.Context.Queue.AccessKey)
-	env = append(env, prefix+"secretAccessKey="+ir.Context.Queue.SecretKey)
+
+	// The credentials were saved to a file when the run started
+	f, err := files.QueueCredentialsFile(ir.Context.Run)
+	if err != nil {
+		return env, err
+	}
+	env = append(env, queue.CredentialsFileEnvVar+"="+f)
+
+	if ca := ir.Context.Queue.CA(); ca != "" {
+		env = append(env, prefix+"ca="+ca)
+
+		// Only the minio component needs the server certificate and key
+		if c.C() == lunchpail.MinioComponent {
+			dir, err := files.QueueCertsDir(ir.Context.Run)
+			if err != nil {
+				return env, err
+			}
+			env = append(env, prefix+"certsDir="+dir)
+		}
+	}
 
 	return env, nil
 }
@@ -155,12 +193,10 @@ func addAllSecrets(env []string, datasets []hlir.Dataset, ir llir.LLIR) ([]strin
 }
 
 func addSecret(env []string, dataset hlir.Dataset, ir llir.LLIR) ([]string, error) {
-	if dataset.S3.EnvFrom.Prefix != "" && dataset.S3.Rclone.RemoteName != "" {
-		isValid, spec, err := queue.SpecFromRcloneRemoteName(dataset.S3.Rclone.RemoteName, "", "", 0)
+	if dataset.S3.EnvFrom.Prefix != "" && q.HasS3Credentials(dataset) {
+		spec, err := q.SpecFromDataset(context.Background(), dataset, "", 0)
 		if err != nil {
 			return env, err
-		} else if !isValid {
-			return env, fmt.Errorf("Invalid or missing rclone config for given remote=%s", dataset.S3.Rclone.RemoteName)
 		}
 
 		env = append(env, dataset.S3.EnvFrom.Prefix+"endpoint="+strings.Replace(spec.Endpoint, "$TEST_QUEUE_ENDPOINT", ir.Context.Queue.Endpoint, -1))
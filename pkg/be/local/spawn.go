@@ -4,14 +4,23 @@ import (
 	"context"
 
 	"lunchpail.io/pkg/be/local/shell"
-	"lunchpail.io/pkg/build"
 	"lunchpail.io/pkg/ir/llir"
+	images "lunchpail.io/pkg/lunchpail/images/build"
 )
 
-func (backend Backend) spawn(ctx context.Context, c llir.ShellComponent, ir llir.LLIR, opts build.LogOptions) error {
+func (backend Backend) spawn(ctx context.Context, c llir.ShellComponent, ir llir.LLIR, opts llir.Options) error {
+	sopts := shell.SpawnOptions{LogOptions: *opts.Log}
+	if opts.LocalContainers {
+		cli, err := images.WhichContainerCli()
+		if err != nil {
+			return err
+		}
+		sopts.ContainerCli = cli
+	}
+
 	if c.RunAsJob {
-		return shell.SpawnJob(ctx, c, ir, opts)
+		return shell.SpawnJob(ctx, c, ir, sopts)
 	} else {
-		return shell.Spawn(ctx, c, ir, opts)
+		return shell.Spawn(ctx, c, ir, sopts)
 	}
 }
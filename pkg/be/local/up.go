@@ -9,21 +9,37 @@ import (
 
 	"lunchpail.io/pkg/be/local/files"
 	"lunchpail.io/pkg/be/local/shell"
+	q "lunchpail.io/pkg/fe/linker/queue"
 	"lunchpail.io/pkg/ir/llir"
+	"lunchpail.io/pkg/runtime/sandbox"
 )
 
 // Bring up the linked application
 func (backend Backend) Up(octx context.Context, ir llir.LLIR, opts llir.Options, isRunning chan llir.Context) error {
 	// Fail fast if this backend doesn't support the given IR
-	if err := backend.IsCompatible(ir); err != nil {
+	if err := backend.IsCompatible(ir, opts); err != nil {
 		return err
 	}
+	if opts.Sandbox {
+		if err := sandbox.Available(opts.SandboxUnconfinedNet); err != nil {
+			return err
+		}
+	}
 
 	if ir.Queue().Auto {
 		ir.Context = llir.Context{
 			Run:   ir.Context.Run,
 			Queue: ir.Queue().UpdateEndpoint(fmt.Sprintf("localhost:%d", ir.Queue().Port)),
 		}
+
+		if opts.QueueTLS && ir.Queue().TLS == nil {
+			// Containerized workers reach the queue via the docker host
+			spec, err := q.WithTLS(ir.Queue(), "localhost", "127.0.0.1", "host.docker.internal", "172.17.0.1")
+			if err != nil {
+				return err
+			}
+			ir.Context.Queue = spec
+		}
 	}
 
 	// Write a pid file to indicate the pid of this process
@@ -38,10 +54,15 @@ func (backend Backend) Up(octx context.Context, ir llir.LLIR, opts llir.Options,
 		return err
 	}
 
+	// Write a breadcrumb that records the tags of this run
+	if err := saveTags(ir); err != nil {
+		return err
+	}
+
 	// Launch each of the components
 	group, ctx := errgroup.WithContext(octx)
 	for _, c := range ir.Components {
-		group.Go(func() error { return backend.spawn(ctx, c, ir, *opts.Log) })
+		group.Go(func() error { return backend.spawn(ctx, c, ir, opts) })
 	}
 
 	// Indicate that we are off to the races
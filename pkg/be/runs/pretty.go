@@ -2,6 +2,8 @@ package runs
 
 import (
 	"fmt"
+	"io"
+	"sort"
 	"strings"
 	"time"
 
@@ -18,3 +20,36 @@ func Pretty(runs []Run) string {
 
 	return strings.Join(names, "\n")
 }
+
+// Write one line per run, most recent first: its name (right-aligned),
+// creation time, and tags, if it has any; or, if nameOnly, just its name
+func Table(w io.Writer, runs []Run, nameOnly, latest bool) {
+	if len(runs) == 0 {
+		return
+	}
+
+	sort.Slice(runs, func(i, j int) bool { return runs[i].CreationTimestamp.After(runs[j].CreationTimestamp) })
+
+	if latest {
+		runs = runs[:1]
+	}
+
+	maxlen := 0
+	if !nameOnly {
+		for _, run := range runs {
+			l := len(run.Name)
+			if l > maxlen {
+				maxlen = l
+			}
+		}
+	}
+	for _, run := range runs {
+		if nameOnly {
+			fmt.Fprintln(w, run.Name)
+		} else if len(run.Tags) == 0 {
+			fmt.Fprintf(w, "%*s %s\n", maxlen, run.Name, run.CreationTimestamp)
+		} else {
+			fmt.Fprintf(w, "%*s %s %s\n", maxlen, run.Name, run.CreationTimestamp, strings.Join(run.Tags, ","))
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package runs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTable(t *testing.T) {
+	then := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	runs := []Run{
+		{Name: "older", CreationTimestamp: then},
+		{Name: "new", CreationTimestamp: then.Add(time.Hour), Tags: []string{"nightly", "gpu"}},
+	}
+
+	tests := []struct {
+		name     string
+		nameOnly bool
+		latest   bool
+		want     []string
+	}{
+		{"table", false, false, []string{
+			"  new " + then.Add(time.Hour).String() + " nightly,gpu",
+			"older " + then.String(),
+		}},
+		{"names", true, false, []string{"new", "older"}},
+		{"latest", true, true, []string{"new"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			Table(&out, runs, tt.nameOnly, tt.latest)
+
+			want := strings.Join(tt.want, "\n") + "\n"
+			if out.String() != want {
+				t.Errorf("expected %q, got %q", want, out.String())
+			}
+		})
+	}
+}
+
+func TestTableWithoutRuns(t *testing.T) {
+	var out strings.Builder
+	Table(&out, nil, false, true)
+	if out.Len() != 0 {
+		t.Errorf("expected no output, got %q", out.String())
+	}
+}
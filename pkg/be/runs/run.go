@@ -1,8 +1,30 @@
 package runs
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 type Run struct {
 	Name              string
 	CreationTimestamp time.Time
+
+	// Tags of the applications that make up this run
+	Tags []string
+}
+
+// Does this run carry all of the given tags?
+func (run Run) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(run.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Those of the given runs that carry all of the given tags
+func WithTags(runs []Run, tags []string) []Run {
+	return slices.DeleteFunc(runs, func(run Run) bool { return !run.HasTags(tags) })
 }
@@ -0,0 +1,34 @@
+package runs
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWithTags(t *testing.T) {
+	all := []Run{
+		{Name: "a", Tags: []string{"nightly", "gpu"}},
+		{Name: "b", Tags: []string{"nightly"}},
+		{Name: "c"},
+	}
+
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{nil, []string{"a", "b", "c"}},
+		{[]string{"nightly"}, []string{"a", "b"}},
+		{[]string{"nightly", "gpu"}, []string{"a"}},
+		{[]string{"gpu", "weekly"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		names := []string{}
+		for _, run := range WithTags(slices.Clone(all), tt.tags) {
+			names = append(names, run.Name)
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("tags %v: expected %v, got %v", tt.tags, tt.want, names)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package boot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"lunchpail.io/pkg/be"
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/llir"
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/runtime/alerts"
+	s3 "lunchpail.io/pkg/runtime/queue"
+)
+
+// Do any of the alert specs ask us to execute local commands?
+func hasExecAlerts(ir llir.LLIR) bool {
+	return ir.Context.Run.Step == 0 && slices.ContainsFunc(ir.Alerts, func(spec hlir.AlertSpec) bool { return len(spec.Exec) > 0 })
+}
+
+// The workstealer records the events it fires on behalf of exec
+// sinks. Here, we execute them on the local machine.
+func execAlerts(ctx context.Context, backend be.Backend, ir llir.LLIR, opts build.LogOptions) error {
+	client, err := s3.NewS3ClientForRun(ctx, backend, ir.Context.Run, ir.Context.Queue, opts)
+	if err != nil {
+		return err
+	}
+	run := client.RunContext
+	defer client.Stop()
+
+	if err := client.Mkdirp(run.Bucket); err != nil {
+		return err
+	}
+
+	objc, errc := client.Listen(run.Bucket, run.AsFile(queue.Alerts), "", false)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errc:
+			if err == nil || strings.Contains(err.Error(), "EOF") || strings.Contains(err.Error(), "connection refused") {
+				return nil
+			} else if !errors.Is(err, s3.ListenNotSupportedError) {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		case object := <-objc:
+			if object == "" {
+				continue
+			}
+
+			content, err := client.Get(run.Bucket, object)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading alert %s: %v\n", object, err)
+				continue
+			}
+
+			var event alerts.Event
+			if err := json.Unmarshal([]byte(content), &event); err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing alert %s: %v\n", object, err)
+				continue
+			}
+
+			if event.Kind == hlir.AlertOnCompletion {
+				// we handle this ourselves, see execCompletionAlerts()
+				continue
+			} else if event.Spec < 0 || event.Spec >= len(ir.Alerts) {
+				fmt.Fprintf(os.Stderr, "Ignoring alert for unknown spec %d\n", event.Spec)
+				continue
+			}
+
+			if err := alerts.Exec(ctx, ir.Alerts[event.Spec], event); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+}
+
+// The run may be torn down as soon as it completes, so we cannot rely
+// on picking up the workstealer's record of the completion event.
+func execCompletionAlerts(ctx context.Context, ir llir.LLIR, runErr error) {
+	message := "Run has completed"
+	if runErr != nil {
+		message = "Run has completed with errors: " + runErr.Error()
+	}
+
+	event := alerts.Event{Kind: hlir.AlertOnCompletion, RunName: ir.Context.Run.RunName, Timestamp: time.Now(), Message: message}
+	for idx, spec := range ir.Alerts {
+		event.Spec = idx
+		if err := alerts.Exec(ctx, spec, event); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
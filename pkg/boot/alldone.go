@@ -7,8 +7,10 @@ import (
 	"strings"
 
 	"lunchpail.io/pkg/be"
+	"lunchpail.io/pkg/be/local/files"
 	"lunchpail.io/pkg/build"
 	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/runtime/logs"
 	s3 "lunchpail.io/pkg/runtime/queue"
 )
 
@@ -23,6 +25,14 @@ func waitForAllDone(ctx context.Context, backend be.Backend, run queue.RunContex
 	}
 	defer client.Stop()
 
+	// Keep the logs that components ship to the queue, which will
+	// otherwise be lost when the queue is torn down
+	archive := true
+	if err := logs.WillArchive(client.S3Client, client.RunContext); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: logs will not be archived: %v\n", err)
+		archive = false
+	}
+
 	if err := client.WaitTillExists(client.RunContext.Bucket, client.RunContext.AsFile(queue.AllDoneMarker)); err != nil {
 		return err
 	}
@@ -30,5 +40,24 @@ func waitForAllDone(ctx context.Context, backend be.Backend, run queue.RunContex
 	if opts.Verbose {
 		fmt.Fprintln(os.Stderr, "Got all done. Cleaning up", client.RunContext.Step)
 	}
+
+	if archive {
+		if err := archiveLogs(client.S3Client, client.RunContext, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to archive logs: %v\n", err)
+		}
+	}
+
 	return nil
 }
+
+func archiveLogs(client s3.S3Client, run queue.RunContext, opts build.LogOptions) error {
+	dir, err := files.LogArchiveDir(run.RunName)
+	if err != nil {
+		return err
+	}
+
+	if opts.Verbose {
+		fmt.Fprintln(os.Stderr, "Archiving logs to", dir)
+	}
+	return logs.Archive(client, run, dir)
+}
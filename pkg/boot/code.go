@@ -0,0 +1,35 @@
+//go:build full || manage
+
+package boot
+
+import (
+	"context"
+
+	"lunchpail.io/pkg/be"
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/llir"
+	s3 "lunchpail.io/pkg/runtime/queue"
+)
+
+// Do any of the components of this run carry binary code, e.g.
+// compiled executables?
+func hasBinaryCode(ir llir.LLIR) bool {
+	for _, c := range ir.Components {
+		if hlir.HasBinaryCode(c.Application.Spec.Code) {
+			return true
+		}
+	}
+	return false
+}
+
+// Upload the binary code of the components of this run, for workers
+// that will download it
+func uploadCode(ctx context.Context, backend be.Backend, ir llir.LLIR, opts build.LogOptions) error {
+	apps := []hlir.Application{}
+	for _, c := range ir.Components {
+		apps = append(apps, c.Application)
+	}
+
+	return s3.UploadCode(ctx, backend, ir.Context.Run, ir.Context.Queue, apps, opts)
+}
@@ -129,6 +129,14 @@ func upLLIR(ctx context.Context, backend be.Backend, ir llir.LLIR, opts UpOption
 	isRunning := make(chan llir.Context) // is the job ready for business?
 	isRunning6 := make(chan llir.Context)
 	needsCatAndRedirect := len(opts.Inputs) > 0 || ir.Context.Run.Step > 0 || ir.HasDispatcher()
+
+	// Workers other than local ones run a generic lunchpail, which
+	// does not carry the application's Python wheels
+	needsWheelhouse := opts.BuildOptions.OfflinePython && opts.BuildOptions.Target.Platform != target.Local
+
+	// Kubernetes workers take their code from a ConfigMap, which
+	// cannot carry binary code
+	needsCode := opts.BuildOptions.Target.Platform == target.Kubernetes && hasBinaryCode(ir)
 	go func() {
 		select {
 		case <-cancellable.Done():
@@ -145,6 +153,15 @@ func upLLIR(ctx context.Context, backend be.Backend, ir llir.LLIR, opts UpOption
 			if opts.Watch {
 				isRunning6 <- ctx
 			}
+			if hasExecAlerts(ir) {
+				isRunning6 <- ctx
+			}
+			if needsWheelhouse {
+				isRunning6 <- ctx
+			}
+			if needsCode {
+				isRunning6 <- ctx
+			}
 		}
 	}()
 
@@ -225,6 +242,42 @@ func upLLIR(ctx context.Context, backend be.Backend, ir llir.LLIR, opts UpOption
 		}
 	}()
 
+	if hasExecAlerts(ir) {
+		go func() {
+			select {
+			case <-cancellable.Done():
+			case <-isRunning6:
+			}
+			if err := execAlerts(cancellable, backend, ir, *opts.BuildOptions.Log); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+	}
+
+	if needsWheelhouse {
+		go func() {
+			select {
+			case <-cancellable.Done():
+			case <-isRunning6:
+			}
+			if err := uploadWheelhouse(cancellable, backend, ir, *opts.BuildOptions.Log); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+	}
+
+	if needsCode {
+		go func() {
+			select {
+			case <-cancellable.Done():
+			case <-isRunning6:
+			}
+			if err := uploadCode(cancellable, backend, ir, *opts.BuildOptions.Log); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+	}
+
 	//inject executable into s3
 	if opts.Executable != "" {
 		go func() {
@@ -297,19 +350,41 @@ func upLLIR(ctx context.Context, backend be.Backend, ir llir.LLIR, opts UpOption
 		<-logsDone
 	}
 
+	var err error
 	switch {
 	case gotSigInt:
 		// then squash any other errors as they are likely
 		// side-effects of the user-initiated cancellation
+		return nil
 	case errorFromTask != nil:
-		return errorFromTask
+		err = errorFromTask
 	case errorFromIo != nil:
-		return errorFromIo
+		err = errorFromIo
 	case errorFromUp != nil:
-		return errorFromUp
+		err = errorFromUp
 	case errorFromAllDone != nil:
-		return errorFromAllDone
+		err = errorFromAllDone
+	}
+
+	if hasExecAlerts(ir) {
+		execCompletionAlerts(ctx, ir, err)
+	}
+
+	return err
+}
+
+// Upload the Python wheels we carry, for workers that will install
+// from them
+func uploadWheelhouse(ctx context.Context, backend be.Backend, ir llir.LLIR, opts build.LogOptions) error {
+	wheelhouse, stagePath, err := build.WheelhouseDirWithStage()
+	if stagePath != "" {
+		defer os.RemoveAll(stagePath)
+	}
+	if err != nil {
+		return err
+	} else if wheelhouse == "" {
+		return fmt.Errorf("This application was built to install Python requirements offline, but carries no wheels")
 	}
 
-	return nil
+	return s3.UploadWheelhouse(ctx, backend, ir.Context.Run, ir.Context.Queue, wheelhouse, opts)
 }
@@ -1,12 +1,18 @@
 package build
 
-import "embed"
+import _ "embed"
 
 //go:generate /bin/sh -c "[ ! -e ./app.tar.gz ] && tar -C $(mktemp -d) -zcf app.tar.gz . || exit 0"
-//go:embed app.tar.gz
-var appTemplate embed.FS
 
-const appTemplateFile = "app.tar.gz"
-
-// NOTE: keep this in sync with ... this directory and appTemplateFile
+// NOTE: keep this in sync with ... this directory
 const embededTemplatePath = "pkg/build/app.tar.gz"
+
+// A build compiled with Go cannot carry a trailer (for darwin, its
+// code signature would not survive one), so it embeds the same
+// record here instead. Empty, for lunchpail itself.
+//
+//go:embed trailer.bin
+var embeddedTrailer []byte
+
+// NOTE: keep this in sync with ... this directory
+const embeddedTrailerPath = "pkg/build/trailer.bin"
@@ -106,15 +106,28 @@ func IsBuilt() bool {
 	return strings.TrimSpace(name) != "<none>"
 }
 
-func DropBreadcrumbs(buildName, appVersion string, testData hlir.TestData, opts Options, stagedir string) error {
-	user, err := user.Current()
-	if err != nil {
-		return err
-	}
+func DropBreadcrumbs(buildName, appVersion string, testData hlir.TestData, opts Options, reproducible bool, stagedir string) error {
+	date := time.Now().String()
+	by := reproducibleBuilder
+	hostname := reproducibleBuilder
 
-	hostname, err := os.Hostname()
-	if err != nil {
-		return err
+	if reproducible {
+		if t, err := ReproducibleTime(); err != nil {
+			return err
+		} else {
+			date = t.String()
+		}
+	} else {
+		user, err := user.Current()
+		if err != nil {
+			return err
+		}
+		by = fmt.Sprintf("%s <%s>", user.Name, user.Username)
+
+		hostname, err = os.Hostname()
+		if err != nil {
+			return err
+		}
 	}
 
 	if len(testData) > 0 {
@@ -129,9 +142,9 @@ func DropBreadcrumbs(buildName, appVersion string, testData hlir.TestData, opts
 		return err
 	} else if err := os.WriteFile(filepath.Join(stagedir, "pkg/build/appVersion.txt"), []byte(appVersion), 0644); err != nil {
 		return err
-	} else if err := os.WriteFile(filepath.Join(stagedir, "pkg/build/buildDate.txt"), []byte(time.Now().String()), 0644); err != nil {
+	} else if err := os.WriteFile(filepath.Join(stagedir, "pkg/build/buildDate.txt"), []byte(date), 0644); err != nil {
 		return err
-	} else if err := os.WriteFile(filepath.Join(stagedir, "pkg/build/builtBy.txt"), []byte(fmt.Sprintf("%s <%s>", user.Name, user.Username)), 0644); err != nil {
+	} else if err := os.WriteFile(filepath.Join(stagedir, "pkg/build/builtBy.txt"), []byte(by), 0644); err != nil {
 		return err
 	} else if err := os.WriteFile(filepath.Join(stagedir, "pkg/build/builtOn.txt"), []byte(hostname), 0644); err != nil {
 		return err
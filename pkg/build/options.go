@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"time"
 
 	"lunchpail.io/pkg/be/target"
 	"lunchpail.io/pkg/ir/hlir"
@@ -30,6 +31,11 @@ type Options struct {
 	OverrideValues         []string `yaml:"overrideValues,omitempty"`
 	OverrideFileValues     []string `yaml:"overrideFileValues,omitempty"`
 	Queue                  string   `yaml:",omitempty"`
+	QueueSecret            string   `yaml:"queueSecret,omitempty"`
+	QueueTLS               bool     `yaml:"queueTLS,omitempty"`
+	Sandbox                bool     `yaml:"sandbox,omitempty"`
+	SandboxUnconfinedNet   bool     `yaml:"sandboxUnconfinedNet,omitempty"`
+	LocalContainers        bool     `yaml:"localContainers,omitempty"`
 	HasGpuSupport          bool     `yaml:"hasGpuSupport,omitempty"`
 	ApiKey                 string   `yaml:"apiKey,omitempty"`
 	ResourceGroupID        string   `yaml:"resourceGroupID,omitempty"`
@@ -49,12 +55,52 @@ type Options struct {
 
 	// Clean up any caches prior to exiting
 	AutoClean bool `yaml:"autoClean,omitempty"`
+
+	// Where to send notifications of run events
+	Alerts hlir.AlertSpec `yaml:"alerts,omitempty"`
+
+	// Speculatively re-execute straggling tasks on idle workers
+	Speculate bool `yaml:"speculate,omitempty"`
+
+	// Lower worker pools to Kubernetes Indexed Jobs, rather than
+	// plain Jobs
+	IndexedJobs bool `yaml:"indexedJobs,omitempty"`
+
+	// Submit worker Jobs to this Kueue LocalQueue for admission
+	KueueQueue string `yaml:"kueueQueue,omitempty"`
+
+	// Schedule the workers of each pool as a gang, using this
+	// (e.g. coscheduling or volcano) scheduler
+	SchedulerName string `yaml:"schedulerName,omitempty"`
+
+	// How long to wait on Kubernetes for worker pools to become
+	// ready, before warning that they are not
+	ReadyTimeout time.Duration `yaml:"readyTimeout,omitempty"`
+
+	// How long to report on the admission of worker pools on
+	// Kubernetes subject to queued admission or gang scheduling,
+	// before warning that they are still pending
+	AdmissionTimeout time.Duration `yaml:"admissionTimeout,omitempty"`
+
+	// Reject unknown fields and kinds in the application YAML,
+	// rather than warning about them
+	Strict bool `yaml:"strict,omitempty"`
+
+	// Install Python requirements only from the wheels bundled at
+	// build time, never from a package index
+	OfflinePython bool `yaml:"offlinePython,omitempty"`
 }
 
 //go:embed buildOptions.json
 var valuesJson []byte
 
 func saveOptions(stagedir string, opts Options) error {
+	// Webhook and Slack URLs carry their own credentials, and a
+	// built binary may be handed to anyone, so these must be
+	// given when the binary is run
+	opts.Alerts.Webhooks = nil
+	opts.Alerts.Slack = nil
+
 	if serialized, err := json.Marshal(opts); err != nil {
 		return err
 	} else {
@@ -102,6 +148,7 @@ func eitherB(a bool, b bool) bool {
 
 func (cliOpts Options) overlay(builtOpts Options) Options {
 	cliOpts.Queue = either(builtOpts.Queue, cliOpts.Queue)
+	cliOpts.QueueSecret = either(builtOpts.QueueSecret, cliOpts.QueueSecret)
 	cliOpts.ImagePullSecret = either(builtOpts.ImagePullSecret, cliOpts.ImagePullSecret)
 	cliOpts.Target = &TargetOptions{
 		Platform:  eitherPlatform(builtOpts.Target.Platform, cliOpts.Target.Platform),
@@ -111,6 +158,11 @@ func (cliOpts Options) overlay(builtOpts Options) Options {
 	// TODO here... how do we determine that boolean values were unset?
 	cliOpts.HasGpuSupport = eitherB(builtOpts.HasGpuSupport, cliOpts.HasGpuSupport)
 	cliOpts.CreateNamespace = eitherB(builtOpts.CreateNamespace, cliOpts.CreateNamespace)
+	cliOpts.QueueTLS = eitherB(builtOpts.QueueTLS, cliOpts.QueueTLS)
+	cliOpts.Sandbox = eitherB(builtOpts.Sandbox, cliOpts.Sandbox)
+	cliOpts.SandboxUnconfinedNet = eitherB(builtOpts.SandboxUnconfinedNet, cliOpts.SandboxUnconfinedNet)
+	cliOpts.LocalContainers = eitherB(builtOpts.LocalContainers, cliOpts.LocalContainers)
+	cliOpts.OfflinePython = eitherB(builtOpts.OfflinePython, cliOpts.OfflinePython)
 
 	// careful: `--set x=3 --set x=4` results in x having
 	// value 4, so we need to place the built
@@ -0,0 +1,48 @@
+package build
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+func TestSaveOptionsOmitsAlertURLs(t *testing.T) {
+	stagedir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(stagedir, "pkg/build"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{Alerts: hlir.AlertSpec{
+		Webhooks: []string{"https://example.com/hook?token=secret"},
+		Slack:    []string{"https://hooks.slack.com/services/secret"},
+		Exec:     []string{"notify-send done"},
+		On:       []string{"completion"},
+	}}
+	if err := saveOptions(stagedir, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(stagedir, "pkg/build/buildOptions.json"))
+	if err != nil {
+		t.Fatal(err)
+	} else if strings.Contains(string(b), "secret") {
+		t.Fatalf("expected alert URLs to be left out, got %s", b)
+	}
+
+	var saved Options
+	if err := json.Unmarshal(b, &saved); err != nil {
+		t.Fatal(err)
+	} else if !slices.Equal(saved.Alerts.Exec, opts.Alerts.Exec) || !slices.Equal(saved.Alerts.On, opts.Alerts.On) {
+		t.Errorf("expected the other alert options to be kept, got %+v", saved.Alerts)
+	}
+
+	// The caller's options are left alone
+	if len(opts.Alerts.Webhooks) != 1 || len(opts.Alerts.Slack) != 1 {
+		t.Errorf("expected the caller's alert URLs to be untouched, got %+v", opts.Alerts)
+	}
+}
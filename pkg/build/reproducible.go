@@ -0,0 +1,28 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Who and where we claim a reproducible build was made
+const reproducibleBuilder = "reproducible"
+
+// The timestamp stamped on reproducible builds. Following the usual
+// convention, this is SOURCE_DATE_EPOCH if set, otherwise the Unix
+// epoch.
+func ReproducibleTime() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Unix(0, 0).UTC(), nil
+	}
+
+	secs, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid SOURCE_DATE_EPOCH %s: %v", epoch, err)
+	}
+
+	return time.Unix(secs, 0).UTC(), nil
+}
@@ -0,0 +1,183 @@
+package build
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+//go:embed sbom.json
+var sbomJson []byte
+
+// A software bill of materials for the application payload of a build
+type SBOM struct {
+	// Every file in the application payload
+	Files []Artifact `json:"files"`
+
+	// The source code of the application
+	Sources []Artifact `json:"sources,omitempty"`
+
+	// Data blobs carried by the application
+	Blobs []Artifact `json:"blobs,omitempty"`
+
+	// Packages the application needs, e.g. "python: numpy==2.0.0"
+	Requirements []string `json:"requirements,omitempty"`
+
+	// Images the application runs in
+	Images []string `json:"images,omitempty"`
+}
+
+type Artifact struct {
+	Name   string `json:"name"`
+	Sha256 string `json:"sha256"`
+}
+
+func artifactOf(name string, content []byte) Artifact {
+	sum := sha256.Sum256(content)
+	return Artifact{name, hex.EncodeToString(sum[:])}
+}
+
+// The SBOM of this build, if it has one
+func BillOfMaterials() (sbom SBOM, err error) {
+	if len(sbomJson) == 0 {
+		return sbom, fmt.Errorf("This build has no bill of materials")
+	}
+
+	err = json.Unmarshal(sbomJson, &sbom)
+	return
+}
+
+// Describe the application template staged in appTemplatePath, and
+// leave that description as a breadcrumb in stagedir
+func DropBillOfMaterials(appTemplatePath, stagedir string) error {
+	var sbom SBOM
+
+	err := filepath.WalkDir(appTemplatePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if path != appTemplatePath && excludeFromAppTemplate(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		} else if !d.Type().IsRegular() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(appTemplatePath, path)
+		if err != nil {
+			return err
+		}
+		sbom.Files = append(sbom.Files, artifactOf(filepath.ToSlash(rel), content))
+
+		if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+			sbom.addApplications(content)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slices.Sort(sbom.Requirements)
+	sbom.Requirements = slices.Compact(sbom.Requirements)
+	slices.Sort(sbom.Images)
+	sbom.Images = slices.Compact(sbom.Images)
+
+	b, err := json.MarshalIndent(sbom, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(stagedir, "pkg/build/sbom.json"), b, 0644)
+}
+
+// Incorporate any Applications in the given yaml. Some yaml may be
+// templated, and so we skip over whatever does not parse.
+func (sbom *SBOM) addApplications(content []byte) {
+	d := yaml.NewDecoder(strings.NewReader(string(content)))
+
+	for {
+		var app hlir.Application
+		if err := d.Decode(&app); errors.Is(err, io.EOF) {
+			return
+		} else if err != nil {
+			// The decoder cannot recover from a syntax error
+			if _, ok := err.(*yaml.TypeError); !ok {
+				return
+			}
+			continue
+		} else if app.Kind != "Application" {
+			continue
+		}
+
+		for _, code := range app.Spec.Code {
+			sbom.Sources = append(sbom.Sources, artifactOf(app.Metadata.Name+"/"+code.Name, []byte(code.Source)))
+		}
+
+		for _, dataset := range app.Spec.Datasets {
+			if dataset.Blob.Content != "" {
+				sbom.Blobs = append(sbom.Blobs, artifactOf(dataset.Name, []byte(dataset.Blob.Content)))
+			}
+		}
+
+		for _, needs := range app.Spec.Needs {
+			if needs.Name == "conda" {
+				sbom.Requirements = append(sbom.Requirements, condaRequirements(needs.Requirements)...)
+				continue
+			}
+			for _, line := range strings.Split(needs.Requirements, "\n") {
+				if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+					sbom.Requirements = append(sbom.Requirements, needs.Name+": "+line)
+				}
+			}
+		}
+
+		if app.Spec.Image != "" {
+			sbom.Images = append(sbom.Images, app.Spec.Image)
+		}
+	}
+}
+
+// The packages of the given conda environment.yml, e.g. "conda:
+// numpy=2.0" and, for those it installs via pip, "python: foo==1.0"
+func condaRequirements(environment string) []string {
+	var env struct{ Dependencies []any }
+	if err := yaml.Unmarshal([]byte(environment), &env); err != nil {
+		return nil
+	}
+
+	reqs := []string{}
+	for _, dep := range env.Dependencies {
+		switch d := dep.(type) {
+		case string:
+			reqs = append(reqs, "conda: "+d)
+		case map[string]any:
+			pip, _ := d["pip"].([]any)
+			for _, p := range pip {
+				if s, ok := p.(string); ok {
+					reqs = append(reqs, "python: "+s)
+				}
+			}
+		}
+	}
+	return reqs
+}
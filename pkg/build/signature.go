@@ -0,0 +1,184 @@
+package build
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Where the detached signature of the given binary lives
+func SignatureFile(binary string) string {
+	return binary + ".sig"
+}
+
+// Where the public half of the given signing key lives
+func PublicKeyFile(keyPath string) string {
+	return keyPath + ".pub"
+}
+
+// Write a detached signature of the given binary, using the ed25519
+// private key in keyPath. Returns the path to the signature.
+func Sign(binary, keyPath string) (string, error) {
+	key, err := loadSigningKey(keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	digest, err := digestOf(binary)
+	if err != nil {
+		return "", err
+	}
+
+	sig, err := key.Sign(nil, digest, &ed25519.Options{Hash: crypto.SHA512})
+	if err != nil {
+		return "", err
+	}
+
+	sigPath := SignatureFile(binary)
+	return sigPath, os.WriteFile(sigPath, []byte(base64.StdEncoding.EncodeToString(sig)+"\n"), 0644)
+}
+
+// Check the detached signature of the given binary against the ed25519
+// public key in pubKeyPath
+func Verify(binary, sigPath, pubKeyPath string) error {
+	key, err := loadPublicKey(pubKeyPath)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := os.ReadFile(sigPath)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return fmt.Errorf("Invalid signature %s: %v", sigPath, err)
+	}
+
+	digest, err := digestOf(binary)
+	if err != nil {
+		return err
+	}
+
+	if err := ed25519.VerifyWithOptions(key, digest, sig, &ed25519.Options{Hash: crypto.SHA512}); err != nil {
+		return fmt.Errorf("Signature %s does not match %s", sigPath, binary)
+	}
+
+	return nil
+}
+
+// Also accepts the private key, as a convenience to whoever signed
+func loadPublicKey(keyPath string) (ed25519.PublicKey, error) {
+	b, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("No PEM-encoded public key found in %s", keyPath)
+	} else if block.Type == "PRIVATE KEY" {
+		key, err := loadSigningKey(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		return key.Public().(ed25519.PublicKey), nil
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("Public key %s is not an ed25519 key", keyPath)
+	}
+
+	return key, nil
+}
+
+// We sign a digest of the binary, as the binary may be large
+func digestOf(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha512.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+// Error unless there is a signing key in keyPath, generating one if
+// asked and there is none. We never generate a key unasked, lest a
+// mistyped path sign with a key that nobody trusts.
+func CheckSigningKey(keyPath string, generate bool) error {
+	if _, err := os.Stat(keyPath); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	} else if !generate {
+		return fmt.Errorf("Signing key %s does not exist. To generate a new key pair there, also pass --generate-signing-key", keyPath)
+	}
+
+	_, err := createSigningKey(keyPath)
+	return err
+}
+
+func loadSigningKey(keyPath string) (ed25519.PrivateKey, error) {
+	b, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("No PEM-encoded private key found in %s", keyPath)
+	}
+	priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := priv.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("Signing key %s is not an ed25519 key", keyPath)
+	}
+
+	return key, nil
+}
+
+func createSigningKey(keyPath string) (ed25519.PrivateKey, error) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	privBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	pubBytes, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privBytes}), 0600); err != nil {
+		return nil, err
+	} else if err := os.WriteFile(PublicKeyFile(keyPath), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes}), 0644); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Generated signing key %s with public key %s\n", keyPath, PublicKeyFile(keyPath))
+	return key, nil
+}
@@ -0,0 +1,74 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMissingSigningKeyIsAnError(t *testing.T) {
+	key := filepath.Join(t.TempDir(), "key.pem")
+
+	if err := CheckSigningKey(key, false); err == nil {
+		t.Fatal("expected an error for a missing signing key")
+	}
+	if _, err := os.Stat(key); !os.IsNotExist(err) {
+		t.Fatalf("expected no key to have been generated, got %v", err)
+	}
+
+	if err := CheckSigningKey(key, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{key, PublicKeyFile(key)} {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected %s to have been generated: %v", f, err)
+		}
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	key := filepath.Join(dir, "key.pem")
+	otherKey := filepath.Join(dir, "other.pem")
+	for _, k := range []string{key, otherKey} {
+		if err := CheckSigningKey(k, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	binary := filepath.Join(dir, "app")
+	if err := os.WriteFile(binary, []byte("app"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := Sign(binary, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(binary, sig, PublicKeyFile(key)); err != nil {
+		t.Fatalf("expected the signature to verify: %v", err)
+	}
+	if err := Verify(binary, sig, PublicKeyFile(otherKey)); err == nil {
+		t.Fatal("expected the signature not to verify against another key")
+	}
+
+	if err := os.WriteFile(binary, []byte("tampered"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(binary, sig, PublicKeyFile(key)); err == nil {
+		t.Fatal("expected the signature not to verify a tampered binary")
+	}
+}
+
+func TestSignWithMissingKey(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "app")
+	if err := os.WriteFile(binary, []byte("app"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Sign(binary, filepath.Join(dir, "typo.pem")); err == nil {
+		t.Fatal("expected an error signing with a missing key")
+	}
+}
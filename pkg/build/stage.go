@@ -2,10 +2,12 @@ package build
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"lunchpail.io/pkg/util"
 )
@@ -14,6 +16,11 @@ type StageOptions struct {
 	Verbose bool
 }
 
+// Mirrors the --exclude options we pass to tar
+func excludeFromAppTemplate(name string) bool {
+	return name == "LICENSE" || strings.Contains(name, ".git")
+}
+
 // return (templatePath, error)
 func StageForBuilder(opts StageOptions) (string, error) {
 	// TODO overlay on kube/common?
@@ -21,7 +28,7 @@ func StageForBuilder(opts StageOptions) (string, error) {
 	if err != nil {
 		return "", err
 
-	} else if err := util.Expand(templatePath, appTemplate, appTemplateFile); err != nil {
+	} else if err := expandAppTemplate(templatePath); err != nil {
 		return "", err
 	}
 
@@ -32,19 +39,55 @@ func StageForBuilder(opts StageOptions) (string, error) {
 	return templatePath, nil
 }
 
+// Expand the app template from our trailer, whether appended or
+// embedded at compile time. Lunchpail itself has no app template.
+func expandAppTemplate(templatePath string) error {
+	if trailerApp == nil {
+		return nil
+	}
+
+	return util.Untar(templatePath, io.NewSectionReader(trailerApp, 0, trailerApp.Size()))
+}
+
 // return (templatePath, error)
 func StageForRun(opts StageOptions) (string, error) {
 	templatePath, err := StageForBuilder(opts)
+	if err != nil {
+		return "", err
+	}
 
-	// TODO we could parallelize these two, but the overhead is probably not worth it
-	if err := emitPlaceholderChartYaml(templatePath); err != nil {
+	return templatePath, prepareForRun(templatePath)
+}
+
+// Stage the app template that the build in the given lunchpail stage
+// directory will carry, as StageForRun will once that build is emitted
+func StageBuildForRun(lunchpailStageDir string, opts StageOptions) (string, error) {
+	templatePath, err := ioutil.TempDir("", "lunchpail")
+	if err != nil {
 		return "", err
 	}
-	if err := emitPlaceholderHelmIgnore(templatePath); err != nil {
+
+	tarball, err := os.Open(filepath.Join(lunchpailStageDir, embededTemplatePath))
+	if err != nil {
 		return "", err
 	}
+	defer tarball.Close()
+
+	if err := util.Untar(templatePath, tarball); err != nil {
+		return "", err
+	} else if opts.Verbose {
+		fmt.Fprintf(os.Stderr, "Finished staging built application template to %s\n", templatePath)
+	}
+
+	return templatePath, prepareForRun(templatePath)
+}
 
-	return templatePath, err
+func prepareForRun(templatePath string) error {
+	// TODO we could parallelize these two, but the overhead is probably not worth it
+	if err := emitPlaceholderChartYaml(templatePath); err != nil {
+		return err
+	}
+	return emitPlaceholderHelmIgnore(templatePath)
 }
 
 // This is just to make helmClient.Template happy.
@@ -68,13 +111,14 @@ func emitPlaceholderHelmIgnore(templatePath string) error {
 .helmignore
 .DS_Store
 .cache
-LICENSE`
+LICENSE
+wheelhouse/`
 	return util.AppendToFile(filepath.Join(templatePath, ".helmignore"), []byte(ignore))
 }
 
 // Reverse of Stage(), store a staged local filesystem in the "right
 // place" so that future calls to Stage() will pick up the changes
-func MoveAppTemplateIntoLunchpailStage(lunchpailStageDir, appTemplatePath string, verbose bool) error {
+func MoveAppTemplateIntoLunchpailStage(lunchpailStageDir, appTemplatePath string, reproducible, verbose bool) error {
 	tarball := filepath.Join(lunchpailStageDir, embededTemplatePath)
 	verboseFlag := ""
 	if verbose {
@@ -82,6 +126,14 @@ func MoveAppTemplateIntoLunchpailStage(lunchpailStageDir, appTemplatePath string
 		fmt.Fprintf(os.Stderr, "Transferring staged app template to final stage %s -> %s\n", appTemplatePath, tarball)
 	}
 
+	if reproducible {
+		mtime, err := ReproducibleTime()
+		if err != nil {
+			return err
+		}
+		return util.TarReproducibly(tarball, appTemplatePath, excludeFromAppTemplate, mtime)
+	}
+
 	cmd := exec.Command("tar", verboseFlag, "-zcf", tarball, "--exclude", "LICENSE", "--exclude", "*.git*", "-C", appTemplatePath, ".")
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
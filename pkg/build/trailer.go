@@ -0,0 +1,300 @@
+package build
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// A build may carry its application as a trailer appended to a
+// prebuilt lunchpail executable, rather than as files embedded at
+// compile time. The layout is:
+//
+//	[executable][app.tar.gz][metadata json][len(app.tar.gz)][len(metadata)][trailerMagic]
+//
+// where the lengths are big-endian uint64s. A build compiled with Go
+// instead embeds [app.tar.gz][metadata json][lengths][trailerMagic].
+const trailerMagic = "LPTRAILR"
+
+const trailerFooterSize = 8 + 8 + len(trailerMagic)
+
+// The breadcrumbs of a build, as carried in a trailer
+type trailerMetadata struct {
+	Name       string          `json:"name"`
+	AppVersion string          `json:"appVersion"`
+	Date       string          `json:"date"`
+	By         string          `json:"by"`
+	On         string          `json:"on"`
+	Options    json.RawMessage `json:"options"`
+	TestData   string          `json:"testData,omitempty"`
+	SBOM       json.RawMessage `json:"sbom,omitempty"`
+}
+
+// Where in our executable the app template lives, if we carry a trailer
+var trailerApp *io.SectionReader
+
+func init() {
+	if len(embeddedTrailer) > 0 {
+		r := bytes.NewReader(embeddedTrailer)
+		if metadata, app, _, err := readTrailer(r, r.Size()); err == nil && app != nil {
+			adopt(metadata, app)
+		}
+	}
+
+	// An appended trailer takes precedence, as a build compiled
+	// with Go may itself serve as the prebuilt executable of
+	// another build
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+
+	f, err := os.Open(exe)
+	if err != nil {
+		return
+	}
+
+	// Note: we keep f open, as trailerApp reads from it
+	metadata, app, _, err := readTrailerOf(f)
+	if err != nil || app == nil {
+		f.Close()
+		return
+	}
+
+	adopt(metadata, app)
+}
+
+// Take on the identity of the build described by the given trailer
+func adopt(metadata trailerMetadata, app *io.SectionReader) {
+	name = metadata.Name
+	appVersion = metadata.AppVersion
+	date = metadata.Date
+	by = metadata.By
+	on = metadata.On
+	valuesJson = metadata.Options
+	testData = []byte(metadata.TestData)
+	sbomJson = metadata.SBOM
+	trailerApp = app
+}
+
+// Take on the identity of the given previously built binary, so that
+// from now on we stage, describe, and re-build its application rather
+// than our own
+func LoadFrom(binary string) error {
+	f, err := os.Open(binary)
+	if err != nil {
+		return err
+	}
+
+	// Note: we keep f open, as trailerApp reads from it
+	metadata, app, size, err := readTrailerOf(f)
+	if err == nil && app == nil {
+		metadata, app, err = findEmbeddedTrailer(f, size)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	} else if app == nil {
+		f.Close()
+		return fmt.Errorf("%s does not carry an application, so its application cannot be read. Is it a lunchpail build?", binary)
+	}
+
+	adopt(metadata, app)
+	return nil
+}
+
+// Read the trailer, if any, appended to the given executable. Also
+// returns the size of the executable without its trailer.
+func readTrailerOf(f *os.File) (metadata trailerMetadata, app *io.SectionReader, size int64, err error) {
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+
+	metadata, app, size, err = readTrailer(f, info.Size())
+	if err != nil {
+		err = fmt.Errorf("%s: %v", f.Name(), err)
+	}
+	return
+}
+
+// Read the trailer, if any, that ends the first size bytes of r. Also
+// returns the size of what precedes the trailer.
+func readTrailer(r io.ReaderAt, size int64) (metadata trailerMetadata, app *io.SectionReader, before int64, err error) {
+	before = size
+	if size < int64(trailerFooterSize) {
+		return
+	}
+
+	footer := make([]byte, trailerFooterSize)
+	if _, err = r.ReadAt(footer, size-int64(trailerFooterSize)); err != nil {
+		return
+	}
+	if string(footer[16:]) != trailerMagic {
+		return
+	}
+
+	appLen := int64(binary.BigEndian.Uint64(footer[0:8]))
+	metadataLen := int64(binary.BigEndian.Uint64(footer[8:16]))
+	metadataStart := size - int64(trailerFooterSize) - metadataLen
+	appStart := metadataStart - appLen
+	if appLen < 0 || metadataLen < 0 || metadataStart < 0 || appStart < 0 {
+		err = fmt.Errorf("Corrupt application trailer")
+		return
+	}
+
+	b := make([]byte, metadataLen)
+	if _, err = r.ReadAt(b, metadataStart); err != nil {
+		return
+	}
+	if err = json.Unmarshal(b, &metadata); err != nil {
+		return
+	}
+
+	app = io.NewSectionReader(r, appStart, appLen)
+	before = appStart
+	return
+}
+
+// Find the trailer that a build compiled with Go embeds somewhere in
+// the first size bytes of f, by looking for its magic, and checking
+// that what precedes it is a trailer. Our own trailerMagic constant,
+// for one, will not be.
+func findEmbeddedTrailer(f *os.File, size int64) (metadata trailerMetadata, app *io.SectionReader, err error) {
+	const chunkSize = 1 << 20
+	magic := []byte(trailerMagic)
+
+	buf := make([]byte, chunkSize+len(magic)-1)
+	for offset := int64(0); offset < size; offset += chunkSize {
+		n, rerr := f.ReadAt(buf[:min(int64(len(buf)), size-offset)], offset)
+		if rerr != nil && rerr != io.EOF {
+			err = rerr
+			return
+		}
+
+		for i := 0; i < min(n, chunkSize); {
+			idx := bytes.Index(buf[i:n], magic)
+			if idx < 0 || i+idx >= chunkSize {
+				break
+			}
+
+			end := offset + int64(i+idx+len(magic))
+			if m, a, _, err := readTrailer(f, end); err == nil && a != nil && m.Name != "" {
+				return m, a, nil
+			}
+			i += idx + 1
+		}
+	}
+
+	return
+}
+
+// Write to outputPath the given lunchpail executable (minus any
+// trailer it already carries), followed by a trailer holding the
+// application template and breadcrumbs dropped into stagedir
+func AppendTrailer(exe, stagedir, outputPath string) error {
+	in, err := os.Open(exe)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	_, _, size, err := readTrailerOf(in)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, io.NewSectionReader(in, 0, size)); err != nil {
+		return err
+	} else if err := writeTrailer(out, stagedir); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+// Write into the given lunchpail stage the trailer that a build
+// compiled from it will embed, as it cannot carry one appended
+func EmbedTrailer(stagedir string) error {
+	out, err := os.Create(filepath.Join(stagedir, embeddedTrailerPath))
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if err := writeTrailer(out, stagedir); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+// Write a trailer holding the application template and breadcrumbs
+// dropped into stagedir
+func writeTrailer(out io.Writer, stagedir string) error {
+	metadata, err := readBreadcrumbs(stagedir)
+	if err != nil {
+		return err
+	}
+	metadataJson, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	app, err := os.ReadFile(filepath.Join(stagedir, embededTemplatePath))
+	if err != nil {
+		return err
+	}
+
+	footer := make([]byte, 16, trailerFooterSize)
+	binary.BigEndian.PutUint64(footer[0:8], uint64(len(app)))
+	binary.BigEndian.PutUint64(footer[8:16], uint64(len(metadataJson)))
+	footer = append(footer, trailerMagic...)
+
+	for _, b := range [][]byte{app, metadataJson, footer} {
+		if _, err := out.Write(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Read back what DropBreadcrumbs wrote into stagedir
+func readBreadcrumbs(stagedir string) (metadata trailerMetadata, err error) {
+	read := func(file string) string {
+		if err != nil {
+			return ""
+		}
+		var b []byte
+		b, err = os.ReadFile(filepath.Join(stagedir, "pkg/build", file))
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return string(b)
+	}
+
+	metadata.Name = read("buildName.txt")
+	metadata.AppVersion = read("appVersion.txt")
+	metadata.Date = read("buildDate.txt")
+	metadata.By = read("builtBy.txt")
+	metadata.On = read("builtOn.txt")
+	if options := read("buildOptions.json"); options != "" {
+		metadata.Options = json.RawMessage(options)
+	}
+	metadata.TestData = read("testData.yaml")
+	if sbom := read("sbom.json"); sbom != "" {
+		metadata.SBOM = json.RawMessage(sbom)
+	}
+	return
+}
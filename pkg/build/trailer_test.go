@@ -0,0 +1,178 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFromWithoutTrailer(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "app")
+	if err := os.WriteFile(binary, []byte(strings.Repeat("not a trailer", 10)), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := LoadFrom(binary)
+	if err == nil {
+		t.Fatal("expected an error")
+	} else if !strings.Contains(err.Error(), "does not carry an application") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// Restore our identity once the test is done with LoadFrom
+func restoreIdentity(t *testing.T) {
+	n, v, d, b, o, opts, td, sbom, app := name, appVersion, date, by, on, valuesJson, testData, sbomJson, trailerApp
+	t.Cleanup(func() {
+		name, appVersion, date, by, on, valuesJson, testData, sbomJson, trailerApp = n, v, d, b, o, opts, td, sbom, app
+	})
+}
+
+// A lunchpail stage for an application with the given name, whose
+// template holds a single main.sh
+func stageApp(t *testing.T, appName string) string {
+	t.Helper()
+
+	stagedir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(stagedir, "pkg/build"), 0755); err != nil {
+		t.Fatal(err)
+	} else if err := DropBreadcrumbs(appName, "1.0.0", nil, Options{}, true, stagedir); err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gz)
+	content := []byte("#!/bin/sh\necho hello\n")
+	if err := tw.WriteHeader(&tar.Header{Name: "main.sh", Mode: 0755, Size: int64(len(content))}); err != nil {
+		t.Fatal(err)
+	} else if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	} else if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	} else if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(filepath.Join(stagedir, embededTemplatePath), b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return stagedir
+}
+
+// Check that we have taken on the identity of the application staged by stageApp
+func expectApp(t *testing.T, appName string) {
+	t.Helper()
+
+	if Name() != appName || AppVersion() != "1.0.0" {
+		t.Fatalf("expected to be %s 1.0.0, got %s %s", appName, Name(), AppVersion())
+	}
+
+	templatePath := t.TempDir()
+	if err := expandAppTemplate(templatePath); err != nil {
+		t.Fatal(err)
+	} else if b, err := os.ReadFile(filepath.Join(templatePath, "main.sh")); err != nil || !strings.Contains(string(b), "echo hello") {
+		t.Fatalf("expected the application template, got %q (error %v)", b, err)
+	}
+}
+
+func TestLoadFromAppendedTrailer(t *testing.T) {
+	restoreIdentity(t)
+
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "lunchpail")
+	if err := os.WriteFile(exe, []byte(strings.Repeat("an executable", 1000)), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	binary := filepath.Join(dir, "app")
+	if err := AppendTrailer(exe, stageApp(t, "appended"), binary); err != nil {
+		t.Fatal(err)
+	} else if err := LoadFrom(binary); err != nil {
+		t.Fatal(err)
+	}
+	expectApp(t, "appended")
+}
+
+// A binary compiled with Go carries the trailer somewhere in its
+// middle, surrounded by code and other data, including (as with any
+// lunchpail) our own trailerMagic
+func TestLoadFromEmbeddedTrailer(t *testing.T) {
+	restoreIdentity(t)
+
+	stagedir := stageApp(t, "embedded")
+	if err := EmbedTrailer(stagedir); err != nil {
+		t.Fatal(err)
+	}
+	trailer, err := os.ReadFile(filepath.Join(stagedir, embeddedTrailerPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Straddle the boundary of the chunks in which we look for it
+	var b bytes.Buffer
+	b.WriteString("code code " + trailerMagic + " more code")
+	b.Write(bytes.Repeat([]byte{0xff}, 1<<20-b.Len()-len(trailer)+3))
+	b.Write(trailer)
+	b.WriteString("rodata rodata")
+
+	binary := filepath.Join(t.TempDir(), "app")
+	if err := os.WriteFile(binary, b.Bytes(), 0755); err != nil {
+		t.Fatal(err)
+	} else if err := LoadFrom(binary); err != nil {
+		t.Fatal(err)
+	}
+	expectApp(t, "embedded")
+}
+
+// As `build --toolchain` does, and as builds for darwin do, compile
+// a binary that embeds a trailer
+func TestLoadFromToolchainBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles with Go")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("needs the Go toolchain")
+	}
+	restoreIdentity(t)
+
+	stagedir := stageApp(t, "compiled")
+	if err := EmbedTrailer(stagedir); err != nil {
+		t.Fatal(err)
+	}
+
+	src := t.TempDir()
+	for file, content := range map[string]string{
+		"go.mod":  "module compiled\n\ngo 1.23\n",
+		"main.go": "package main\n\nimport _ \"embed\"\n\n//go:embed trailer.bin\nvar trailer []byte\n\nfunc main() { println(\"" + trailerMagic + "\", len(trailer)) }\n",
+	} {
+		if err := os.WriteFile(filepath.Join(src, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Link(filepath.Join(stagedir, embeddedTrailerPath), filepath.Join(src, "trailer.bin")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, goos := range []string{"linux", "darwin"} {
+		t.Run(goos, func(t *testing.T) {
+			binary := filepath.Join(t.TempDir(), "app")
+			cmd := exec.Command(gobin, "build", "-ldflags", "-s -w", "-o", binary, ".")
+			cmd.Dir = src
+			cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH=arm64", "CGO_ENABLED=0")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("%v\n%s", err, out)
+			}
+
+			if err := LoadFrom(binary); err != nil {
+				t.Fatal(err)
+			}
+			expectApp(t, "compiled")
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package build
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// An application may carry, for each set of Python requirements, the
+// wheels that satisfy them on each platform it was built for, so that
+// its workers can install them without reaching a package index. The
+// layout within the app template is:
+//
+//	wheelhouse/<sha256 of requirements>/<os>-<arch>/*.whl
+const wheelhouseDir = "wheelhouse"
+
+func WheelhouseDirFor(templatePath string) string {
+	return filepath.Join(templatePath, wheelhouseDir)
+}
+
+// Where in the given app template the wheels for the given
+// requirements and platform live
+func WheelhouseFor(templatePath string, requirements []byte, goos, goarch string) string {
+	return filepath.Join(WheelhouseDirFor(templatePath), WheelhouseKey(requirements, goos, goarch))
+}
+
+// The path, relative to a wheelhouse, of the wheels for the given
+// requirements and platform
+func WheelhouseKey(requirements []byte, goos, goarch string) string {
+	return filepath.Join(fmt.Sprintf("%x", sha256.Sum256(requirements)), goos+"-"+goarch)
+}
+
+// Stage this application, and return where in that stage its
+// wheelhouse lives, if it carries one
+func WheelhouseDirWithStage() (wheelhouse string, stagePath string, err error) {
+	if !IsBuilt() {
+		return
+	}
+
+	stagePath, err = StageForBuilder(StageOptions{})
+	if err != nil {
+		return
+	}
+
+	if dir := WheelhouseDirFor(stagePath); exists(dir) {
+		wheelhouse = dir
+	}
+	return
+}
+
+// Stage this application, and return where in that stage the wheels
+// for the given requirements and platform live, if we carry them
+func WheelhouseWithStage(requirements []byte, goos, goarch string) (wheelhouse string, stagePath string, err error) {
+	if !IsBuilt() {
+		return
+	}
+
+	stagePath, err = StageForBuilder(StageOptions{})
+	if err != nil {
+		return
+	}
+
+	if dir := WheelhouseFor(stagePath, requirements, goos, goarch); exists(dir) {
+		wheelhouse = dir
+	} else if exists(filepath.Dir(dir)) {
+		err = fmt.Errorf("This application carries Python wheels for its requirements, but not for %s/%s. Re-build with --python-platform %s/%s", goos, goarch, goos, goarch)
+	}
+	return
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -7,6 +7,8 @@ import (
 	"io/ioutil"
 	"os"
 
+	"golang.org/x/sync/errgroup"
+
 	"lunchpail.io/pkg/build"
 	"lunchpail.io/pkg/fe/builder/overlay"
 	"lunchpail.io/pkg/observe/colors"
@@ -37,8 +39,20 @@ func Build(ctx context.Context, sourcePath string, opts Options) error {
 		return fmt.Errorf("Output path already exists and is a directory: %s", opts.Name)
 	}
 
-	// First, copy out lunchpail itself
-	lunchpailStageDir, err := stageLunchpailItself()
+	// Check the signing key now, rather than after a long build
+	if opts.SigningKey != "" {
+		if err := build.CheckSigningKey(opts.SigningKey, opts.GenerateSigningKey); err != nil {
+			return err
+		}
+	}
+
+	// First, copy out lunchpail itself, or if we will not be
+	// compiling, just a place for the build to tell about itself
+	stage := stageForTrailer
+	if needsToolchain(opts) {
+		stage = stageLunchpailItself
+	}
+	lunchpailStageDir, err := stage()
 	if err != nil {
 		return err
 	} else if opts.Verbose() {
@@ -59,18 +73,85 @@ func Build(ctx context.Context, sourcePath string, opts Options) error {
 	// Fourth, copy that overlay into the lunchpail stage (TODO:
 	// why do we need two stages? cannot overlay.Overlay... copy
 	// directly into the lunchpail stage??)
-	if err := build.MoveAppTemplateIntoLunchpailStage(lunchpailStageDir, appTemplatePath, opts.Verbose()); err != nil {
+	if err := build.MoveAppTemplateIntoLunchpailStage(lunchpailStageDir, appTemplatePath, opts.Reproducible, opts.Verbose()); err != nil {
 		return err
 	}
 
-	// Fifth, tell the build about itself (its name, version)
-	if err := build.DropBreadcrumbs(buildName, appVersion, hasTestData, opts.OverlayOptions.BuildOptions, lunchpailStageDir); err != nil {
+	// Fourth and a half, if asked, show what changed and bundle
+	// Python wheels. Both need the application as it will be run.
+	if opts.ShowDiff || opts.OfflinePython {
+		ir, err := builtHLIR(buildName, lunchpailStageDir, opts)
+		if err != nil {
+			return err
+		}
+
+		if opts.ShowDiff {
+			if err := showDiff(buildName, ir); err != nil {
+				return err
+			}
+		}
+
+		if opts.OfflinePython {
+			if err := bundleWheelhouse(ir, appTemplatePath, opts); err != nil {
+				return err
+			} else if err := build.MoveAppTemplateIntoLunchpailStage(lunchpailStageDir, appTemplatePath, opts.Reproducible, opts.Verbose()); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Fifth, tell the build about itself (its name, version, contents)
+	if err := build.DropBreadcrumbs(buildName, appVersion, hasTestData, opts.OverlayOptions.BuildOptions, opts.Reproducible, lunchpailStageDir); err != nil {
+		return err
+	} else if err := build.DropBillOfMaterials(appTemplatePath, lunchpailStageDir); err != nil {
 		return err
 	}
 
-	// Finally, emit a new binary
-	if opts.AllPlatforms {
-		return emitAll(ctx, lunchpailStageDir, opts.Name)
+	// Fifth and a half, binaries we compile carry all of that
+	// embedded, rather than appended
+	if needsToolchain(opts) {
+		if err := build.EmbedTrailer(lunchpailStageDir); err != nil {
+			return err
+		}
 	}
-	return emit(lunchpailStageDir, opts.Name, "", "")
+
+	// Sixth, emit a new binary
+	if err := emitBinaries(ctx, lunchpailStageDir, opts); err != nil {
+		return err
+	}
+
+	// Finally, sign it, if asked
+	if opts.SigningKey != "" {
+		return sign(opts)
+	}
+
+	return nil
+}
+
+// Emit a binary for each target, appending the application to a
+// prebuilt lunchpail executable where we have one, and otherwise
+// compiling with the Go toolchain
+func emitBinaries(ctx context.Context, lunchpailStageDir string, opts Options) error {
+	group, _ := errgroup.WithContext(ctx)
+
+	for _, t := range targets(opts) {
+		name := opts.Name
+		if opts.AllPlatforms {
+			name = opts.Name + "-" + t.os + "-" + t.arch
+		}
+
+		if exe, ok := prebuiltFor(t, opts); ok {
+			if err := build.AppendTrailer(exe, lunchpailStageDir, name); err != nil {
+				return err
+			}
+		} else if !opts.AllPlatforms {
+			return emit(lunchpailStageDir, opts.Name, "", "", opts.Reproducible)
+		} else {
+			group.Go(func() error {
+				return emit(lunchpailStageDir, opts.Name, t.os, t.arch, opts.Reproducible)
+			})
+		}
+	}
+
+	return group.Wait()
 }
@@ -0,0 +1,46 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/fe"
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/observe/colors"
+	"lunchpail.io/pkg/observe/info"
+)
+
+// The HLIR of the application staged in lunchpailStageDir, as a run
+// of the build we are about to emit would see it
+func builtHLIR(buildName, lunchpailStageDir string, opts Options) (hlir.HLIR, error) {
+	templatePath, err := build.StageBuildForRun(lunchpailStageDir, build.StageOptions{Verbose: opts.Verbose()})
+	if err != nil {
+		return hlir.HLIR{}, err
+	}
+
+	return fe.PrepareHLIRFromTemplate(buildName, templatePath, opts.OverlayOptions.BuildOptions)
+}
+
+// Show how the given application differs from that of the prior build
+// we overlaid it onto
+func showDiff(buildName string, after hlir.HLIR) error {
+	priorOpts, err := build.RestoreOptions()
+	if err != nil {
+		return err
+	}
+
+	before, err := fe.PrepareHLIR(priorOpts)
+	if err != nil {
+		return err
+	}
+
+	changes, err := hlir.Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%s Changes from %s\n", colors.Yellow.Render(buildName), build.Name())
+	info.PrintDiff(os.Stderr, changes)
+	return nil
+}
@@ -1,12 +1,12 @@
 package builder
 
 import (
-	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 
-	"golang.org/x/sync/errgroup"
+	"lunchpail.io/pkg/build"
 )
 
 func goget(dir string) error {
@@ -22,9 +22,18 @@ func goget(dir string) error {
 	return nil
 }
 
-func gogenerate(dir string) error {
+func gogenerate(dir string, reproducible bool) error {
 	cmd := exec.Command("go", "generate", "./...")
 	cmd.Dir = dir
+	if reproducible {
+		// The generators use tar, so that the embedded tarballs
+		// are reproducible, too (this needs GNU tar)
+		mtime, err := build.ReproducibleTime()
+		if err != nil {
+			return err
+		}
+		cmd.Env = append(os.Environ(), fmt.Sprintf("TAR_OPTIONS=--sort=name --mtime=@%d --owner=0 --group=0 --numeric-owner", mtime.Unix()))
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -35,7 +44,7 @@ func gogenerate(dir string) error {
 	return nil
 }
 
-func gobuild(dir, name, targetOs, targetArch string) error {
+func gobuild(dir, name, targetOs, targetArch string, reproducible bool) error {
 	absName, err := filepath.Abs(name)
 	if err != nil {
 		return err
@@ -46,7 +55,13 @@ func gobuild(dir, name, targetOs, targetArch string) error {
 		targetName = absName + "-" + targetOs + "-" + targetArch
 	}
 
-	cmd := exec.Command("go", "build", "-tags", "full", "-ldflags", "-s -w", "-o", targetName, "cmd/main.go")
+	args := []string{"build", "-tags", "full", "-ldflags", "-s -w", "-o", targetName}
+	if reproducible {
+		// Keep the (temporary) stage directory out of the binary
+		args = append(args, "-trimpath", "-buildvcs=false")
+	}
+
+	cmd := exec.Command("go", append(args, "cmd/main.go")...)
 	cmd.Env = os.Environ()
 	cmd.Env = append(cmd.Env, "CGO_ENABLED=0")
 	cmd.Env = append(cmd.Env, "GOOS="+targetOs)
@@ -64,33 +79,18 @@ func gobuild(dir, name, targetOs, targetArch string) error {
 }
 
 // Emit application binary for the given os and arch
-func emit(dir, name, targetOs, targetArch string) error {
+func emit(dir, name, targetOs, targetArch string, reproducible bool) error {
 	if err := goget(dir); err != nil {
 		return err
 	}
 
-	if err := gogenerate(dir); err != nil {
+	if err := gogenerate(dir, reproducible); err != nil {
 		return err
 	}
 
-	if err := gobuild(dir, name, targetOs, targetArch); err != nil {
+	if err := gobuild(dir, name, targetOs, targetArch, reproducible); err != nil {
 		return err
 	}
 
 	return nil
 }
-
-// emit() but for all supported os and arch
-func emitAll(ctx context.Context, dir, name string) error {
-	group, _ := errgroup.WithContext(ctx)
-
-	for _, targetOs := range supportedOs() {
-		for _, targetArch := range supportedArch() {
-			group.Go(func() error {
-				return emit(dir, name, targetOs, targetArch)
-			})
-		}
-	}
-
-	return group.Wait()
-}
@@ -3,8 +3,38 @@ package builder
 import "lunchpail.io/pkg/fe/builder/overlay"
 
 type Options struct {
-	Name           string
-	AllPlatforms   bool
+	Name         string
+	AllPlatforms bool
+
+	// Compile with the Go toolchain, rather than appending the
+	// application to a prebuilt lunchpail executable
+	Toolchain bool
+
+	// Where to find prebuilt lunchpail-<os>-<arch> executables for
+	// platforms other than our own
+	PrebuiltDir string
+
+	// Produce the same binary given the same inputs
+	Reproducible bool
+
+	// Sign the binary with the ed25519 private key in this file
+	SigningKey string
+
+	// Generate the SigningKey, if it does not exist
+	GenerateSigningKey bool
+
+	// Before emitting, show how the application differs from that
+	// of the prior build we are overlaying onto
+	ShowDiff bool
+
+	// Bundle wheels for the application's Python requirements, so
+	// that workers install them without reaching a package index
+	OfflinePython bool
+
+	// The os/arch of the workers that will install those wheels; by
+	// default, the platforms we emit binaries for
+	PythonPlatforms []string
+
 	OverlayOptions overlay.Options
 }
 
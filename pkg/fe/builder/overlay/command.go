@@ -8,6 +8,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/hlir/v1beta1"
 )
 
 // Support for build --command
@@ -48,7 +49,7 @@ func writeCommandAppToTemplateDir(ir hlir.HLIR, dir string, verbose bool) error
 		return err
 	}
 	for _, app := range ir.Applications {
-		yaml, err := yaml.Marshal(app)
+		yaml, err := yaml.Marshal(v1beta1.ApplicationFromV1alpha1(app))
 		if err != nil {
 			return err
 		}
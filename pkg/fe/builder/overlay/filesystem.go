@@ -11,12 +11,14 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/dustin/go-humanize/english"
 	"gopkg.in/yaml.v3"
 
 	"lunchpail.io/pkg/build"
 	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/hlir/v1beta1"
 	"lunchpail.io/pkg/observe/colors"
 )
 
@@ -38,8 +40,9 @@ func copyFilesystemIntoTemplate(appname, sourcePath, templatePath string, opts O
 		return
 	}
 
+	// Emit the latest apiVersion
 	var b []byte
-	b, err = yaml.Marshal(app)
+	b, err = yaml.Marshal(v1beta1.ApplicationFromV1alpha1(app))
 	if err != nil {
 		return
 	}
@@ -136,8 +139,8 @@ func (b filesystemBuilder) addBlobs(spec *hlir.Spec, blobsPrefix, encoding strin
 
 // Handle src/ artifacts
 func (b filesystemBuilder) addCode(spec *hlir.Spec, sourcePath string) (err error) {
-	maybeCommand := ""
-	maybeImage := ""
+	var files []fs.DirEntry
+	sources := make(map[string]string)
 
 	srcPrefix := filepath.Join(sourcePath, "src")
 	if _, err = os.Stat(srcPrefix); err == nil {
@@ -151,29 +154,45 @@ func (b filesystemBuilder) addCode(spec *hlir.Spec, sourcePath string) (err erro
 				fmt.Fprintln(os.Stderr, "Incorporating source file", path)
 			}
 
-			source, err := b.readString(path)
+			raw, err := os.ReadFile(path)
 			if err != nil {
 				return err
 			}
+			source := string(raw)
+			if utf8.Valid(raw) {
+				// Binaries, e.g. jars, must be carried verbatim
+				source = strings.TrimSpace(source)
+			}
 			spec.Code = append(spec.Code, hlir.Code{Name: d.Name(), Source: source})
 
-			switch d.Name() {
-			case "main.sh":
-				maybeCommand = "./main.sh"
-				maybeImage = "docker.io/alpine:3"
-			case "main.py":
-				maybeCommand = "python3 main.py"
-				maybeImage = "docker.io/python:3.12"
-			}
+			files = append(files, d)
+			sources[d.Name()] = source
 			return nil
 		})
+		if err != nil {
+			return
+		}
+	}
+
+	lang, name, ok := recognize(files)
+	if !ok {
+		return
+	}
+
+	if name == "main.go" {
+		var compiled []hlir.Code
+		if compiled, err = b.compileGo(srcPrefix); err != nil {
+			return
+		}
+		spec.Code = append(spec.Code, compiled...)
 	}
 
-	if spec.Command == "" && maybeCommand != "" {
-		spec.Command = maybeCommand
+	if spec.Command == "" {
+		spec.Command = lang.command(name, sources[name])
+		spec.Needs = append(spec.Needs, lang.needsFor(sources[name])...)
 	}
-	if spec.Image == "" && maybeImage != "" {
-		spec.Image = maybeImage
+	if spec.Image == "" {
+		spec.Image = lang.image
 	}
 
 	return
@@ -182,12 +201,26 @@ func (b filesystemBuilder) addCode(spec *hlir.Spec, sourcePath string) (err erro
 // Handle top-level metadata files
 func (b filesystemBuilder) addMetadata(spec *hlir.Spec, sourcePath string) (appVersion string, err error) {
 	var topLevelFiles []fs.DirEntry
+	var lockfiles []string
 	if topLevelFiles, err = os.ReadDir(sourcePath); err == nil {
 		for _, d := range topLevelFiles {
 			path := filepath.Join(sourcePath, d.Name())
 			switch d.Name() {
+			case "requirements.lock", "uv.lock", "poetry.lock":
+				// Handled below, as these take precedence
+				// over requirements.txt
+				lockfiles = append(lockfiles, d.Name())
+			case "environment.yml", "environment.yaml":
+				if env, rerr := b.readString(path); rerr != nil {
+					err = rerr
+					return
+				} else {
+					spec.Needs = append(spec.Needs, hlir.Needs{Name: "conda", Version: "latest", Requirements: env})
+				}
 			case "version", "version.txt":
-				if appVersion, err = handleVersionFile(path); err != nil {
+				// Note: not handleVersionFile(), which removes
+				// the file, and this is the user's source
+				if appVersion, err = b.readString(path); err != nil {
 					return
 				}
 			case "requirements.txt":
@@ -210,6 +243,14 @@ func (b filesystemBuilder) addMetadata(spec *hlir.Spec, sourcePath string) (appV
 						spec.Needs = append(spec.Needs, hlir.Needs{Name: "python", Version: "latest", Requirements: req})
 					}
 				}
+			case "calling-convention":
+				if cc, rerr := b.readString(path); rerr != nil {
+					err = rerr
+					return
+				} else if rerr := spec.CallingConvention.Set(cc); rerr != nil {
+					err = rerr
+					return
+				}
 			case "memory", "memory.txt":
 				if mem, rerr := b.readString(path); rerr != nil {
 					err = rerr
@@ -247,6 +288,22 @@ func (b filesystemBuilder) addMetadata(spec *hlir.Spec, sourcePath string) (appV
 		}
 	}
 
+	switch {
+	case len(lockfiles) > 1:
+		err = fmt.Errorf("Found more than one Python lockfile (%s); please keep only one", strings.Join(lockfiles, ", "))
+	case len(lockfiles) == 1:
+		var req string
+		if req, err = requirementsFromLockfile(filepath.Join(sourcePath, lockfiles[0])); err != nil {
+			return
+		} else if b.verbose {
+			fmt.Fprintln(os.Stderr, "Using Python requirements pinned by", lockfiles[0])
+		}
+
+		// The lockfile replaces any requirements.txt
+		spec.Needs = slices.DeleteFunc(spec.Needs, func(needs hlir.Needs) bool { return needs.Name == "python" })
+		spec.Needs = append(spec.Needs, hlir.Needs{Name: "python", Version: "latest", Requirements: req})
+	}
+
 	return
 }
 
@@ -260,6 +317,11 @@ func (b filesystemBuilder) addTestData(spec *hlir.Spec, sourcePath, templatePath
 	expectedDir := filepath.Join(testDataDir, filepath.Base(templateTestDataDirForExpected))
 
 	if d, err := os.Stat(testDataDir); err == nil && d.IsDir() {
+		// When re-building, the template already holds the test
+		// data of the prior build, which this source replaces
+		if err := os.RemoveAll(templateTestDataDir); err != nil {
+			return err
+		}
 		if err := os.CopyFS(templateTestDataDir, os.DirFS(testDataDir)); err != nil {
 			return err
 		}
@@ -268,32 +330,7 @@ func (b filesystemBuilder) addTestData(spec *hlir.Spec, sourcePath, templatePath
 	if inputs, err := os.ReadDir(inputDir); err == nil {
 		for _, input := range inputs {
 			if !input.IsDir() {
-				test := hlir.TestDatum{Name: input.Name(), Input: input.Name()}
-
-				output := filepath.Join(expectedDir, input.Name())
-				if _, err := os.Stat(output); err != nil {
-					// Hmm, check if it exists with a .gz extension
-					output = filepath.Join(expectedDir, input.Name()+".gz")
-					if _, err := os.Stat(output); err != nil {
-						// Hmm, check if it exists with _0, _1, ... extensions
-						idx := strings.Index(input.Name(), ".")
-						if idx >= 0 {
-							outputNum := 0
-							for {
-								output = filepath.Join(expectedDir, input.Name()[:idx]+"_"+strconv.Itoa(outputNum)+input.Name()[idx:])
-								if _, err := os.Stat(output); err != nil {
-									break
-								}
-								test.Expected = append(test.Expected, filepath.Base(output))
-								outputNum++
-							}
-						}
-					} else {
-						test.Expected = []string{filepath.Base(output)}
-					}
-				} else {
-					test.Expected = []string{filepath.Base(output)}
-				}
+				test := hlir.TestDatum{Name: input.Name(), Input: input.Name(), Expected: expectedOutputs(expectedDir, input.Name())}
 
 				if len(test.Expected) == 0 {
 					// Then the application does not provided expected output
@@ -312,6 +349,38 @@ func (b filesystemBuilder) addTestData(spec *hlir.Spec, sourcePath, templatePath
 	return nil
 }
 
+// The expected outputs for the given test input: either of the same
+// name, or with a .gz extension, or with _0, _1, ... extensions
+func expectedOutputs(expectedDir, input string) []string {
+	output := filepath.Join(expectedDir, input)
+	if _, err := os.Stat(output); err == nil {
+		return []string{filepath.Base(output)}
+	}
+
+	// Hmm, check if it exists with a .gz extension
+	output = filepath.Join(expectedDir, input+".gz")
+	if _, err := os.Stat(output); err == nil {
+		return []string{filepath.Base(output)}
+	}
+
+	// Hmm, check if it exists with _0, _1, ... extensions
+	var expected []string
+	idx := strings.Index(input, ".")
+	if idx >= 0 {
+		outputNum := 0
+		for {
+			output = filepath.Join(expectedDir, input[:idx]+"_"+strconv.Itoa(outputNum)+input[idx:])
+			if _, err := os.Stat(output); err != nil {
+				break
+			}
+			expected = append(expected, filepath.Base(output))
+			outputNum++
+		}
+	}
+
+	return expected
+}
+
 // If we now know the specific python version needed (e.g. because of
 // a given command or image file), we can update the Needs spec. TODO:
 // handle version from image.
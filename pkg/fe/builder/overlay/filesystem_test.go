@@ -0,0 +1,64 @@
+package overlay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lunchpail.io/pkg/build"
+)
+
+func writeSource(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// As build --from does, overlay a source with test-data onto a
+// template that already holds the test data of a prior build
+func TestRebuildWithTestData(t *testing.T) {
+	opts := Options{BuildOptions: build.Options{Log: &build.LogOptions{}}}
+	templatePath := t.TempDir()
+	if err := os.MkdirAll(appdir(templatePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	prior := t.TempDir()
+	writeSource(t, prior, map[string]string{
+		"src/main.sh":                  "cat $1 > $2",
+		"test-data/input/hello.txt":    "hello",
+		"test-data/expected/hello.txt": "hello",
+		"test-data/input/stale.txt":    "stale",
+	})
+	if _, _, err := copyFilesystemIntoTemplate("demo", prior, templatePath, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	source := t.TempDir()
+	writeSource(t, source, map[string]string{
+		"src/main.sh":                  "cat $1 > $2",
+		"test-data/input/hello.txt":    "hello",
+		"test-data/expected/hello.txt": "hello world",
+	})
+	_, testData, err := copyFilesystemIntoTemplate("demo", source, templatePath, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(testData) != 1 || testData[0].Name != "hello.txt" {
+		t.Errorf("expected the test data of the new source, got %v", testData)
+	}
+	if b, err := os.ReadFile(filepath.Join(build.TestDataDirForExpected(templatePath), "hello.txt")); err != nil || string(b) != "hello world" {
+		t.Errorf("expected the new expected output, got %q (error %v)", b, err)
+	}
+	if _, err := os.Stat(filepath.Join(build.TestDataDirForInput(templatePath), "stale.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected the prior build's test data to be replaced, got error %v", err)
+	}
+}
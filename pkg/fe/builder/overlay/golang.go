@@ -0,0 +1,87 @@
+package overlay
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+// Picks the executable for the platform we find ourselves on
+const goLauncher = `#!/bin/sh
+os=$(uname -s | tr '[:upper:]' '[:lower:]')
+case $(uname -m) in
+  x86_64) arch=amd64 ;;
+  aarch64|arm64) arch=arm64 ;;
+  *) arch=$(uname -m) ;;
+esac
+exec "$(dirname "$0")/main-$os-$arch" "$@"`
+
+// Compile the Go application in srcDir to static executables for the
+// platforms an application may run on, along with a ./main that
+// launches the right one
+func (b filesystemBuilder) compileGo(srcDir string) ([]hlir.Code, error) {
+	if _, err := exec.LookPath("go"); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("Building an application from main.go requires the Go toolchain")
+		}
+		return nil, err
+	}
+
+	// Without a go.mod, we can only build the files themselves
+	packages := []string{"."}
+	if _, err := os.Stat(filepath.Join(srcDir, "go.mod")); err != nil {
+		files, err := filepath.Glob(filepath.Join(srcDir, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		packages = packages[:0]
+		for _, file := range files {
+			if !strings.HasSuffix(file, "_test.go") {
+				packages = append(packages, filepath.Base(file))
+			}
+		}
+	}
+
+	outdir, err := os.MkdirTemp("", "lunchpail-go")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(outdir)
+
+	platforms := [][2]string{{"linux", "amd64"}, {"linux", "arm64"}}
+	if runtime.GOOS != "linux" {
+		// So that the application may also run locally
+		platforms = append(platforms, [2]string{runtime.GOOS, runtime.GOARCH})
+	}
+
+	code := []hlir.Code{{Name: "main", Source: goLauncher}}
+	for _, platform := range platforms {
+		name := "main-" + platform[0] + "-" + platform[1]
+		if b.verbose {
+			fmt.Fprintln(os.Stderr, "Compiling Go application", name)
+		}
+
+		cmd := exec.Command("go", append([]string{"build", "-trimpath", "-ldflags", "-s -w", "-o", filepath.Join(outdir, name)}, packages...)...)
+		cmd.Dir = srcDir
+		cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS="+platform[0], "GOARCH="+platform[1])
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("Error compiling Go application for %s/%s: %v", platform[0], platform[1], err)
+		}
+
+		exe, err := os.ReadFile(filepath.Join(outdir, name))
+		if err != nil {
+			return nil, err
+		}
+		code = append(code, hlir.Code{Name: name, Source: string(exe)})
+	}
+
+	return code, nil
+}
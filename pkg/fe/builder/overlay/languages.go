@@ -0,0 +1,131 @@
+package overlay
+
+import (
+	"encoding/json"
+	"io/fs"
+	"strings"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+// How to run an application, as inferred from one of its source files
+type language struct {
+	// Does this source file identify the language?
+	recognizes func(d fs.DirEntry) bool
+
+	// Given the identifying source file, how to run the application
+	command func(name, source string) string
+
+	// The image to run in, if not otherwise specified
+	image string
+
+	// The installer that can provide the language runtime locally,
+	// if any. The requirements are the identifying source file.
+	needs        string
+	needsVersion string
+	requirements bool
+}
+
+func named(name string) func(d fs.DirEntry) bool {
+	return func(d fs.DirEntry) bool { return d.Name() == name }
+}
+
+func always(command string) func(name, source string) string {
+	return func(_, _ string) string { return command }
+}
+
+// In order of precedence, should a source tree contain more than one
+var languages = []language{
+	{
+		recognizes: named("main.sh"),
+		command:    always("./main.sh"),
+		image:      "docker.io/alpine:3",
+	},
+	{
+		recognizes: named("main.py"),
+		command:    always("python3 main.py"),
+		image:      "docker.io/python:3.12",
+	},
+	{
+		recognizes:   named("package.json"),
+		command:      nodePackageCommand,
+		image:        "docker.io/node:22",
+		needs:        "node",
+		needsVersion: "22",
+		requirements: true,
+	},
+	{
+		recognizes:   named("main.js"),
+		command:      always("node main.js"),
+		image:        "docker.io/node:22",
+		needs:        "node",
+		needsVersion: "22",
+	},
+	{
+		// See compileGo() for how main.go becomes ./main
+		recognizes: named("main.go"),
+		command:    always("./main"),
+		image:      "docker.io/alpine:3",
+	},
+	{
+		recognizes: func(d fs.DirEntry) bool { return d.Name() == "main.R" || d.Name() == "main.r" },
+		command:    func(name, _ string) string { return "Rscript " + name },
+		image:      "docker.io/r-base:4.4.1",
+		needs:      "r",
+	},
+	{
+		recognizes:   func(d fs.DirEntry) bool { return strings.HasSuffix(d.Name(), ".jar") },
+		command:      func(name, _ string) string { return "java -jar " + name },
+		image:        "docker.io/eclipse-temurin:21-jre",
+		needs:        "java",
+		needsVersion: "21",
+	},
+	{
+		// Any other executable named main, e.g. a prebuilt binary
+		recognizes: func(d fs.DirEntry) bool {
+			info, err := d.Info()
+			return err == nil && d.Name() == "main" && info.Mode().Perm()&0111 != 0
+		},
+		command: always("./main"),
+		image:   "docker.io/debian:stable-slim",
+	},
+}
+
+// Use the "main" of the package.json if it has one, otherwise let
+// npm figure it out
+func nodePackageCommand(_, source string) string {
+	var pkg struct{ Main string }
+	if err := json.Unmarshal([]byte(source), &pkg); err == nil && pkg.Main != "" {
+		return "node " + pkg.Main
+	}
+	return "npm start"
+}
+
+// The first language that recognizes one of the given source files
+func recognize(files []fs.DirEntry) (language, string, bool) {
+	for _, lang := range languages {
+		for _, d := range files {
+			if lang.recognizes(d) {
+				return lang, d.Name(), true
+			}
+		}
+	}
+	return language{}, "", false
+}
+
+// What this language needs installed, to run the application locally
+func (lang language) needsFor(source string) []hlir.Needs {
+	if lang.needs == "" {
+		return nil
+	}
+
+	needs := hlir.Needs{Name: lang.needs, Version: lang.needsVersion}
+	if needs.Version == "" {
+		needs.Version = "latest"
+	}
+	if lang.requirements {
+		needs.Requirements = source
+	}
+
+	return []hlir.Needs{needs}
+}
@@ -0,0 +1,311 @@
+package overlay
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"gopkg.in/yaml.v3"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+// Something amiss with an application source directory
+type Finding struct {
+	// Relative to the source directory
+	Path    string
+	Message string
+
+	// Otherwise, the build would go ahead, just perhaps not as
+	// the author intended
+	IsError bool
+}
+
+func (f Finding) String() string {
+	level := "warning"
+	if f.IsError {
+		level = "error"
+	}
+	return fmt.Sprintf("%s: %s: %s", f.Path, level, f.Message)
+}
+
+// The top-level files and directories that scan() understands. Keep
+// this in sync with addMetadata() and scan().
+var topLevelFiles = []string{
+	"blobs",
+	"calling-convention",
+	"command",
+	"env.yaml",
+	"environment.yaml",
+	"environment.yml",
+	"image",
+	"memory",
+	"memory.txt",
+	"poetry.lock",
+	"requirements.lock",
+	"requirements.txt",
+	"requirements_linux_ci.txt",
+	"src",
+	"test-data",
+	"uv.lock",
+	"version",
+	"version.txt",
+}
+
+// Common names for things that scan() knows by another name
+var aliases = map[string]string{
+	"source":  "src",
+	"sources": "src",
+	"code":    "src",
+	"test":    "test-data",
+	"tests":   "test-data",
+	"data":    "blobs",
+}
+
+// Files that scan() ignores, but which are not worth mentioning
+var ignoredFiles = []string{"README.md", "LICENSE", ".gitignore", ".git", "pyproject.toml"}
+
+// Check the given application source directory against the
+// conventions of scan()
+func Lint(sourcePath string) ([]Finding, error) {
+	entries, err := os.ReadDir(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	l := linter{sourcePath: sourcePath}
+	hasCommand := false
+	for _, d := range entries {
+		name := d.Name()
+		switch {
+		case slices.Contains(ignoredFiles, name):
+		case !slices.Contains(topLevelFiles, name):
+			l.unrecognized(name, topLevelFiles)
+		case name == "src" || name == "blobs" || name == "test-data":
+			if !d.IsDir() {
+				l.error(name, "should be a directory")
+			}
+		case d.IsDir():
+			l.error(name, "should be a file, not a directory")
+		case name == "command":
+			hasCommand = true
+		case name == "memory" || name == "memory.txt":
+			if mem, err := l.read(name); err == nil {
+				if _, err := humanize.ParseBytes(mem); err != nil {
+					l.error(name, fmt.Sprintf("%s is not an amount of memory, e.g. 512Mi or 2Gi", mem))
+				}
+			}
+		case name == "calling-convention":
+			if cc, err := l.read(name); err == nil {
+				var c hlir.CallingConvention
+				if err := c.Set(cc); err != nil {
+					l.error(name, fmt.Sprintf("%s is not one of %s or %s", cc, hlir.CallingConventionFiles, hlir.CallingConventionStdio))
+				}
+			}
+		case slices.Contains(pythonLockfiles, name):
+			if _, err := requirementsFromLockfile(filepath.Join(sourcePath, name)); err != nil {
+				l.error(name, err.Error())
+			}
+		case name == "environment.yml" || name == "environment.yaml":
+			if b, err := os.ReadFile(filepath.Join(sourcePath, name)); err == nil {
+				var env struct{ Dependencies []any }
+				if err := yaml.Unmarshal(b, &env); err != nil {
+					l.error(name, fmt.Sprintf("should be a conda environment: %v", err))
+				} else if len(env.Dependencies) == 0 {
+					l.warn(name, "lists no dependencies")
+				}
+			}
+		case name == "env.yaml":
+			if b, err := os.ReadFile(filepath.Join(sourcePath, name)); err == nil {
+				var env hlir.Env
+				if err := yaml.Unmarshal(b, &env); err != nil {
+					l.error(name, fmt.Sprintf("should map environment variable names to values: %v", err))
+				}
+			}
+		}
+	}
+
+	l.lintSource(hasCommand)
+	l.lintBlobs()
+	l.lintTestData()
+
+	return l.findings, nil
+}
+
+type linter struct {
+	sourcePath string
+	findings   []Finding
+}
+
+func (l *linter) error(path, message string) {
+	l.findings = append(l.findings, Finding{path, message, true})
+}
+
+func (l *linter) warn(path, message string) {
+	l.findings = append(l.findings, Finding{path, message, false})
+}
+
+func (l *linter) read(path string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(l.sourcePath, path))
+	if err != nil {
+		l.error(path, err.Error())
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// Report a file that scan() will skip, suggesting what it may have
+// been meant to be
+func (l *linter) unrecognized(path string, known []string) {
+	if suggestion := closest(filepath.Base(path), known); suggestion != "" {
+		l.warn(path, fmt.Sprintf("will be ignored; did you mean %s?", suggestion))
+	} else {
+		l.warn(path, "will be ignored")
+	}
+}
+
+func (l *linter) lintSource(hasCommand bool) {
+	src := filepath.Join(l.sourcePath, "src")
+	if _, err := os.Stat(src); err != nil {
+		if !hasCommand {
+			l.error("src", "no src/ directory and no command file, so there is nothing to run")
+		}
+		return
+	}
+
+	var files []fs.DirEntry
+	filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, d)
+		}
+		return nil
+	})
+
+	if _, _, ok := recognize(files); ok || hasCommand {
+		return
+	}
+
+	// Nothing recognizable. Perhaps something is misnamed?
+	known := []string{"main.sh", "main.py", "main.js", "package.json", "main.go", "main.R"}
+	for _, d := range files {
+		if suggestion := closest(d.Name(), known); suggestion != "" {
+			l.error(filepath.Join("src", d.Name()), fmt.Sprintf("no recognized entry point in src/; did you mean %s?", suggestion))
+			return
+		}
+	}
+	l.error("src", fmt.Sprintf("no recognized entry point (one of %s, a jar, or an executable main), and no command file", strings.Join(known, ", ")))
+}
+
+func (l *linter) lintBlobs() {
+	entries, err := os.ReadDir(filepath.Join(l.sourcePath, "blobs"))
+	if err != nil {
+		return
+	}
+
+	for _, d := range entries {
+		if !slices.Contains([]string{"base64", "plain"}, d.Name()) {
+			l.unrecognized(filepath.Join("blobs", d.Name()), []string{"base64", "plain"})
+		}
+	}
+}
+
+func (l *linter) lintTestData() {
+	testData := filepath.Join(l.sourcePath, "test-data")
+	entries, err := os.ReadDir(testData)
+	if err != nil {
+		return
+	}
+
+	for _, d := range entries {
+		if !slices.Contains([]string{"input", "expected"}, d.Name()) {
+			l.unrecognized(filepath.Join("test-data", d.Name()), []string{"input", "expected"})
+		}
+	}
+
+	inputs, err := os.ReadDir(filepath.Join(testData, "input"))
+	if err != nil {
+		l.warn("test-data", "has no input/ directory, so there are no tests")
+		return
+	} else if len(inputs) == 0 {
+		l.warn("test-data/input", "is empty, so there are no tests")
+	}
+
+	expectedDir := filepath.Join(testData, "expected")
+	claimed := make(map[string]bool)
+	for _, input := range inputs {
+		if input.IsDir() {
+			l.warn(filepath.Join("test-data/input", input.Name()), "directories are not test inputs, and will be ignored")
+			continue
+		}
+
+		expected := expectedOutputs(expectedDir, input.Name())
+		if len(expected) == 0 {
+			l.warn(filepath.Join("test-data/input", input.Name()), fmt.Sprintf("has no expected output in test-data/expected (e.g. %s, %s.gz, or numbered %s)", input.Name(), input.Name(), numbered(input.Name())))
+		}
+		for _, e := range expected {
+			claimed[e] = true
+		}
+	}
+
+	if outputs, err := os.ReadDir(expectedDir); err == nil {
+		for _, output := range outputs {
+			if !claimed[output.Name()] {
+				l.warn(filepath.Join("test-data/expected", output.Name()), "does not correspond to any test input")
+			}
+		}
+	}
+}
+
+// e.g. test_0.txt for test.txt
+func numbered(input string) string {
+	if idx := strings.Index(input, "."); idx >= 0 {
+		return input[:idx] + "_0" + input[idx:]
+	}
+	return input + "_0"
+}
+
+// The known name that the given name is most plausibly a misspelling
+// of, if any
+func closest(name string, known []string) string {
+	if alias, ok := aliases[strings.ToLower(name)]; ok && slices.Contains(known, alias) {
+		return alias
+	}
+
+	best := ""
+	bestDistance := 0
+	for _, k := range known {
+		d := distance(strings.ToLower(name), strings.ToLower(k))
+		if d <= max(2, len(k)/4) && (best == "" || d < bestDistance) {
+			best = k
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+// Levenshtein edit distance
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,286 @@
+package overlay
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Python lockfiles that we honor in place of requirements.txt. We
+// convert these, at build time, into pinned (and, where the lockfile
+// records them, hashed) requirements, so that workers need neither
+// uv nor poetry, and so that the build does not depend on which of
+// those tools happens to be installed where it is built.
+var pythonLockfiles = []string{"requirements.lock", "uv.lock", "poetry.lock"}
+
+// The pinned requirements recorded in the given lockfile
+func requirementsFromLockfile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var reqs []requirement
+	switch filepath.Base(path) {
+	case "requirements.lock":
+		// e.g. from pip-compile or uv pip compile, and thus
+		// already in requirements.txt form
+		return strings.TrimSpace(string(b)), nil
+	case "uv.lock":
+		reqs, err = requirementsFromUvLock(b)
+	case "poetry.lock":
+		reqs, err = requirementsFromPoetryLock(b)
+	default:
+		return "", fmt.Errorf("Unsupported Python lockfile %s", path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("Error parsing %s: %v", filepath.Base(path), err)
+	}
+
+	header := "# Generated from " + filepath.Base(path)
+
+	// Given any hash, pip requires one for every requirement, so
+	// either hash them all, or none
+	unhashed := []string{}
+	for _, req := range reqs {
+		if len(req.hashes) == 0 {
+			unhashed = append(unhashed, req.name)
+		}
+	}
+	hashed := len(unhashed) == 0
+	if !hashed && len(unhashed) < len(reqs) {
+		slices.Sort(unhashed)
+		header += "\n# Without hashes, as none are recorded for " + strings.Join(unhashed, ", ")
+	}
+
+	lines := []string{}
+	for _, req := range reqs {
+		lines = append(lines, req.line(hashed))
+	}
+	slices.Sort(lines)
+	return header + "\n" + strings.Join(lines, "\n"), nil
+}
+
+// One line of requirements.txt
+type requirement struct {
+	name string
+
+	// e.g. numpy==2.1.0, or numpy @ https://...
+	spec string
+
+	// e.g. python_version >= '3.10'
+	marker string
+
+	// e.g. sha256:...
+	hashes []string
+}
+
+// e.g. numpy==2.1.0
+func pinned(name, version, marker string, hashes []string) requirement {
+	return requirement{name, name + "==" + version, marker, hashes}
+}
+
+// e.g. numpy @ https://...
+func fromUrl(name, url, marker string, hashes []string) requirement {
+	return requirement{name, name + " @ " + url, marker, hashes}
+}
+
+// e.g. numpy==2.1.0 ; python_version >= '3.10' --hash=sha256:...
+func (req requirement) line(withHashes bool) string {
+	line := req.spec
+	if req.marker != "" {
+		line += " ; " + req.marker
+	}
+	if withHashes {
+		for _, hash := range req.hashes {
+			line += " --hash=" + hash
+		}
+	}
+	return line
+}
+
+type uvArtifact struct {
+	Url  string
+	Hash string
+}
+
+type uvDependency struct {
+	Name   string
+	Marker string
+}
+
+type uvPackage struct {
+	Name         string
+	Version      string
+	Source       map[string]string
+	Sdist        *uvArtifact
+	Wheels       []uvArtifact
+	Dependencies []uvDependency
+}
+
+// The hashes of the given package's sdist and wheels
+func (pkg uvPackage) hashes() []string {
+	hashes := []string{}
+	if pkg.Sdist != nil && pkg.Sdist.Hash != "" {
+		hashes = append(hashes, pkg.Sdist.Hash)
+	}
+	for _, wheel := range pkg.Wheels {
+		if wheel.Hash != "" {
+			hashes = append(hashes, wheel.Hash)
+		}
+	}
+	return hashes
+}
+
+func requirementsFromUvLock(b []byte) ([]requirement, error) {
+	var lock struct {
+		Package []uvPackage
+	}
+	if err := toml.Unmarshal(b, &lock); err != nil {
+		return nil, err
+	}
+
+	// The project itself is the root, whose (non-dev, non-optional)
+	// dependencies are what we install. Without a root, e.g. a
+	// lock of bare requirements, we install everything.
+	byName := map[string]uvPackage{}
+	roots := []uvPackage{}
+	for _, pkg := range lock.Package {
+		byName[pkg.Name] = pkg
+		if pkg.Source["virtual"] == "." || pkg.Source["editable"] == "." || pkg.Source["directory"] == "." {
+			roots = append(roots, pkg)
+		}
+	}
+	if len(roots) == 0 {
+		roots = lock.Package
+	}
+
+	// Walk the dependency graph, noting the markers under which each
+	// package is needed. A package needed unconditionally by any of
+	// its dependents has no marker. Note: this does not carry a
+	// dependent's own marker down to its dependencies.
+	markers := map[string][]string{}
+	unconditional := map[string]bool{}
+	seen := map[string]bool{}
+	var visit func(pkg uvPackage)
+	visit = func(pkg uvPackage) {
+		if seen[pkg.Name] {
+			return
+		}
+		seen[pkg.Name] = true
+		for _, dep := range pkg.Dependencies {
+			if dep.Marker == "" {
+				unconditional[dep.Name] = true
+			} else {
+				markers[dep.Name] = append(markers[dep.Name], dep.Marker)
+			}
+			if next, ok := byName[dep.Name]; ok {
+				visit(next)
+			}
+		}
+	}
+	for _, root := range roots {
+		unconditional[root.Name] = true
+		visit(root)
+	}
+
+	reqs := []requirement{}
+	for _, pkg := range lock.Package {
+		if !seen[pkg.Name] {
+			// e.g. a dev dependency
+			continue
+		}
+
+		marker := ""
+		if !unconditional[pkg.Name] {
+			marker = "(" + strings.Join(markers[pkg.Name], ") or (") + ")"
+		}
+
+		switch {
+		case pkg.Source["registry"] != "":
+			reqs = append(reqs, pinned(pkg.Name, pkg.Version, marker, pkg.hashes()))
+		case pkg.Source["url"] != "":
+			reqs = append(reqs, fromUrl(pkg.Name, pkg.Source["url"], marker, pkg.hashes()))
+		case slices.ContainsFunc(roots, func(root uvPackage) bool { return root.Name == pkg.Name }):
+			// The project itself, which is carried as source
+		default:
+			return nil, fmt.Errorf("Unsupported source for %s; only packages from a registry or url can be installed from uv.lock", pkg.Name)
+		}
+	}
+
+	return reqs, nil
+}
+
+type poetryPackage struct {
+	Name     string
+	Version  string
+	Optional bool
+	Category string
+	Groups   []string
+	Markers  any
+	Files    []struct {
+		Hash string
+	}
+	Source struct {
+		Type string
+		Url  string
+	}
+}
+
+func requirementsFromPoetryLock(b []byte) ([]requirement, error) {
+	var lock struct {
+		Package  []poetryPackage
+		Metadata struct {
+			// Older lockfiles list hashes here, by package name
+			Files map[string][]struct {
+				Hash string
+			}
+		}
+	}
+	if err := toml.Unmarshal(b, &lock); err != nil {
+		return nil, err
+	}
+
+	reqs := []requirement{}
+	for _, pkg := range lock.Package {
+		if pkg.Optional || pkg.Category == "dev" || (len(pkg.Groups) > 0 && !slices.Contains(pkg.Groups, "main")) {
+			// Only needed for an extra, or for development
+			continue
+		}
+
+		// Either a string, or per-group, e.g. {main = "..."}
+		marker := ""
+		switch m := pkg.Markers.(type) {
+		case string:
+			marker = m
+		case map[string]any:
+			marker, _ = m["main"].(string)
+		}
+
+		files := pkg.Files
+		if len(files) == 0 {
+			files = lock.Metadata.Files[pkg.Name]
+		}
+		hashes := []string{}
+		for _, file := range files {
+			if file.Hash != "" {
+				hashes = append(hashes, file.Hash)
+			}
+		}
+
+		switch pkg.Source.Type {
+		case "", "legacy":
+			reqs = append(reqs, pinned(pkg.Name, pkg.Version, marker, hashes))
+		case "url":
+			reqs = append(reqs, fromUrl(pkg.Name, pkg.Source.Url, marker, hashes))
+		default:
+			return nil, fmt.Errorf("Unsupported %s source for %s; only packages from a registry or url can be installed from poetry.lock", pkg.Source.Type, pkg.Name)
+		}
+	}
+
+	return reqs, nil
+}
@@ -0,0 +1,194 @@
+package overlay
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func lockfile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func requirementsFrom(t *testing.T, name, content string) string {
+	t.Helper()
+	req, err := requirementsFromLockfile(lockfile(t, name, content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func checkLines(t *testing.T, req string, expected ...string) {
+	t.Helper()
+	if got := strings.Join(strings.Split(req, "\n")[1:], "\n"); got != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), got)
+	}
+}
+
+const uvLock = `version = 1
+
+[[package]]
+name = "app"
+version = "0.1.0"
+source = { virtual = "." }
+dependencies = [
+    { name = "numpy" },
+    { name = "colorama", marker = "sys_platform == 'win32'" },
+]
+
+[package.dev-dependencies]
+dev = [{ name = "pytest" }]
+
+[[package]]
+name = "numpy"
+version = "2.1.0"
+source = { registry = "https://pypi.org/simple" }
+sdist = { url = "https://files/numpy-2.1.0.tar.gz", hash = "sha256:aaa" }
+wheels = [{ url = "https://files/numpy-2.1.0-cp312.whl", hash = "sha256:bbb" }]
+
+[[package]]
+name = "colorama"
+version = "0.4.6"
+source = { registry = "https://pypi.org/simple" }
+wheels = [{ url = "https://files/colorama-0.4.6-py3-none-any.whl", hash = "sha256:ccc" }]
+
+[[package]]
+name = "pytest"
+version = "8.3.3"
+source = { registry = "https://pypi.org/simple" }
+wheels = [{ url = "https://files/pytest-8.3.3-py3-none-any.whl", hash = "sha256:ddd" }]
+`
+
+func TestUvLock(t *testing.T) {
+	req := requirementsFrom(t, "uv.lock", uvLock)
+	if !strings.HasPrefix(req, "# Generated from uv.lock\n") {
+		t.Errorf("expected a header, got %s", req)
+	}
+	checkLines(t, req,
+		"colorama==0.4.6 ; (sys_platform == 'win32') --hash=sha256:ccc",
+		"numpy==2.1.0 --hash=sha256:aaa --hash=sha256:bbb",
+	)
+}
+
+// uv.lock with the root also depending on the given package
+func withDependency(name string) string {
+	return strings.Replace(uvLock, `    { name = "numpy" },`, `    { name = "numpy" },
+    { name = "`+name+`" },`, 1)
+}
+
+func TestUvLockUrlIsHashed(t *testing.T) {
+	req := requirementsFrom(t, "uv.lock", withDependency("extra")+`
+[[package]]
+name = "extra"
+version = "1.0"
+source = { url = "https://example.com/extra-1.0.tar.gz" }
+sdist = { hash = "sha256:eee" }
+`)
+	if !strings.Contains(req, "extra @ https://example.com/extra-1.0.tar.gz --hash=sha256:eee") {
+		t.Errorf("expected a hashed url requirement, got\n%s", req)
+	}
+	if strings.Contains(req, "Without hashes") {
+		t.Errorf("expected every requirement to be hashed, got\n%s", req)
+	}
+}
+
+// pip refuses a mix of hashed and unhashed requirements
+func TestUvLockMixedHashes(t *testing.T) {
+	req := requirementsFrom(t, "uv.lock", withDependency("extra")+`
+[[package]]
+name = "extra"
+version = "1.0"
+source = { url = "https://example.com/extra-1.0.tar.gz" }
+`)
+	if strings.Contains(req, "--hash") {
+		t.Errorf("expected no hashes, given a requirement without one, got\n%s", req)
+	}
+	if !strings.Contains(req, "# Without hashes, as none are recorded for extra\n") {
+		t.Errorf("expected a note of why there are no hashes, got\n%s", req)
+	}
+	if !strings.HasSuffix(req, "\nnumpy==2.1.0") {
+		t.Errorf("expected numpy to remain pinned, got\n%s", req)
+	}
+}
+
+func TestUvLockUnsupportedSource(t *testing.T) {
+	_, err := requirementsFromLockfile(lockfile(t, "uv.lock", withDependency("local")+`
+[[package]]
+name = "local"
+version = "1.0"
+source = { directory = "../local" }
+`))
+	if err == nil || !strings.Contains(err.Error(), "Unsupported source for local") {
+		t.Errorf("expected an unsupported source error, got %v", err)
+	}
+}
+
+const poetryLock = `[[package]]
+name = "requests"
+version = "2.32.3"
+optional = false
+groups = ["main"]
+markers = "python_version >= \"3.8\""
+files = [
+    {file = "requests-2.32.3-py3-none-any.whl", hash = "sha256:fff"},
+]
+
+[[package]]
+name = "pytest"
+version = "8.3.3"
+optional = false
+groups = ["dev"]
+files = [
+    {file = "pytest-8.3.3-py3-none-any.whl", hash = "sha256:ggg"},
+]
+
+[[package]]
+name = "ujson"
+version = "5.10.0"
+optional = true
+groups = ["main"]
+files = []
+
+[[package]]
+name = "legacy"
+version = "1.0"
+optional = false
+category = "main"
+
+[metadata]
+lock-version = "1.1"
+
+[metadata.files]
+legacy = [
+    {file = "legacy-1.0.tar.gz", hash = "sha256:hhh"},
+]
+`
+
+func TestPoetryLock(t *testing.T) {
+	req := requirementsFrom(t, "poetry.lock", poetryLock)
+	checkLines(t, req,
+		"legacy==1.0 --hash=sha256:hhh",
+		`requests==2.32.3 ; python_version >= "3.8" --hash=sha256:fff`,
+	)
+}
+
+func TestRequirementsLock(t *testing.T) {
+	content := "six==1.16.0 \\\n    --hash=sha256:iii\n"
+	if req := requirementsFrom(t, "requirements.lock", content); req != strings.TrimSpace(content) {
+		t.Errorf("expected requirements.lock as is, got\n%s", req)
+	}
+}
+
+func TestInvalidLockfile(t *testing.T) {
+	_, err := requirementsFromLockfile(lockfile(t, "poetry.lock", "[[package]\n"))
+	if err == nil || !strings.Contains(err.Error(), "Error parsing poetry.lock") {
+		t.Errorf("expected a parse error, got %v", err)
+	}
+}
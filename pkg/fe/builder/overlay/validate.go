@@ -0,0 +1,103 @@
+package overlay
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"lunchpail.io/pkg/fe/parser"
+)
+
+// Files and directories of a YAML application source that are not
+// resource specs. See copyYamlSpecIntoTemplate() and scan().
+var notSpecs = []string{"values.yaml", "env.yaml", "src", "data", "blobs", "test-data", ".git"}
+
+// The YAML resource specs in the given file or directory
+func YamlSpecs(path string) ([]string, error) {
+	specs := []string{}
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if p != path && slices.Contains(notSpecs, d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		} else if !d.IsDir() && (p == path || filepath.Ext(p) == ".yaml" || filepath.Ext(p) == ".yml") {
+			specs = append(specs, p)
+		}
+		return nil
+	})
+
+	return specs, err
+}
+
+// Check the YAML resource specs in the given file or directory
+// against the published schemas. Specs that contain templates cannot
+// be checked until they are instantiated, by `up`; these are returned
+// separately.
+func ValidateYaml(path string, strict bool) (findings []Finding, templated []string, err error) {
+	specs, err := YamlSpecs(path)
+	if err != nil {
+		return
+	}
+
+	for _, spec := range specs {
+		rel, rerr := filepath.Rel(path, spec)
+		if rerr != nil || rel == "." {
+			rel = spec
+		}
+
+		b, rerr := os.ReadFile(spec)
+		if rerr != nil {
+			return nil, nil, rerr
+		} else if strings.Contains(string(b), "{{") {
+			templated = append(templated, rel)
+			continue
+		}
+
+		for _, p := range parser.Validate(string(b), rel, strict) {
+			findings = append(findings, Finding{p.Where(), p.What(), p.IsError})
+		}
+	}
+
+	return
+}
+
+// Fail the build if the application YAML is invalid
+func validateYamlSpec(appdir string, opts Options) error {
+	findings, templated, err := ValidateYaml(appdir, opts.BuildOptions.Strict)
+	if err != nil {
+		return err
+	}
+
+	if opts.Verbose() {
+		for _, spec := range templated {
+			fmt.Fprintf(os.Stderr, "Not validating %s until up, as it contains templates\n", spec)
+		}
+	}
+
+	errs := []string{}
+	for _, finding := range findings {
+		if finding.IsError {
+			errs = append(errs, "  "+finding.Path+": "+finding.Message)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", finding.Path, finding.Message)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Invalid application YAML:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// Does the given source directory contain YAML resource specs, as
+// opposed to following the conventions of scan()?
+func HasYamlSpecs(sourcePath string) bool {
+	specs, err := YamlSpecs(sourcePath)
+	return err == nil && len(specs) > 0
+}
@@ -35,5 +35,9 @@ func copyYamlSpecIntoTemplate(appname, sourcePath, templatePath string, opts Opt
 		return
 	}
 
+	if err = validateYamlSpec(appdir(templatePath), opts); err != nil {
+		return
+	}
+
 	return
 }
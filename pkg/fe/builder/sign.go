@@ -0,0 +1,39 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+
+	"lunchpail.io/pkg/build"
+)
+
+// The binaries emitted by a build
+func outputs(opts Options) []string {
+	if !opts.AllPlatforms {
+		return []string{opts.Name}
+	}
+
+	names := []string{}
+	for _, targetOs := range supportedOs() {
+		for _, targetArch := range supportedArch() {
+			names = append(names, opts.Name+"-"+targetOs+"-"+targetArch)
+		}
+	}
+	return names
+}
+
+// Write a detached signature alongside each emitted binary
+func sign(opts Options) error {
+	for _, binary := range outputs(opts) {
+		sig, err := build.Sign(binary, opts.SigningKey)
+		if err != nil {
+			return err
+		}
+
+		if opts.Verbose() {
+			fmt.Fprintf(os.Stderr, "Signed %s -> %s\n", binary, sig)
+		}
+	}
+
+	return nil
+}
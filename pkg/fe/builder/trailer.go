@@ -0,0 +1,80 @@
+package builder
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+)
+
+// A stage that holds only the application template and breadcrumbs
+func stageForTrailer() (string, error) {
+	dir, err := ioutil.TempDir("", "lunchpail")
+	if err != nil {
+		return "", err
+	}
+
+	return dir, os.MkdirAll(filepath.Join(dir, "pkg/build"), 0755)
+}
+
+// A platform for which we emit a binary
+type target struct {
+	os   string
+	arch string
+}
+
+// The platforms for which we emit binaries
+func targets(opts Options) []target {
+	if !opts.AllPlatforms {
+		return []target{{runtime.GOOS, runtime.GOARCH}}
+	}
+
+	ts := []target{}
+	for _, targetOs := range supportedOs() {
+		for _, targetArch := range supportedArch() {
+			ts = append(ts, target{targetOs, targetArch})
+		}
+	}
+	return ts
+}
+
+// The prebuilt lunchpail executable to which we can append the
+// application for the given target, if any. If none, we must compile
+// with the Go toolchain.
+func prebuiltFor(t target, opts Options) (string, bool) {
+	if opts.Toolchain {
+		return "", false
+	}
+
+	if t.os == "darwin" {
+		// Appending to a Mach-O executable invalidates its code
+		// signature, and `codesign` refuses to re-sign files
+		// with data past their __LINKEDIT segment
+		return "", false
+	}
+
+	if opts.PrebuiltDir != "" {
+		exe := filepath.Join(opts.PrebuiltDir, "lunchpail-"+t.os+"-"+t.arch)
+		if _, err := os.Stat(exe); err == nil {
+			return exe, true
+		}
+	}
+
+	if t.os == runtime.GOOS && t.arch == runtime.GOARCH {
+		// We can use ourselves
+		if exe, err := os.Executable(); err == nil {
+			return exe, true
+		}
+	}
+
+	return "", false
+}
+
+// Must we compile with the Go toolchain for any of our targets?
+func needsToolchain(opts Options) bool {
+	return slices.ContainsFunc(targets(opts), func(t target) bool {
+		_, ok := prebuiltFor(t, opts)
+		return !ok
+	})
+}
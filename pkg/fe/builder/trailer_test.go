@@ -0,0 +1,58 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestAllPlatformsWithoutPrebuiltCompiles(t *testing.T) {
+	if !needsToolchain(Options{AllPlatforms: true}) {
+		t.Fatal("expected -A without --prebuilt to compile for the platforms we cannot append to")
+	}
+}
+
+func TestDarwinIsAlwaysCompiled(t *testing.T) {
+	dir := t.TempDir()
+	for _, arch := range supportedArch() {
+		if err := os.WriteFile(filepath.Join(dir, "lunchpail-darwin-"+arch), []byte{}, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, arch := range supportedArch() {
+		if exe, ok := prebuiltFor(target{"darwin", arch}, Options{PrebuiltDir: dir}); ok {
+			t.Errorf("expected darwin/%s to be compiled, rather than appended to %s", arch, exe)
+		}
+	}
+}
+
+func TestPrebuiltForOtherPlatforms(t *testing.T) {
+	dir := t.TempDir()
+	for _, arch := range supportedArch() {
+		if err := os.WriteFile(filepath.Join(dir, "lunchpail-linux-"+arch), []byte{}, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, arch := range supportedArch() {
+		want := filepath.Join(dir, "lunchpail-linux-"+arch)
+		if exe, ok := prebuiltFor(target{"linux", arch}, Options{PrebuiltDir: dir}); !ok || exe != want {
+			t.Errorf("expected linux/%s to use %s, got %s", arch, want, exe)
+		}
+		if _, ok := prebuiltFor(target{"linux", arch}, Options{PrebuiltDir: dir, Toolchain: true}); ok {
+			t.Errorf("expected linux/%s to be compiled with --toolchain", arch)
+		}
+	}
+}
+
+func TestHostBuildUsesOurselves(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("darwin binaries are always compiled")
+	}
+
+	if needsToolchain(Options{}) {
+		t.Fatal("expected a build for this platform to append to ourselves")
+	}
+}
@@ -0,0 +1,175 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+// The pip platform tags for each os/arch we support. pip will also
+// accept wheels built for older manylinux and macOS versions.
+var pipPlatforms = map[string][]string{
+	"linux/amd64":  {"manylinux2014_x86_64", "linux_x86_64"},
+	"linux/arm64":  {"manylinux2014_aarch64", "linux_aarch64"},
+	"darwin/amd64": {"macosx_14_0_x86_64"},
+	"darwin/arm64": {"macosx_14_0_arm64"},
+}
+
+// Workers that do not run on the host that brought up the run, i.e.
+// in Kubernetes or on Cloud virtual machines, run on these
+var workerPlatforms = []string{"linux/amd64", "linux/arm64"}
+
+// By default, bundle wheels for the platforms we emit binaries for
+// (the given host, unless building for all platforms), and for the
+// platforms on which workers may run elsewhere
+func pythonPlatforms(host string, opts Options) []string {
+	if len(opts.PythonPlatforms) > 0 {
+		return opts.PythonPlatforms
+	}
+
+	platforms := []string{host}
+	if opts.AllPlatforms {
+		platforms = []string{}
+		for _, targetOs := range supportedOs() {
+			for _, targetArch := range supportedArch() {
+				platforms = append(platforms, targetOs+"/"+targetArch)
+			}
+		}
+	}
+
+	for _, platform := range workerPlatforms {
+		if !slices.Contains(platforms, platform) {
+			platforms = append(platforms, platform)
+		}
+	}
+	return platforms
+}
+
+// Resolve the Python requirements of the given application into
+// wheels, and place them in the app template, so that workers can
+// install them without reaching a package index
+func bundleWheelhouse(ir hlir.HLIR, appTemplatePath string, opts Options) error {
+	platforms := pythonPlatforms(runtime.GOOS+"/"+runtime.GOARCH, opts)
+	for _, platform := range platforms {
+		if _, ok := pipPlatforms[platform]; !ok {
+			supported := []string{}
+			for p := range pipPlatforms {
+				supported = append(supported, p)
+			}
+			slices.Sort(supported)
+			return fmt.Errorf("Unsupported Python platform %s, expected one of %s", platform, strings.Join(supported, ", "))
+		}
+	}
+
+	// Start afresh, in case we are re-building an application that
+	// already carries wheels for requirements it no longer has
+	if err := os.RemoveAll(build.WheelhouseDirFor(appTemplatePath)); err != nil {
+		return err
+	}
+
+	bundled := false
+	for _, app := range ir.Applications {
+		for _, needs := range app.Spec.Needs {
+			if needs.Name != "python" || needs.Requirements == "" {
+				continue
+			}
+
+			for _, platform := range platforms {
+				if err := downloadWheels(needs, app.Spec.Image, platform, appTemplatePath, opts.Verbose()); err != nil {
+					return err
+				}
+			}
+			bundled = true
+		}
+	}
+
+	if !bundled {
+		fmt.Fprintf(os.Stderr, "Warning: --offline-python was given, but the application has no Python requirements\n")
+	}
+
+	return nil
+}
+
+func downloadWheels(needs hlir.Needs, image, platform, appTemplatePath string, verbose bool) error {
+	goos, goarch, _ := strings.Cut(platform, "/")
+	dir := build.WheelhouseFor(appTemplatePath, []byte(needs.Requirements), goos, goarch)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	// Keep a copy of the requirements alongside their wheels, for
+	// pip, and for anyone curious as to what the wheels are for
+	requirements := filepath.Join(filepath.Dir(dir), "requirements.txt")
+	if err := os.WriteFile(requirements, []byte(needs.Requirements), 0644); err != nil {
+		return err
+	}
+
+	version, err := pythonVersion(needs.Version, image)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Resolving Python requirements into wheels for %s and Python %s\n", platform, version)
+	cmd := exec.Command("python3", pipDownloadArgs(dir, requirements, platform, version, verbose)...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Unable to resolve Python requirements into wheels for %s: %v", platform, err)
+	}
+
+	return nil
+}
+
+// The arguments to `python3` that resolve the given requirements into
+// wheels for the given platform and major.minor Python version. These
+// need not be those of the python3 doing the resolving.
+func pipDownloadArgs(dir, requirements, platform, version string, verbose bool) []string {
+	args := []string{"-m", "pip", "download", "--dest", dir, "--requirement", requirements, "--only-binary=:all:", "--implementation", "cp", "--python-version", version}
+	for _, tag := range pipPlatforms[platform] {
+		args = append(args, "--platform", tag)
+	}
+	for _, abi := range pythonAbis(version) {
+		args = append(args, "--abi", abi)
+	}
+	if !verbose {
+		args = append(args, "--quiet")
+	}
+	return args
+}
+
+var majorMinor = regexp.MustCompile(`^3\.\d+$`)
+
+// e.g. docker.io/python:3.12 or python:3.12.4-slim
+var pythonImage = regexp.MustCompile(`(^|/)python:(3\.\d+)([.-]|$)`)
+
+// The major.minor Python version for which to resolve wheels: that
+// asked for by the application, or else that of the python image it
+// runs in. Not that of the python3 doing the build, as workers need
+// not run on the same host, or even the same os.
+func pythonVersion(version, image string) (string, error) {
+	if majorMinor.MatchString(version) {
+		return version, nil
+	} else if version != "" && version != "latest" && version != "3" {
+		return "", fmt.Errorf("Unsupported Python version %s for --offline-python, expected e.g. 3.12", version)
+	}
+
+	if match := pythonImage.FindStringSubmatch(image); match != nil {
+		return match[2], nil
+	}
+
+	return "", fmt.Errorf("Unable to determine the Python version for --offline-python from the image %q. Specify a version in the application's python needs, e.g. 3.12", image)
+}
+
+// The ABIs of wheels that CPython of the given major.minor version
+// can install: its own, the stable ABI, and pure-Python wheels
+func pythonAbis(version string) []string {
+	return []string{"cp" + strings.Replace(version, ".", "", 1), "abi3", "none"}
+}
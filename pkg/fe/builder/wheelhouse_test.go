@@ -0,0 +1,110 @@
+package builder
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestPythonVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		image   string
+		want    string
+		wantErr bool
+	}{
+		{"3.11", "", "3.11", false},
+		{"3.11", "docker.io/python:3.12", "3.11", false},
+		{"", "docker.io/python:3.12", "3.12", false},
+		{"latest", "python:3.12-slim", "3.12", false},
+		{"3", "docker.io/library/python:3.13.1-bookworm", "3.13", false},
+		{"", "docker.io/alpine:3", "", true},
+		{"", "docker.io/notpython:3.12", "", true},
+		{"", "", "", true},
+		{"3.11.4", "docker.io/python:3.12", "", true},
+		{"2.7", "", "", true},
+	}
+
+	for _, tt := range tests {
+		v, err := pythonVersion(tt.version, tt.image)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("version %q image %q: expected error=%v, got %v", tt.version, tt.image, tt.wantErr, err)
+		} else if v != tt.want {
+			t.Errorf("version %q image %q: expected %q, got %q", tt.version, tt.image, tt.want, v)
+		}
+	}
+}
+
+func TestPythonAbis(t *testing.T) {
+	if abis := pythonAbis("3.12"); !reflect.DeepEqual(abis, []string{"cp312", "abi3", "none"}) {
+		t.Errorf("unexpected %v", abis)
+	}
+}
+
+func TestPythonPlatforms(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		opts Options
+		want []string
+	}{
+		{"linux host", "linux/amd64", Options{}, []string{"linux/amd64", "linux/arm64"}},
+		{"macOS host", "darwin/arm64", Options{}, []string{"darwin/arm64", "linux/amd64", "linux/arm64"}},
+		{"explicit", "darwin/arm64", Options{PythonPlatforms: []string{"linux/arm64"}}, []string{"linux/arm64"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pythonPlatforms(tt.host, tt.opts); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	all := pythonPlatforms("darwin/arm64", Options{AllPlatforms: true})
+	for _, platform := range []string{"linux/amd64", "linux/arm64", "darwin/amd64", "darwin/arm64"} {
+		if n := len(slices.DeleteFunc(slices.Clone(all), func(p string) bool { return p != platform })); n != 1 {
+			t.Errorf("expected %s exactly once for all platforms, got %v", platform, all)
+		}
+	}
+}
+
+// Resolving on a macOS host with Python 3.11, for a worker that will
+// run on Kubernetes in a python:3.12 image
+func TestCrossTargetPipDownload(t *testing.T) {
+	version, err := pythonVersion("", "docker.io/python:3.12")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, platform := range pythonPlatforms("darwin/arm64", Options{}) {
+		if _, ok := pipPlatforms[platform]; !ok {
+			t.Errorf("expected a supported platform, got %s", platform)
+		}
+	}
+
+	args := pipDownloadArgs("/wheels", "/requirements.txt", "linux/amd64", version, false)
+	for _, want := range [][]string{
+		{"--python-version", "3.12"},
+		{"--platform", "manylinux2014_x86_64"},
+		{"--platform", "linux_x86_64"},
+		{"--abi", "cp312"},
+		{"--implementation", "cp"},
+	} {
+		found := false
+		for i := range len(args) - 1 {
+			if args[i] == want[0] && args[i+1] == want[1] {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected %v, got %v", want, args)
+		}
+	}
+	if slices.Contains(args, "cp311") || slices.Contains(args, "macosx_14_0_arm64") {
+		t.Errorf("expected nothing of the host, got %v", args)
+	}
+	if !slices.Contains(args, "--only-binary=:all:") {
+		t.Errorf("expected only binary wheels, got %v", args)
+	}
+}
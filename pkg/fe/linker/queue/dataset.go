@@ -0,0 +1,37 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"lunchpail.io/pkg/fe/linker/secrets"
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/queue"
+)
+
+// Does this dataset need s3 credentials?
+func HasS3Credentials(dataset hlir.Dataset) bool {
+	return dataset.S3.Secret != "" || dataset.S3.Rclone.RemoteName != ""
+}
+
+// The endpoint and credentials of an s3 dataset, taken either from
+// its secret reference or from its rclone config
+func SpecFromDataset(ctx context.Context, dataset hlir.Dataset, runname string, internalS3Port int) (queue.Spec, error) {
+	if dataset.S3.Secret != "" {
+		data, err := secrets.Ref(dataset.S3.Secret).Resolve(ctx, "endpoint", "accessKeyID", "secretAccessKey")
+		if err != nil {
+			return queue.Spec{}, err
+		}
+
+		return queue.Spec{Endpoint: data["endpoint"], AccessKey: data["accessKeyID"], SecretKey: data["secretAccessKey"]}, nil
+	}
+
+	isValid, spec, err := SpecFromRcloneRemoteName(dataset.S3.Rclone.RemoteName, "", runname, internalS3Port)
+	if err != nil {
+		return queue.Spec{}, err
+	} else if !isValid {
+		return queue.Spec{}, fmt.Errorf("Invalid or missing rclone config for given remote=%s", dataset.S3.Rclone.RemoteName)
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,39 @@
+package queue
+
+import (
+	"context"
+
+	"lunchpail.io/pkg/fe/linker/secrets"
+	"lunchpail.io/pkg/ir/queue"
+)
+
+// Take the queue credentials from the given secret. If
+// byReferenceIn is non-empty, and the secret is a Kubernetes Secret
+// in that namespace, the run will consume the Secret directly, and
+// we never see the credentials.
+func WithSecret(ctx context.Context, spec queue.Spec, ref secrets.Ref, byReferenceIn string) (queue.Spec, error) {
+	if namespace, name, ok := ref.KubernetesSecret(); ok && byReferenceIn != "" && (namespace == "" || namespace == byReferenceIn) {
+		spec.Auto = false
+		spec.Endpoint = ""
+		spec.SecretRef = name
+		return spec.WithoutCredentials(), nil
+	}
+
+	data, err := ref.Resolve(ctx, "accessKeyID", "secretAccessKey")
+	if err != nil {
+		return spec, err
+	}
+
+	spec.AccessKey = data["accessKeyID"]
+	spec.SecretKey = data["secretAccessKey"]
+
+	if endpoint, ok := data["endpoint"]; ok && endpoint != "" {
+		spec.Endpoint = endpoint
+		spec.Auto = isInternalS3(endpoint)
+	}
+	if bucket, ok := data["bucket"]; ok && bucket != "" {
+		spec.Bucket = bucket
+	}
+
+	return spec, nil
+}
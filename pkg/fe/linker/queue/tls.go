@@ -0,0 +1,94 @@
+package queue
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+
+	"lunchpail.io/pkg/ir/queue"
+)
+
+// How long the certificates of an internal queue remain valid
+var certificateLifetime = 30 * 24 * time.Hour
+
+// Serve the given internal queue over TLS, using a fresh certificate
+// authority that signs a server certificate for the given hosts
+func WithTLS(spec queue.Spec, hosts ...string) (queue.Spec, error) {
+	notBefore := time.Now().Add(-time.Minute)
+	notAfter := notBefore.Add(certificateLifetime)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return spec, err
+	}
+
+	caTemplate := x509.Certificate{
+		SerialNumber:          serialNumber(),
+		Subject:               pkix.Name{Organization: []string{"lunchpail.io"}, CommonName: "lunchpail queue CA"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, &caTemplate, &caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return spec, err
+	}
+	ca, err := x509.ParseCertificate(caDer)
+	if err != nil {
+		return spec, err
+	}
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return spec, err
+	}
+
+	serverTemplate := x509.Certificate{
+		SerialNumber: serialNumber(),
+		Subject:      pkix.Name{Organization: []string{"lunchpail.io"}, CommonName: hosts[0]},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, host)
+		}
+	}
+	serverDer, err := x509.CreateCertificate(rand.Reader, &serverTemplate, ca, &serverKey.PublicKey, caKey)
+	if err != nil {
+		return spec, err
+	}
+
+	serverKeyDer, err := x509.MarshalECPrivateKey(serverKey)
+	if err != nil {
+		return spec, err
+	}
+
+	spec.TLS = &queue.TLS{
+		CA:   string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDer})),
+		Cert: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverDer})),
+		Key:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: serverKeyDer})),
+	}
+	spec.Endpoint = "https://" + strings.TrimPrefix(strings.TrimPrefix(spec.Endpoint, "http://"), "https://")
+
+	return spec, nil
+}
+
+func serialNumber() *big.Int {
+	n, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	return n
+}
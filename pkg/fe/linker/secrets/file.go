@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+type tool string
+
+const (
+	ageTool  tool = "age"
+	sopsTool      = "sops"
+)
+
+// A file of yaml key/value pairs, encrypted with age or sops
+type encryptedFile struct {
+	tool tool
+	path string
+}
+
+func (f encryptedFile) Resolve(ctx context.Context) (map[string]string, error) {
+	args := []string{"--decrypt"}
+	if f.tool == ageTool {
+		identity, err := ageIdentity()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--identity", identity)
+	}
+	args = append(args, f.path)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, string(f.tool), args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decrypt %s with %s: %v %s", f.path, f.tool, err, stderr.String())
+	}
+
+	var data map[string]any
+	if err := yaml.Unmarshal(out, &data); err != nil {
+		return nil, fmt.Errorf("Decrypted %s is not a yaml map: %v", f.path, err)
+	}
+
+	return stringify(data), nil
+}
+
+// The age identity file, following the sops conventions for locating it
+func ageIdentity() (string, error) {
+	for _, env := range []string{"LUNCHPAIL_AGE_IDENTITY", "SOPS_AGE_KEY_FILE"} {
+		if identity := os.Getenv(env); identity != "" {
+			return identity, nil
+		}
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "sops", "age", "keys.txt"), nil
+}
@@ -0,0 +1,52 @@
+package secrets
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// A Secret in the current Kubernetes context
+type kubernetesSecret struct {
+	namespace string
+	name      string
+}
+
+func (s kubernetesSecret) Resolve(ctx context.Context) (map[string]string, error) {
+	config := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
+
+	namespace := s.namespace
+	if namespace == "" {
+		ns, _, err := config.Namespace()
+		if err != nil {
+			return nil, err
+		}
+		namespace = ns
+	}
+
+	restConfig, err := config.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, s.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]string)
+	for k, v := range secret.Data {
+		data[k] = string(v)
+	}
+	for k, v := range secret.StringData {
+		data[k] = v
+	}
+	return data, nil
+}
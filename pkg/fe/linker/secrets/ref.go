@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// A reference to a secret held by a secrets provider, so that
+// credentials need not be baked into build options or environment
+// variables. Supported forms:
+//
+//	k8s://name or k8s://namespace/name  a Kubernetes Secret
+//	vault://mount/path                  a HashiCorp Vault KV secret
+//	age:///path/to/file.age             an age-encrypted yaml file
+//	sops:///path/to/file.yaml           a sops-encrypted yaml file
+type Ref string
+
+type Provider interface {
+	// The key/value data of the secret
+	Resolve(ctx context.Context) (map[string]string, error)
+}
+
+var refPattern = regexp.MustCompile("^(k8s|vault|age|sops)://(.+)$")
+
+func (ref Ref) Provider() (Provider, error) {
+	match := refPattern.FindStringSubmatch(string(ref))
+	if len(match) != 3 {
+		return nil, fmt.Errorf("Unsupported secret reference '%s', expected one of k8s://, vault://, age://, or sops://", ref)
+	}
+
+	switch match[1] {
+	case "k8s":
+		namespace, name := splitKubernetesRef(match[2])
+		return kubernetesSecret{namespace, name}, nil
+	case "vault":
+		return vault{match[2]}, nil
+	case "age":
+		return encryptedFile{ageTool, match[2]}, nil
+	default:
+		return encryptedFile{sopsTool, match[2]}, nil
+	}
+}
+
+// Resolve the data of the referenced secret, ensuring that it
+// contains all of the given keys
+func (ref Ref) Resolve(ctx context.Context, requiredKeys ...string) (map[string]string, error) {
+	provider, err := ref.Provider()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := provider.Resolve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to resolve secret %s: %v", ref, err)
+	}
+
+	for _, key := range requiredKeys {
+		if _, ok := data[key]; !ok {
+			return nil, fmt.Errorf("Secret %s is missing '%s'", ref, key)
+		}
+	}
+
+	return data, nil
+}
+
+// If this references a Kubernetes Secret, its namespace (which may
+// be empty, meaning the current namespace) and name
+func (ref Ref) KubernetesSecret() (namespace, name string, ok bool) {
+	if rest, isK8s := strings.CutPrefix(string(ref), "k8s://"); isK8s && rest != "" {
+		namespace, name = splitKubernetesRef(rest)
+		return namespace, name, true
+	}
+	return "", "", false
+}
+
+func splitKubernetesRef(ref string) (namespace, name string) {
+	if idx := strings.Index(ref, "/"); idx >= 0 {
+		return ref[:idx], ref[idx+1:]
+	}
+	return "", ref
+}
+
+// Secret values may be given as any scalar type, but we need strings
+func stringify(data map[string]any) map[string]string {
+	m := make(map[string]string)
+	for k, v := range data {
+		m[k] = fmt.Sprintf("%v", v)
+	}
+	return m
+}
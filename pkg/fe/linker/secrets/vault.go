@@ -0,0 +1,111 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A secret in a HashiCorp Vault KV secrets engine, located via the
+// standard VAULT_ADDR, VAULT_TOKEN, and VAULT_NAMESPACE environment
+// variables. This also works against `vault server -dev`.
+type vault struct {
+	// mount/path of the secret
+	path string
+}
+
+func (v vault) Resolve(ctx context.Context) (map[string]string, error) {
+	addr := strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/")
+	if addr == "" {
+		return nil, fmt.Errorf("VAULT_ADDR is not set")
+	}
+
+	token, err := vaultToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// Try KV version 2 first, which interposes "data" after the
+	// mount. A KV version 1 mount may answer this with a 403, as
+	// policies rarely grant the "data" path there, so on any
+	// failure we also try KV version 1.
+	mount, rest, _ := strings.Cut(v.path, "/")
+	data, found, kv2err := v.get(ctx, addr+"/v1/"+mount+"/data/"+rest, token)
+	if kv2err == nil && found {
+		return data, nil
+	}
+
+	if data, found, err := v.get(ctx, addr+"/v1/"+v.path, token); err != nil {
+		return nil, errors.Join(kv2err, err)
+	} else if found {
+		return data, nil
+	} else if kv2err != nil {
+		return nil, kv2err
+	}
+
+	return nil, fmt.Errorf("Vault secret %s not found", v.path)
+}
+
+func (v vault) get(ctx context.Context, url, token string) (map[string]string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, false, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, false, fmt.Errorf("Vault responded with %s", resp.Status)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, false, err
+	}
+
+	// KV version 2 nests the secret data alongside its metadata
+	if nested, ok := body.Data["data"].(map[string]any); ok {
+		if _, hasMetadata := body.Data["metadata"]; hasMetadata {
+			return stringify(nested), true, nil
+		}
+	}
+
+	return stringify(body.Data), true, nil
+}
+
+// As with the vault CLI, prefer VAULT_TOKEN, then ~/.vault-token
+func vaultToken() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	b, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+	if err != nil {
+		return "", fmt.Errorf("VAULT_TOKEN is not set, and unable to read ~/.vault-token: %v", err)
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
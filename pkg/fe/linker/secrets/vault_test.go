@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// A Vault that answers the given paths with the given status and body
+func fakeVault(t *testing.T, responses map[string]func(w http.ResponseWriter)) {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "t0ken" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if respond, ok := responses[r.URL.Path]; ok {
+			respond(w)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(s.Close)
+
+	t.Setenv("VAULT_ADDR", s.URL)
+	t.Setenv("VAULT_TOKEN", "t0ken")
+	t.Setenv("VAULT_NAMESPACE", "")
+}
+
+func respond(status int, body string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}
+}
+
+func TestVaultKV2(t *testing.T) {
+	fakeVault(t, map[string]func(http.ResponseWriter){
+		"/v1/secret/data/lunchpail/s3": respond(http.StatusOK, `{"data": {"data": {"accessKeyID": "ak"}, "metadata": {"version": 1}}}`),
+	})
+
+	data, err := vault{"secret/lunchpail/s3"}.Resolve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["accessKeyID"] != "ak" {
+		t.Fatalf("unexpected secret %v", data)
+	}
+}
+
+func TestVaultKV1AfterKV2Forbidden(t *testing.T) {
+	fakeVault(t, map[string]func(http.ResponseWriter){
+		"/v1/kv/data/lunchpail/s3": respond(http.StatusForbidden, `{"errors": ["permission denied"]}`),
+		"/v1/kv/lunchpail/s3":      respond(http.StatusOK, `{"data": {"accessKeyID": "ak"}}`),
+	})
+
+	data, err := vault{"kv/lunchpail/s3"}.Resolve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["accessKeyID"] != "ak" {
+		t.Fatalf("unexpected secret %v", data)
+	}
+}
+
+func TestVaultReportsForbiddenWhenKV1NotFound(t *testing.T) {
+	fakeVault(t, map[string]func(http.ResponseWriter){
+		"/v1/kv/data/lunchpail/s3": respond(http.StatusForbidden, `{"errors": ["permission denied"]}`),
+	})
+
+	_, err := vault{"kv/lunchpail/s3"}.Resolve(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected the KV2 403 to be reported, got %v", err)
+	}
+}
+
+func TestVaultNotFound(t *testing.T) {
+	fakeVault(t, map[string]func(http.ResponseWriter){})
+
+	_, err := vault{"kv/nope"}.Resolve(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
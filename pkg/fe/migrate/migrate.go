@@ -0,0 +1,298 @@
+package migrate
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+// A field that moves, within a resource, from one path to another
+type move struct {
+	from []string
+	to   []string
+}
+
+// How to bring each kind of resource from one apiVersion to the next
+type migration struct {
+	from  string
+	to    string
+	moves map[string][]move
+}
+
+// Oldest first. Keep these in sync with the conversions in the
+// hlir/v1beta1 package.
+var migrations = []migration{
+	{
+		from: hlir.V1alpha1,
+		to:   hlir.V1beta1,
+		moves: map[string][]move{
+			"Application": {
+				{[]string{"spec", "minMemory"}, []string{"spec", "resources", "minMemory"}},
+				{[]string{"spec", "supportsGpu"}, []string{"spec", "resources", "gpu"}},
+			},
+			"WorkerPool": {
+				{[]string{"spec", "workers", "minMemory"}, []string{"spec", "scheduling", "minMemory"}},
+			},
+			"Alert": {},
+		},
+	},
+}
+
+// Rewrite the given application YAML to the latest apiVersion,
+// preserving comments and templates. Resources that are not ours, or
+// are already up to date, are left as they are.
+func Migrate(yamls string) (migrated string, changed bool, err error) {
+	var out []string
+	for _, chunk := range split(yamls) {
+		if chunk.separator {
+			out = append(out, chunk.text)
+			continue
+		}
+
+		text, ok, err := migrateDocument(chunk.text)
+		if err != nil {
+			return "", false, err
+		}
+
+		out = append(out, text)
+		changed = changed || ok
+	}
+
+	return strings.Join(out, "\n"), changed, nil
+}
+
+type chunk struct {
+	text      string
+	separator bool
+}
+
+// Split on `---` lines, keeping the separators, so that we can rewrite
+// only the documents that need it
+func split(yamls string) []chunk {
+	chunks := []chunk{}
+	var lines []string
+	for _, line := range strings.Split(yamls, "\n") {
+		if line == "---" || strings.HasPrefix(line, "--- ") {
+			chunks = append(chunks, chunk{text: strings.Join(lines, "\n")}, chunk{text: line, separator: true})
+			lines = nil
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	return append(chunks, chunk{text: strings.Join(lines, "\n")})
+}
+
+// We edit the text of a document line by line, rather than
+// re-encoding it, so as to leave everything we don't migrate exactly
+// as it was
+func migrateDocument(text string) (string, bool, error) {
+	lines := strings.Split(text, "\n")
+
+	resource := parse(lines)
+	if resource == nil {
+		return text, false, nil
+	}
+
+	_, kind := find(resource, "kind")
+	_, apiVersion := find(resource, "apiVersion")
+	if kind == nil {
+		return text, false, nil
+	}
+
+	from := ""
+	if apiVersion != nil {
+		from = apiVersion.Value
+	}
+	version, err := hlir.ParseApiVersion(from)
+	if err != nil {
+		// e.g. a Kubernetes resource
+		return text, false, nil
+	}
+
+	changed := false
+	for _, m := range migrations {
+		moves, ok := m.moves[kind.Value]
+		if !ok || version != m.from {
+			continue
+		}
+
+		for _, mv := range moves {
+			if lines, err = apply(lines, mv); err != nil {
+				return "", false, err
+			}
+		}
+		version = m.to
+		changed = true
+	}
+
+	if !changed {
+		return text, false, nil
+	}
+
+	return strings.Join(setApiVersion(lines, version), "\n"), true, nil
+}
+
+var templateAction = regexp.MustCompile(`\{\{.*?\}\}`)
+
+// Parse the given lines into the resource they describe, if they do,
+// hiding any go/helm templates from the YAML parser. Lines that hold
+// only template actions, e.g. {{ if ... }}, become comments, so that
+// line numbers are preserved.
+func parse(lines []string) *yaml.Node {
+	masked := make([]string, len(lines))
+	for i, line := range lines {
+		if !templateAction.MatchString(line) {
+			masked[i] = line
+		} else if strings.TrimSpace(templateAction.ReplaceAllString(line, "")) == "" {
+			masked[i] = "#" + line
+		} else {
+			masked[i] = templateAction.ReplaceAllString(line, "__lunchpail_template__")
+		}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(masked, "\n")), &root); err != nil || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		// Not a resource, or not one we can parse; in either
+		// case, not one we can migrate
+		return nil
+	}
+
+	return root.Content[0]
+}
+
+// The key and value nodes for the given key of a mapping node
+func find(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// The block mapping at the given path, if there is one
+func lookup(resource *yaml.Node, path []string) *yaml.Node {
+	node := resource
+	for _, key := range path {
+		if _, child := find(node, key); child != nil && child.Kind == yaml.MappingNode && child.Style&yaml.FlowStyle == 0 {
+			node = child
+		} else {
+			return nil
+		}
+	}
+	return node
+}
+
+// Is the given value entirely on the line of its key?
+func oneLine(key, value *yaml.Node) bool {
+	return value.Kind == yaml.ScalarNode && value.Line == key.Line && value.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0
+}
+
+// Move a field, creating any mappings that its new home requires
+func apply(lines []string, mv move) ([]string, error) {
+	parentPath, field := mv.from[:len(mv.from)-1], mv.from[len(mv.from)-1]
+	destPath, name := mv.to[:len(mv.to)-1], mv.to[len(mv.to)-1]
+
+	resource := parse(lines)
+	parent := lookup(resource, parentPath)
+	if parent == nil {
+		return lines, nil
+	}
+	key, value := find(parent, field)
+	if key == nil {
+		return lines, nil
+	}
+
+	line := lines[key.Line-1]
+	col := key.Column - 1
+	if !oneLine(key, value) || !strings.HasPrefix(line[col:], field) {
+		return nil, fmt.Errorf("Unable to migrate %s at line %d; please move it to %s by hand", strings.Join(mv.from, "."), key.Line, strings.Join(mv.to, "."))
+	}
+
+	// e.g. ": 512Mi # comment"
+	rest := line[col+len(field):]
+	step := indentation(resource)
+
+	// Remove the field from where it was...
+	at := key.Line - 1
+	lines = slices.Delete(lines, at, at+1)
+	resource = parse(lines)
+
+	// ...find the deepest part of its new home that exists...
+	existing := len(destPath)
+	for lookup(resource, destPath[:existing]) == nil {
+		existing--
+	}
+	dest := lookup(resource, destPath[:existing])
+
+	if existing < len(destPath) {
+		if _, v := find(dest, destPath[existing]); v != nil {
+			// e.g. resources: {} or resources: 3
+			return nil, fmt.Errorf("Unable to migrate %s, as %s is not a block mapping; please move it by hand", strings.Join(mv.from, "."), strings.Join(destPath[:existing+1], "."))
+		}
+	}
+
+	// If its new home is a new mapping alongside where it was, put
+	// it where it was
+	indent := col
+	if !slices.Equal(destPath[:existing], parentPath) {
+		at, indent = insertionPoint(dest, col)
+	}
+
+	// ...and put it there, along with any mappings it needs
+	insert := []string{}
+	for _, k := range destPath[existing:] {
+		insert = append(insert, strings.Repeat(" ", indent)+k+":")
+		indent += step
+	}
+	insert = append(insert, strings.Repeat(" ", indent)+name+rest)
+
+	return slices.Insert(lines, at, insert...), nil
+}
+
+// Where to insert a new child of the given mapping: after its last
+// child, if that fits on one line, otherwise before its first
+func insertionPoint(mapping *yaml.Node, fallbackIndent int) (at int, indent int) {
+	if len(mapping.Content) < 2 {
+		return mapping.Line, fallbackIndent
+	}
+
+	lastKey, lastValue := mapping.Content[len(mapping.Content)-2], mapping.Content[len(mapping.Content)-1]
+	if oneLine(lastKey, lastValue) {
+		return lastKey.Line, lastKey.Column - 1
+	}
+
+	first := mapping.Content[0]
+	return first.Line - 1, first.Column - 1
+}
+
+// How far this document indents each level of nesting
+func indentation(resource *yaml.Node) int {
+	for i := 0; i+1 < len(resource.Content); i += 2 {
+		key, value := resource.Content[i], resource.Content[i+1]
+		if value.Kind == yaml.MappingNode && len(value.Content) > 0 && value.Content[0].Line > key.Line {
+			return value.Content[0].Column - key.Column
+		}
+	}
+	return 2
+}
+
+func setApiVersion(lines []string, version string) []string {
+	resource := parse(lines)
+	key, value := find(resource, "apiVersion")
+	if key == nil {
+		first := resource.Content[0]
+		return slices.Insert(lines, first.Line-1, strings.Repeat(" ", first.Column-1)+"apiVersion: "+version)
+	}
+
+	line := lines[value.Line-1]
+	col := value.Column - 1
+	lines[value.Line-1] = line[:col] + strings.Replace(line[col:], value.Value, version, 1)
+	return lines
+}
@@ -7,11 +7,19 @@ import (
 	"io"
 	"lunchpail.io/pkg/defaults/application"
 	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/hlir/v1beta1"
 	"os"
 	"strings"
 )
 
-func Parse(yamls string) (hlir.HLIR, error) {
+// Parse the given application YAML into the HLIR, after validating
+// it. If strict, unknown fields and kinds are errors rather than
+// warnings.
+func Parse(yamls string, strict bool) (hlir.HLIR, error) {
+	if err := check(yamls, strict); err != nil {
+		return hlir.HLIR{}, err
+	}
+
 	model := hlir.HLIR{}
 	d := yaml.NewDecoder(strings.NewReader(yamls))
 
@@ -37,10 +45,14 @@ func Parse(yamls string) (hlir.HLIR, error) {
 			continue
 		}
 
+		// The validation in check() has already rejected any
+		// unsupported apiVersion
+		apiVersion, _ := m["apiVersion"].(string)
+		version, _ := hlir.ParseApiVersion(apiVersion)
+
 		switch kind {
 		case "Application":
-			var r hlir.Application
-			if err := yaml.Unmarshal(bytes, &r); err != nil {
+			if r, err := decodeApplication(bytes, version); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: skipping yaml with invalid Application resource %v", err)
 				continue
 			} else {
@@ -48,14 +60,22 @@ func Parse(yamls string) (hlir.HLIR, error) {
 			}
 
 		case "WorkerPool":
-			var r hlir.WorkerPool
-			if err := yaml.Unmarshal(bytes, &r); err != nil {
+			if r, err := decodeWorkerPool(bytes, version); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: skipping yaml with invalid WorkerPool resource %v\n!!!!\n%s\n!!!!\n", err, string(bytes))
 				continue
 			} else {
 				model.WorkerPools = append(model.WorkerPools, r)
 			}
 
+		case "Alert":
+			var r hlir.Alert
+			if err := yaml.Unmarshal(bytes, &r); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: skipping yaml with invalid Alert resource %v\n", err)
+				continue
+			} else {
+				model.Alerts = append(model.Alerts, r)
+			}
+
 		default:
 			model.Others = append(model.Others, m)
 		}
@@ -64,6 +84,51 @@ func Parse(yamls string) (hlir.HLIR, error) {
 	return model, nil
 }
 
+// Decode an Application of the given apiVersion, converting it to the
+// HLIR's representation
+func decodeApplication(bytes []byte, version string) (hlir.Application, error) {
+	if version == v1beta1.ApiVersion {
+		var r v1beta1.Application
+		err := yaml.Unmarshal(bytes, &r)
+		return r.ToV1alpha1(), err
+	}
+
+	var r hlir.Application
+	err := yaml.Unmarshal(bytes, &r)
+	return r, err
+}
+
+// Decode a WorkerPool of the given apiVersion, converting it to the
+// HLIR's representation
+func decodeWorkerPool(bytes []byte, version string) (hlir.WorkerPool, error) {
+	if version == v1beta1.ApiVersion {
+		var r v1beta1.WorkerPool
+		err := yaml.Unmarshal(bytes, &r)
+		return r.ToV1alpha1(), err
+	}
+
+	var r hlir.WorkerPool
+	err := yaml.Unmarshal(bytes, &r)
+	return r, err
+}
+
+// Report warnings, and fail on any errors
+func check(yamls string, strict bool) error {
+	errs := []string{}
+	for _, p := range Validate(yamls, "", strict) {
+		if p.IsError {
+			errs = append(errs, "  "+p.Where()+": "+p.What())
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", p.Where(), p.What())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Invalid application YAML:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
 func stringVal(key string, m hlir.UnknownResource) (string, error) {
 	uval, ok := m[key]
 	if !ok {
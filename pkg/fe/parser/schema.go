@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/hlir/v1alpha1"
+	"lunchpail.io/pkg/ir/hlir/v1beta1"
+)
+
+// The kinds of resource that have a published schema, by apiVersion
+var schemaTypes = map[string]map[string]reflect.Type{
+	v1alpha1.ApiVersion: {
+		"Application": reflect.TypeOf(v1alpha1.Application{}),
+		"WorkerPool":  reflect.TypeOf(v1alpha1.WorkerPool{}),
+	},
+	v1beta1.ApiVersion: {
+		"Application": reflect.TypeOf(v1beta1.Application{}),
+		"WorkerPool":  reflect.TypeOf(v1beta1.WorkerPool{}),
+	},
+}
+
+// String-valued types that accept only certain values
+var enums = map[reflect.Type][]string{
+	reflect.TypeOf(hlir.CallingConvention("")): {string(hlir.CallingConventionFiles), hlir.CallingConventionStdio},
+	reflect.TypeOf(hlir.Role("")):              hlir.Roles,
+}
+
+// The kinds of resource that have a published schema in the latest
+// apiVersion
+func SchemaKinds() []string {
+	kinds := make([]string, 0, len(schemaTypes[hlir.LatestApiVersion]))
+	for kind := range schemaTypes[hlir.LatestApiVersion] {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// The JSON Schema for the given apiVersion and kind of resource. This
+// is strict, in that it disallows fields that the HLIR does not know
+// about.
+func Schema(apiVersion, kind string) (map[string]any, error) {
+	version, err := hlir.ParseApiVersion(apiVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := schemaTypes[version][kind]
+	if !ok {
+		return nil, fmt.Errorf("No schema for kind %s, expected one of %s", kind, strings.Join(SchemaKinds(), ", "))
+	}
+
+	schema := schemaFor(t)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = kind + " " + version
+	schema["required"] = []string{"apiVersion", "kind", "metadata"}
+
+	properties := schema["properties"].(map[string]any)
+	properties["apiVersion"] = map[string]any{"enum": apiVersionSpellings(version)}
+	properties["kind"] = map[string]any{"const": kind}
+	properties["metadata"].(map[string]any)["required"] = []string{"name"}
+
+	return schema, nil
+}
+
+// The ways of writing the given apiVersion that ParseApiVersion()
+// accepts
+func apiVersionSpellings(version string) []string {
+	spellings := []string{version}
+	for _, alias := range []string{"v1alpha1"} {
+		if v, err := hlir.ParseApiVersion(alias); err == nil && v == version {
+			spellings = append(spellings, alias)
+		}
+	}
+	return spellings
+}
+
+// The JSON Schema for the given apiVersion and kind of resource, as
+// JSON
+func SchemaJson(apiVersion, kind string) ([]byte, error) {
+	schema, err := Schema(apiVersion, kind)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// Follow the yaml.v3 conventions for how Go types appear in YAML
+func schemaFor(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.String:
+		if values, ok := enums[t]; ok {
+			return map[string]any{"type": "string", "enum": values}
+		}
+		// yaml.v3 will happily decode any scalar into a string
+		return map[string]any{"type": []string{"string", "number", "boolean"}}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]any{}
+		addProperties(t, properties)
+		return map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	}
+
+	// e.g. interface{}, which may be anything
+	return map[string]any{}
+}
+
+func addProperties(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, flags, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		} else if slices.Contains(strings.Split(flags, ","), "inline") {
+			addProperties(field.Type, properties)
+			continue
+		} else if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		properties[name] = schemaFor(field.Type)
+	}
+}
@@ -0,0 +1,324 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+// Something amiss with an application YAML document
+type Problem struct {
+	// The file the document came from, if known
+	Source string
+
+	// Counting from 1, within Source if known
+	Line   int
+	Column int
+
+	// e.g. spec.workers.count
+	Field   string
+	Message string
+
+	// Otherwise, the resource would still be used, just perhaps not
+	// as the author intended
+	IsError bool
+}
+
+// e.g. app.yaml:12:7
+func (p Problem) Where() string {
+	where := fmt.Sprintf("line %d", p.Line)
+	if p.Source != "" {
+		where = fmt.Sprintf("%s:%d", p.Source, p.Line)
+	}
+	if p.Column > 0 {
+		where = fmt.Sprintf("%s:%d", where, p.Column)
+	}
+	return where
+}
+
+// e.g. spec.workers.count: Invalid type. Expected: integer, given: string
+func (p Problem) What() string {
+	if p.Field == "" {
+		return p.Message
+	}
+	return p.Field + ": " + p.Message
+}
+
+func (p Problem) String() string {
+	level := "warning"
+	if p.IsError {
+		level = "error"
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Where(), level, p.What())
+}
+
+// The kinds that parse() understands, whether or not they have a
+// published schema
+var knownKinds = []string{"Application", "WorkerPool", "Alert"}
+
+// Resources with this apiVersion prefix are ours, as opposed to
+// Kubernetes resources that we pass through to the cluster
+const lunchpailApiGroup = "lunchpail.io/"
+
+// Check the given (possibly multi-document) application YAML against
+// the schemas of the resources it contains. Unknown fields and kinds
+// are errors if strict, otherwise warnings. Other problems, e.g. values
+// of the wrong type, leave the resource undecodable, and so are always
+// errors. The source names the file the YAML came from, if any;
+// `# Source:` comments, as emitted by helm template, take precedence.
+func Validate(yamls, source string, strict bool) []Problem {
+	problems := []Problem{}
+	for _, doc := range splitDocuments(yamls, source) {
+		problems = append(problems, doc.validate(strict)...)
+	}
+	return problems
+}
+
+// One document of a multi-document YAML stream
+type document struct {
+	text   string
+	source string
+
+	// Add this to a line number within text to get a line number
+	// within source
+	offset int
+}
+
+// Split on `---` lines, so that we can report line numbers relative
+// to the file each document came from
+func splitDocuments(yamls, source string) []document {
+	docs := []document{}
+	doc := document{source: source}
+	var lines []string
+
+	flush := func() {
+		doc.text = strings.Join(lines, "\n")
+		docs = append(docs, doc)
+		lines = nil
+	}
+
+	for idx, line := range strings.Split(yamls, "\n") {
+		if line == "---" || strings.HasPrefix(line, "--- ") {
+			flush()
+			doc = document{source: source, offset: idx + 1}
+			continue
+		}
+
+		if after, ok := strings.CutPrefix(line, "# Source: "); ok && len(lines) == 0 {
+			// helm template output; the following lines are
+			// (the rendering of) line 1 and on of this file
+			doc.source = helmSource(after)
+			doc.offset = -1
+		}
+
+		lines = append(lines, line)
+	}
+	flush()
+
+	return docs
+}
+
+// Strip the chart and template directories, which are of no interest
+// to the author of the application
+func helmSource(path string) string {
+	if _, after, ok := strings.Cut(path, "/__embededapp__/"); ok {
+		return after
+	} else if _, after, ok := strings.Cut(path, "/templates/"); ok {
+		return after
+	}
+	return path
+}
+
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func (doc document) validate(strict bool) []Problem {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(doc.text), &root); err != nil {
+		p := doc.problem(nil, "", err.Error(), true)
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			p.Line = line + doc.offset
+			p.Message = m[2]
+		}
+		return []Problem{p}
+	} else if len(root.Content) == 0 {
+		// e.g. a template that rendered to nothing
+		return nil
+	}
+
+	w := walker{positions: map[string]*yaml.Node{}}
+	value := w.value(root.Content[0], "")
+	resource, ok := value.(map[string]any)
+	if !ok {
+		return []Problem{doc.problem(root.Content[0], "", "Expected a resource, i.e. a mapping with apiVersion and kind", true)}
+	}
+
+	kind, _ := resource["kind"].(string)
+	apiVersion, _ := resource["apiVersion"].(string)
+
+	switch {
+	case kind == "":
+		return []Problem{doc.problem(root.Content[0], "", "Missing kind", strict)}
+	case !slices.Contains(knownKinds, kind) && strings.HasPrefix(apiVersion, lunchpailApiGroup):
+		return []Problem{doc.problem(w.positions["kind"], "kind", fmt.Sprintf("Unknown kind %s, expected one of %s", kind, strings.Join(knownKinds, ", ")), strict)}
+	case !slices.Contains(knownKinds, kind):
+		// Not ours to validate, e.g. a Kubernetes resource
+		return nil
+	}
+
+	version, err := hlir.ParseApiVersion(apiVersion)
+	if err != nil {
+		return []Problem{doc.problem(w.find("apiVersion"), "apiVersion", err.Error(), true)}
+	}
+
+	schema, err := compiledSchema(version, kind)
+	if err != nil || schema == nil {
+		// e.g. an Alert, which has no published schema
+		return nil
+	}
+
+	result, err := schema.Validate(gojsonschema.NewGoLoader(resource))
+	if err != nil {
+		return []Problem{doc.problem(root.Content[0], "", err.Error(), true)}
+	}
+
+	problems := []Problem{}
+	for _, e := range result.Errors() {
+		field := e.Field()
+		if field == gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+			field = ""
+		}
+
+		isError := true
+		where := field
+		switch e.Type() {
+		case "additional_property_not_allowed":
+			// Point at the unknown field, rather than its parent
+			where = join(field, fmt.Sprintf("%v", e.Details()["property"]))
+			field = where
+			isError = strict
+		}
+
+		// Some descriptions lead with the field, which we report separately
+		message := strings.TrimPrefix(e.Description(), field+" ")
+		problems = append(problems, doc.problem(w.find(where), field, message, isError))
+	}
+
+	slices.SortStableFunc(problems, func(a, b Problem) int { return a.Line - b.Line })
+	return problems
+}
+
+func (doc document) problem(node *yaml.Node, field, message string, isError bool) Problem {
+	p := Problem{Source: doc.source, Field: field, Message: message, IsError: isError}
+	if node != nil {
+		p.Line = node.Line + doc.offset
+		p.Column = node.Column
+	}
+	return p
+}
+
+// The compiled schema for each apiVersion and kind
+var compiledSchemas = map[string]*gojsonschema.Schema{}
+
+// The compiled schema for the given apiVersion and kind; nil for kinds
+// without a schema
+func compiledSchema(version, kind string) (*gojsonschema.Schema, error) {
+	key := version + "/" + kind
+	if schema, ok := compiledSchemas[key]; ok {
+		return schema, nil
+	} else if _, ok := schemaTypes[version][kind]; !ok {
+		return nil, nil
+	}
+
+	s, err := Schema(version, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(s))
+	if err != nil {
+		return nil, err
+	}
+
+	compiledSchemas[key] = schema
+	return schema, nil
+}
+
+// Converts a yaml.Node into the generic form that the schema validator
+// expects, remembering where each field came from
+type walker struct {
+	// Keyed by dotted path, e.g. spec.code.0.name, as reported by
+	// the schema validator
+	positions map[string]*yaml.Node
+}
+
+func (w walker) value(node *yaml.Node, path string) any {
+	if path == "" {
+		w.positions[path] = node
+	}
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		return w.value(node.Alias, path)
+
+	case yaml.MappingNode:
+		m := map[string]any{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			child := join(path, key.Value)
+			w.positions[child] = key
+
+			// yaml.v3 decodes a null into the zero value,
+			// so we don't hold it against the schema
+			if val.Tag != "!!null" {
+				m[key.Value] = w.value(val, child)
+			}
+		}
+		return m
+
+	case yaml.SequenceNode:
+		s := make([]any, 0, len(node.Content))
+		for i, item := range node.Content {
+			child := join(path, strconv.Itoa(i))
+			w.positions[child] = item
+			s = append(s, w.value(item, child))
+		}
+		return s
+	}
+
+	var v any
+	if err := node.Decode(&v); err != nil {
+		return node.Value
+	}
+	return v
+}
+
+// The node for the given path, or for its closest ancestor that we
+// know about
+func (w walker) find(path string) *yaml.Node {
+	for {
+		if node, ok := w.positions[path]; ok {
+			return node
+		}
+
+		idx := strings.LastIndex(path, ".")
+		if idx < 0 {
+			return w.positions[""]
+		}
+		path = path[:idx]
+	}
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const pool = `apiVersion: lunchpail.io/v1alpha1
+kind: WorkerPool
+metadata:
+  name: p
+spec:
+  workers:
+    count: %s
+    %s
+`
+
+func workerPool(count, extra string) string {
+	return fmt.Sprintf(pool, count, extra)
+}
+
+func TestValidateTestApps(t *testing.T) {
+	paths, err := filepath.Glob("../../../tests/tests/*/pail/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skip("no test apps found")
+	}
+
+	for _, path := range paths {
+		if filepath.Base(path) == "values.yaml" {
+			// helm values, not a resource
+			continue
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), "{{") {
+			// a helm template, which only validates once rendered
+			continue
+		}
+		for _, p := range Validate(string(b), path, true) {
+			t.Errorf("%s", p)
+		}
+	}
+}
+
+func TestValidateWorkerSize(t *testing.T) {
+	for _, size := range []string{"auto", "xxs"} {
+		if problems := Validate(workerPool("2", "size: "+size), "", true); len(problems) != 0 {
+			t.Errorf("size %s: unexpected problems %v", size, problems)
+		}
+	}
+}
+
+func TestValidateTypeError(t *testing.T) {
+	yamls := workerPool(`"two"`, "")
+
+	for _, strict := range []bool{false, true} {
+		problems := Validate(yamls, "pool.yaml", strict)
+		if len(problems) != 1 {
+			t.Fatalf("strict=%v: expected one problem, got %v", strict, problems)
+		}
+		p := problems[0]
+		if !p.IsError {
+			t.Errorf("strict=%v: expected an error: %s", strict, p)
+		}
+		if p.Field != "spec.workers.count" || p.Line != 7 {
+			t.Errorf("strict=%v: expected spec.workers.count at line 7: %s", strict, p)
+		}
+	}
+
+	for _, strict := range []bool{false, true} {
+		if _, err := Parse(yamls, strict); err == nil {
+			t.Errorf("strict=%v: expected the parse to fail", strict)
+		}
+	}
+}
+
+func TestValidateUnknownField(t *testing.T) {
+	yamls := workerPool("2", "colour: blue")
+
+	for _, strict := range []bool{false, true} {
+		problems := Validate(yamls, "pool.yaml", strict)
+		if len(problems) != 1 {
+			t.Fatalf("strict=%v: expected one problem, got %v", strict, problems)
+		}
+		p := problems[0]
+		if p.IsError != strict || p.Field != "spec.workers.colour" || p.Line != 8 {
+			t.Errorf("strict=%v: unexpected problem %s", strict, p)
+		}
+		if p.Where() != "pool.yaml:8:5" {
+			t.Errorf("strict=%v: unexpected location %s", strict, p.Where())
+		}
+	}
+}
+
+func TestValidateSyntaxError(t *testing.T) {
+	problems := Validate("apiVersion: lunchpail.io/v1alpha1\nkind: [WorkerPool\n", "", false)
+	if len(problems) != 1 || !problems[0].IsError {
+		t.Fatalf("expected one error, got %v", problems)
+	}
+}
+
+func TestValidateDocumentLines(t *testing.T) {
+	yamls := "apiVersion: v1\nkind: ConfigMap\n---\n" + workerPool("2", "colour: blue")
+	problems := Validate(yamls, "", false)
+	if len(problems) != 1 || problems[0].Line != 11 {
+		t.Fatalf("expected one problem at line 11, got %v", problems)
+	}
+}
@@ -1,13 +1,16 @@
 package fe
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"lunchpail.io/pkg/be/helm"
+	"lunchpail.io/pkg/be/target"
 	"lunchpail.io/pkg/build"
 	"lunchpail.io/pkg/fe/linker"
 	q "lunchpail.io/pkg/fe/linker/queue"
+	"lunchpail.io/pkg/fe/linker/secrets"
 	"lunchpail.io/pkg/fe/parser"
 	"lunchpail.io/pkg/fe/transformer"
 	"lunchpail.io/pkg/ir/hlir"
@@ -49,10 +52,36 @@ func prepareHLIR(run queue.RunContext, popts PrepareOptions, opts build.Options)
 		}
 	}
 
+	ir, err := instantiate(build.Name(), run.RunName, templatePath, opts)
+	return ir, run, err
+}
+
+// Return the HLIR of this application, as a run with the given
+// options would see it
+func PrepareHLIR(opts build.Options) (hlir.HLIR, error) {
+	templatePath, err := build.StageForRun(build.StageOptions{Verbose: opts.Log.Verbose})
+	if err != nil {
+		return hlir.HLIR{}, err
+	}
+
+	return instantiate(build.Name(), build.Name(), templatePath, opts)
+}
+
+// Return the HLIR of the application template staged (as by
+// build.StageForRun) in the given directory
+func PrepareHLIRFromTemplate(appname, templatePath string, opts build.Options) (hlir.HLIR, error) {
+	return instantiate(appname, appname, templatePath, opts)
+}
+
+// Instantiate the given staged application template, and parse the
+// result. Unless verbose, this consumes the stage directory.
+func instantiate(appname, runname, templatePath string, opts build.Options) (hlir.HLIR, error) {
+	verbose := opts.Log.Verbose
+
 	// Set up values that will be given to the application YAML
-	yamlValues, err := linker.Configure(build.Name(), run.RunName, opts)
+	yamlValues, err := linker.Configure(appname, runname, opts)
 	if err != nil {
-		return hlir.HLIR{}, run, err
+		return hlir.HLIR{}, err
 	}
 
 	if !verbose {
@@ -63,20 +92,15 @@ func prepareHLIR(run queue.RunContext, popts PrepareOptions, opts build.Options)
 	}
 
 	// Instantiate the application's templates. We allow application YAML to have go/helm templates.
-	yaml, err := helm.Template(run.RunName, "", templatePath, yamlValues, helm.TemplateOptions{OverrideValues: opts.OverrideValues, OverrideFileValues: opts.OverrideFileValues, Verbose: verbose})
+	yaml, err := helm.Template(runname, "", templatePath, yamlValues, helm.TemplateOptions{OverrideValues: opts.OverrideValues, OverrideFileValues: opts.OverrideFileValues, Verbose: verbose})
 	if err != nil {
-		return hlir.HLIR{}, run, err
+		return hlir.HLIR{}, err
 	}
 
 	// Now that we're instantiated any templates, we can parse the
 	// application YAML. We parse into the high-level intermediate
 	// representation (HLIR).
-	ir, err := parser.Parse(yaml)
-	if err != nil {
-		return hlir.HLIR{}, run, err
-	}
-
-	return ir, run, nil
+	return parser.Parse(yaml, opts.Strict)
 }
 
 func PrepareHLIRForRun(ir hlir.HLIR, ctx llir.Context, popts PrepareOptions, opts build.Options) (llir.LLIR, error) {
@@ -91,11 +115,26 @@ func PrepareHLIRForRun(ir hlir.HLIR, ctx llir.Context, popts PrepareOptions, opt
 		}
 	}
 
-	if opts.Queue != "" || ctx.Queue.Endpoint == "" {
+	if opts.Queue != "" || opts.QueueSecret != "" || ctx.Queue.Endpoint == "" {
 		spec, err := q.ParseFlag(opts.Queue, ctx.Run.RunName)
 		if err != nil {
 			return llir.LLIR{}, err
 		}
+
+		if opts.QueueSecret != "" {
+			// On Kubernetes, we can consume a Kubernetes Secret by reference
+			byReferenceIn := ""
+			if opts.Target != nil && opts.Target.Platform == target.Kubernetes {
+				byReferenceIn = opts.Target.Namespace
+				if byReferenceIn == "" {
+					byReferenceIn = "default"
+				}
+			}
+
+			if spec, err = q.WithSecret(context.Background(), spec, secrets.Ref(opts.QueueSecret), byReferenceIn); err != nil {
+				return llir.LLIR{}, err
+			}
+		}
 		ctx.Queue = spec
 
 		r := ctx.Run
@@ -0,0 +1,147 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+type Options struct {
+	Template
+	hlir.CallingConvention
+	Verbose bool
+}
+
+// What the README says about a scaffolded application
+type readmeValues struct {
+	Name                  string
+	Main                  string
+	CallingConventionHelp string
+	TestDataHelp          string
+	RequirementsHelp      string
+	CallingConvention     hlir.CallingConvention
+}
+
+// Generate, in the given directory, a new application that follows
+// the conventions of `lunchpail build`
+func Init(dir string, opts Options) error {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("Directory %s already exists and is not empty", dir)
+	}
+
+	if opts.Template == "" {
+		opts.Template = Python
+	}
+	if opts.CallingConvention == "" {
+		opts.CallingConvention = hlir.CallingConventionFiles
+	}
+
+	name := filepath.Base(dir)
+	if abs, err := filepath.Abs(dir); err == nil {
+		name = filepath.Base(abs)
+	}
+
+	f, err := filesFor(name, opts)
+	if err != nil {
+		return err
+	}
+
+	for path, content := range f {
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "Writing %s\n", filepath.Join(dir, path))
+		}
+
+		mode := os.FileMode(0644)
+		if filepath.Ext(path) == ".sh" {
+			mode = 0755
+		}
+
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755); err != nil {
+			return err
+		} else if err := os.WriteFile(filepath.Join(dir, path), []byte(content), mode); err != nil {
+			return err
+		}
+	}
+
+	// Parquet test data cannot be conjured up, so leave places for it
+	for _, sub := range []string{"input", "expected"} {
+		if err := os.MkdirAll(filepath.Join(dir, "test-data", sub), 0755); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Created %s application in %s. Next, try: lunchpail build -o %s %s\n", opts.Template, dir, name, dir)
+	return nil
+}
+
+func filesFor(name string, opts Options) (files, error) {
+	stdio := opts.CallingConvention == hlir.CallingConventionStdio
+
+	var main, source, memory, requirements string
+	switch opts.Template {
+	case Shell:
+		main, memory = "main.sh", "512Mi"
+		source = pick(stdio, shellStdio, shellFiles)
+	case ParquetTransform:
+		main, memory, requirements = "main.py", "2Gi", "pyarrow\n"
+		source = pick(stdio, parquetStdio, parquetFiles)
+	default:
+		main, memory, requirements = "main.py", "512Mi", pythonRequirements
+		source = pick(stdio, pythonStdio, pythonFiles)
+	}
+
+	f := files{
+		"src/" + main:        source,
+		"memory":             memory + "\n",
+		"calling-convention": string(opts.CallingConvention) + "\n",
+	}
+
+	requirementsHelp := ""
+	if requirements != "" {
+		f["requirements.txt"] = requirements
+		requirementsHelp = "- requirements.txt: the Python packages the logic needs.\n"
+	}
+
+	testDataHelp := ""
+	if opts.Template == ParquetTransform {
+		testDataHelp = " Add sample .parquet files here, along with the output you expect."
+	} else {
+		f["test-data/input/hello.txt"] = "hello world\n"
+		f["test-data/expected/hello.txt"] = "HELLO WORLD\n"
+	}
+
+	ccHelp := pick(stdio, "The logic reads each task from stdin and writes its output to stdout.", "The logic is given the path of each task and the path to write its output to.")
+
+	text, err := render(readme, readmeValues{name, main, ccHelp, testDataHelp, requirementsHelp, opts.CallingConvention})
+	if err != nil {
+		return nil, err
+	}
+	f["README.md"] = text
+
+	return f, nil
+}
+
+// Render the given template, failing if it refers to a value we do not have
+func render(tmpl string, values readmeValues) (string, error) {
+	t, err := template.New("README.md").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, values); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func pick(stdio bool, ifStdio, otherwise string) string {
+	if stdio {
+		return ifStdio
+	}
+	return otherwise
+}
@@ -0,0 +1,133 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lunchpail.io/pkg/fe/builder/overlay"
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+func TestInit(t *testing.T) {
+	for _, template := range templates {
+		for _, cc := range []hlir.CallingConvention{hlir.CallingConventionFiles, hlir.CallingConventionStdio} {
+			t.Run(string(template)+"-"+string(cc), func(t *testing.T) {
+				dir := filepath.Join(t.TempDir(), "myapp")
+				if err := Init(dir, Options{Template: template, CallingConvention: cc}); err != nil {
+					t.Fatal(err)
+				}
+
+				main := "src/main.py"
+				if template == Shell {
+					main = "src/main.sh"
+				}
+				for _, path := range []string{main, "memory", "calling-convention", "README.md", "test-data/input", "test-data/expected"} {
+					if _, err := os.Stat(filepath.Join(dir, path)); err != nil {
+						t.Errorf("expected %s: %v", path, err)
+					}
+				}
+
+				if b, err := os.ReadFile(filepath.Join(dir, "calling-convention")); err != nil || strings.TrimSpace(string(b)) != string(cc) {
+					t.Errorf("expected calling convention %s, got %q (error %v)", cc, b, err)
+				}
+
+				readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, want := range []string{"# myapp", "lunchpail build -o myapp .", "./myapp test", main + ":", "(here, " + string(cc) + ")"} {
+					if !strings.Contains(string(readme), want) {
+						t.Errorf("expected the README to mention %q, got\n%s", want, readme)
+					}
+				}
+
+				// A fresh application should pass its own lint
+				findings, err := overlay.Lint(dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, f := range findings {
+					if f.IsError {
+						t.Errorf("expected no lint errors, got %s", f)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestInitDefaults(t *testing.T) {
+	dir := t.TempDir()
+	if err := Init(dir, Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "src/main.py")); err != nil {
+		t.Errorf("expected a python application by default: %v", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "calling-convention")); err != nil || strings.TrimSpace(string(b)) != string(hlir.CallingConventionFiles) {
+		t.Errorf("expected the files calling convention by default, got %q (error %v)", b, err)
+	}
+}
+
+func TestInitRefusesNonEmptyDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "precious"), []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Init(dir, Options{}); err == nil {
+		t.Fatal("expected an error for a non-empty directory")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src")); err == nil {
+		t.Fatal("expected nothing to be written to a non-empty directory")
+	}
+}
+
+func TestRenderMissingKey(t *testing.T) {
+	if _, err := render("# {{.Name}} by {{.Author}}", readmeValues{Name: "myapp"}); err == nil {
+		t.Fatal("expected an error for a value we do not have")
+	}
+}
+
+func TestLintFindsScaffoldingMistakes(t *testing.T) {
+	tests := []struct {
+		name   string
+		mangle func(dir string) error
+		path   string
+	}{
+		{"missing src", func(dir string) error { return os.RemoveAll(filepath.Join(dir, "src")) }, "src"},
+		{"misnamed src", func(dir string) error { return os.Rename(filepath.Join(dir, "src"), filepath.Join(dir, "source")) }, "source"},
+		{"bad memory", func(dir string) error { return os.WriteFile(filepath.Join(dir, "memory"), []byte("lots\n"), 0644) }, "memory"},
+		{"bad calling convention", func(dir string) error {
+			return os.WriteFile(filepath.Join(dir, "calling-convention"), []byte("carrier-pigeon\n"), 0644)
+		}, "calling-convention"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := Init(dir, Options{}); err != nil {
+				t.Fatal(err)
+			} else if err := tt.mangle(dir); err != nil {
+				t.Fatal(err)
+			}
+
+			findings, err := overlay.Lint(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			found := false
+			for _, f := range findings {
+				if strings.HasPrefix(f.Path, tt.path) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a finding about %s, got %v", tt.path, findings)
+			}
+		})
+	}
+}
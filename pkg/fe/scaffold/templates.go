@@ -0,0 +1,134 @@
+package scaffold
+
+import "fmt"
+
+type Template string
+
+const (
+	Python           Template = "python"
+	Shell            Template = "shell"
+	ParquetTransform Template = "parquet-transform"
+)
+
+var templates = []Template{Python, Shell, ParquetTransform}
+
+// String is used both by fmt.Print and by Cobra in help text
+func (t *Template) String() string {
+	return string(*t)
+}
+
+// Set must have pointer receiver so it doesn't change the value of a copy
+func (t *Template) Set(v string) error {
+	for _, template := range templates {
+		if string(template) == v {
+			*t = template
+			return nil
+		}
+	}
+	return fmt.Errorf("Unsupported template %s", v)
+}
+
+// Type is only used in help text
+func (t *Template) Type() string {
+	return "Template"
+}
+
+// The files of a scaffolded application, keyed by path
+type files map[string]string
+
+const pythonFiles = `import sys
+
+# $1 input filepath
+# $2 output filepath
+input = sys.argv[1]
+output = sys.argv[2]
+
+with open(input) as f:
+    content = f.read()
+
+with open(output, "w") as f:
+    f.write(content.upper())
+`
+
+const pythonStdio = `import sys
+
+# Each task arrives on stdin, and what we write to stdout is its output
+sys.stdout.write(sys.stdin.read().upper())
+`
+
+const shellFiles = `#!/bin/sh
+
+# $1 input filepath
+# $2 output filepath
+tr '[:lower:]' '[:upper:]' < "$1" > "$2"
+`
+
+const shellStdio = `#!/bin/sh
+
+# Each task arrives on stdin, and what we write to stdout is its output
+tr '[:lower:]' '[:upper:]'
+`
+
+const parquetFiles = `import sys
+import pyarrow.parquet as pq
+
+# $1 input filepath
+# $2 output filepath
+input = sys.argv[1]
+output = sys.argv[2]
+
+table = pq.read_table(input)
+
+# Transform the table here
+print(f"Read {table.num_rows} rows from {input}")
+
+pq.write_table(table, output)
+`
+
+const parquetStdio = `import sys
+import pyarrow as pa
+import pyarrow.parquet as pq
+
+# Each task arrives on stdin, and what we write to stdout is its output
+table = pq.read_table(pa.BufferReader(sys.stdin.buffer.read()))
+
+# Transform the table here. Note: log to stderr, as stdout is the output
+print(f"Read {table.num_rows} rows", file=sys.stderr)
+
+sink = pa.BufferOutputStream()
+pq.write_table(table, sink)
+sys.stdout.buffer.write(sink.getvalue().to_pybytes())
+`
+
+const pythonRequirements = `# One requirement per line, e.g.
+# numpy==2.1.0
+`
+
+// The README of a scaffolded application, rendered with readmeValues
+const readme = `# {{.Name}}
+
+A Lunchpail application, built via:
+
+` + "```shell" + `
+lunchpail build -o {{.Name}} .
+` + "```" + `
+
+## Layout
+
+- src/{{.Main}}: the logic, run once per task. {{.CallingConventionHelp}}
+- test-data/input: sample tasks. Each is paired with the file of the same name (or with a .gz extension, or numbered _0, _1, ...) in test-data/expected.{{.TestDataHelp}}
+{{.RequirementsHelp}}- memory: how much memory each worker needs, e.g. 512Mi or 2Gi.
+- calling-convention: how the logic receives each task, either files or stdio (here, {{.CallingConvention}}).
+
+To run the tests:
+
+` + "```shell" + `
+./{{.Name}} test
+` + "```" + `
+
+To check this directory against the conventions of lunchpail build:
+
+` + "```shell" + `
+lunchpail dev lint .
+` + "```" + `
+`
@@ -0,0 +1,25 @@
+package scaffold
+
+import "testing"
+
+func TestSetTemplate(t *testing.T) {
+	for _, want := range templates {
+		var got Template
+		if err := got.Set(string(want)); err != nil || got != want {
+			t.Errorf("expected %s, got %s (error %v)", want, got, err)
+		}
+	}
+
+	var got Template
+	if err := got.Set("cobol"); err == nil {
+		t.Fatal("expected an error for an unsupported template")
+	}
+}
+
+func TestTemplatesAreTyped(t *testing.T) {
+	for _, template := range []any{Python, Shell, ParquetTransform} {
+		if _, ok := template.(Template); !ok {
+			t.Errorf("expected %v to be a Template, got %T", template, template)
+		}
+	}
+}
@@ -52,8 +52,9 @@ func LowerAsComponent(buildName string, ctx llir.Context, app hlir.Application,
 	if opts.AutoClean {
 		// We try to be cautious here, given the rm -rf. Only
 		// do so if we find the requirements.txt that is
-		// stashed by needs/install_requirements.
-		clean = `trap "if [[ -n "$venvBin" ]] && [[ -f "$venvBin/../requirements.txt" ]]; then echo 'Cleaning up venv $(dirname $venvBin)'; rm -rf $(dirname $venvBin); fi" EXIT`
+		// stashed by needs/install_requirements, or the
+		// conda-meta of a conda environment.
+		clean = `trap "if [[ -n "$venvBin" ]] && [[ -f "$venvBin/../requirements.txt" || -d "$venvBin/../conda-meta" ]]; then echo 'Cleaning up venv $(dirname $venvBin)'; rm -rf $(dirname $venvBin); fi" EXIT`
 	}
 
 	for _, needs := range app.Spec.Needs {
@@ -61,6 +62,13 @@ func LowerAsComponent(buildName string, ctx llir.Context, app hlir.Application,
 
 		if needs.Requirements != "" {
 			req = "--requirements " + base64.StdEncoding.EncodeToString([]byte(needs.Requirements))
+			if needs.Name == "python" || needs.Name == "conda" {
+				// Keep the environment from being pruned while we run
+				req += " --hold-for $$"
+			}
+			if needs.Name == "python" && opts.OfflinePython {
+				req += " --offline"
+			}
 			if opts.Log.Verbose {
 				fmt.Fprintln(os.Stderr, "Setting requirements for needs")
 			}
@@ -74,7 +82,23 @@ PATH="$venvBin":$PATH
 	}
 
 	for _, dataset := range app.Spec.Datasets {
-		if dataset.S3.Rclone.RemoteName != "" && dataset.S3.CopyIn.Path != "" {
+		if dataset.S3.CopyIn.Path == "" && dataset.S3.Mount.Path == "" {
+			continue
+		} else if dataset.S3.CopyIn.Path != "" && dataset.S3.Mount.Path != "" {
+			return llir.ShellComponent{}, fmt.Errorf("Dataset %s of Application=%s may specify either copyIn or mount, but not both", dataset.Name, app.Metadata.Name)
+		}
+
+		var queueEnv string
+		if dataset.S3.Secret != "" {
+			// Refer to the credentials bound via envFrom,
+			// rather than baking them into the command
+			prefix := dataset.S3.EnvFrom.Prefix
+			if prefix == "" {
+				return llir.ShellComponent{}, fmt.Errorf("Dataset %s of Application=%s uses a secret and copyIn or mount, and so must also specify envFrom.prefix", dataset.Name, app.Metadata.Name)
+			}
+
+			queueEnv = fmt.Sprintf(`lunchpail_queue_endpoint="$%sendpoint" lunchpail_queue_accessKeyID="$%saccessKeyID" lunchpail_queue_secretAccessKey="$%ssecretAccessKey"`, prefix, prefix, prefix)
+		} else if dataset.S3.Rclone.RemoteName != "" {
 			// We were asked to copy data in from s3, so
 			// we will use the secrets attached to an
 			// initContainer
@@ -86,10 +110,29 @@ PATH="$venvBin":$PATH
 				return llir.ShellComponent{}, fmt.Errorf("Error: invalid or missing rclone config for given remote=%s for Application=%s", dataset.S3.Rclone.RemoteName, app.Metadata.Name)
 			}
 
+			queueEnv = fmt.Sprintf(`lunchpail_queue_endpoint=%s lunchpail_queue_accessKeyID=%s lunchpail_queue_secretAccessKey=%s`, spec.Endpoint, spec.AccessKey, spec.SecretKey)
+		} else {
+			continue
+		}
+
+		if dataset.S3.CopyIn.Path != "" {
 			// sleep to delay the copy-out, if requested
 			component.Spec.Command = fmt.Sprintf(`sleep %d
-env lunchpail_queue_endpoint=%s lunchpail_queue_accessKeyID=%s lunchpail_queue_secretAccessKey=%s $LUNCHPAIL_EXE queue download %s %s/%s
-%s`, dataset.S3.CopyIn.Delay, spec.Endpoint, spec.AccessKey, spec.SecretKey, dataset.S3.CopyIn.Path, dataset.Name, filepath.Base(dataset.S3.CopyIn.Path), component.Spec.Command)
+env %s $LUNCHPAIL_EXE queue download %s %s/%s
+%s`, dataset.S3.CopyIn.Delay, queueEnv, dataset.S3.CopyIn.Path, dataset.Name, filepath.Base(dataset.S3.CopyIn.Path), component.Spec.Command)
+		} else {
+			// Rather than copying everything in, expose
+			// the data as a filesystem that fetches
+			// lazily as it is read. The command runs in a
+			// subshell, so that we unmount however it exits
+			// (and whatever traps it sets).
+			component.Spec.Command = fmt.Sprintf(`env %s $LUNCHPAIL_EXE queue mount %s %s --verbose=%v || exit $?
+(
+%s
+)
+lunchpail_status=$?
+$LUNCHPAIL_EXE queue unmount %s --verbose=%v
+exit $lunchpail_status`, queueEnv, dataset.S3.Mount.Path, dataset.Name, opts.Log.Verbose, component.Spec.Command, dataset.Name, opts.Log.Verbose)
 		}
 	}
 	return component, nil
@@ -0,0 +1,130 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/llir"
+	"lunchpail.io/pkg/ir/queue"
+)
+
+func TestS3MountIsUnmounted(t *testing.T) {
+	var app hlir.Application
+	app.Metadata.Name = "test"
+	app.Spec.Command = "python3 main.py"
+
+	var dataset hlir.Dataset
+	dataset.Name = "data"
+	dataset.S3.Secret = "k8s://creds"
+	dataset.S3.EnvFrom.Prefix = "data_"
+	dataset.S3.Mount.Path = "bucket/prefix"
+	app.Spec.Datasets = []hlir.Dataset{dataset}
+
+	c, err := Lower("test", llir.Context{Run: queue.RunContext{RunName: "r"}}, app, build.Options{Log: &build.LogOptions{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := c.Application.Spec.Command
+	mount := strings.Index(cmd, "queue mount bucket/prefix data")
+	run := strings.Index(cmd, app.Spec.Command)
+	unmount := strings.Index(cmd, "queue unmount data")
+	if mount < 0 || run < mount || unmount < run {
+		t.Fatalf("expected the command to mount, run, then unmount, got %s", cmd)
+	}
+
+	if out, err := exec.Command("/bin/sh", "-n", "-c", cmd).CombinedOutput(); err != nil {
+		t.Fatalf("invalid shell command: %v %s\n%s", err, out, cmd)
+	}
+}
+
+func TestS3MountIsUnmountedOnFailure(t *testing.T) {
+	var app hlir.Application
+	app.Metadata.Name = "test"
+	app.Spec.Command = `trap "echo trapped" EXIT
+exit 3`
+
+	var dataset hlir.Dataset
+	dataset.Name = "data"
+	dataset.S3.Secret = "k8s://creds"
+	dataset.S3.EnvFrom.Prefix = "data_"
+	dataset.S3.Mount.Path = "bucket/prefix"
+	app.Spec.Datasets = []hlir.Dataset{dataset}
+
+	c, err := Lower("test", llir.Context{Run: queue.RunContext{RunName: "r"}}, app, build.Options{Log: &build.LogOptions{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A stand-in for lunchpail that logs how it was called
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "lunchpail")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\necho \"$1 $2\" >> "+filepath.Join(dir, "log")+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", c.Application.Spec.Command)
+	cmd.Env = append(os.Environ(), "LUNCHPAIL_EXE="+exe)
+	out, err := cmd.CombinedOutput()
+	if cmd.ProcessState.ExitCode() != 3 {
+		t.Fatalf("expected the exit code of the command, got %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "trapped") {
+		t.Errorf("expected the command's own trap to run, got %s", out)
+	}
+
+	log, err := os.ReadFile(filepath.Join(dir, "log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(log) != "queue mount\nqueue unmount\n" {
+		t.Fatalf("expected a mount and then an unmount, got %q", log)
+	}
+}
+
+func TestOfflinePythonNeeds(t *testing.T) {
+	var app hlir.Application
+	app.Metadata.Name = "test"
+	app.Spec.Command = "python3 main.py"
+	app.Spec.Needs = []hlir.Needs{{Name: "python", Version: "latest", Requirements: "six\n"}}
+
+	for _, offline := range []bool{false, true} {
+		c, err := Lower("test", llir.Context{Run: queue.RunContext{RunName: "r"}}, app, build.Options{Log: &build.LogOptions{}, OfflinePython: offline})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cmd := c.Application.Spec.Command; strings.Contains(cmd, "--offline") != offline {
+			t.Errorf("offline=%v: unexpected needs command %s", offline, cmd)
+		}
+	}
+}
+
+// Both python and conda environments are kept from being pruned while
+// the application runs, but only python installs go offline
+func TestNeedsHoldFor(t *testing.T) {
+	for _, name := range []string{"python", "conda"} {
+		var app hlir.Application
+		app.Metadata.Name = "test"
+		app.Spec.Command = "python3 main.py"
+		app.Spec.Needs = []hlir.Needs{{Name: name, Version: "latest", Requirements: "six\n"}}
+
+		c, err := Lower("test", llir.Context{Run: queue.RunContext{RunName: "r"}}, app, build.Options{Log: &build.LogOptions{}, OfflinePython: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cmd := c.Application.Spec.Command
+		if !strings.Contains(cmd, "--hold-for $$") {
+			t.Errorf("%s: expected --hold-for in %s", name, cmd)
+		}
+		if strings.Contains(cmd, "--offline") != (name == "python") {
+			t.Errorf("%s: unexpected needs command %s", name, cmd)
+		}
+	}
+}
@@ -35,6 +35,21 @@ func Lower(buildName string, ctx llir.Context, app hlir.Application, pool hlir.W
 		}
 	}
 
+	if err := pool.Spec.Scheduling.Validate(); err != nil {
+		return spec, fmt.Errorf("Invalid scheduling for worker pool %s: %v", pool.Metadata.Name, err)
+	}
+	spec.Scheduling = pool.Spec.Scheduling
+
+	if opts.HasGpuSupport && spec.Scheduling.Gpu == 0 {
+		spec.Scheduling.Gpu = 1
+	}
+	if spec.Scheduling.Gpu > 0 && !app.Spec.SupportsGpu {
+		if opts.HasGpuSupport {
+			return spec, fmt.Errorf("The --gpu option was given, but application %s does not declare supportsGpu", app.Metadata.Name)
+		}
+		return spec, fmt.Errorf("Worker pool %s requests %d GPUs, but application %s does not declare supportsGpu", pool.Metadata.Name, spec.Scheduling.Gpu, app.Metadata.Name)
+	}
+
 	if pool.Spec.Workers.Count != 0 {
 		spec.InitialWorkers = pool.Spec.Workers.Count
 	} else {
@@ -59,21 +74,20 @@ func Lower(buildName string, ctx llir.Context, app hlir.Application, pool hlir.W
 		opts.Log.Debug,
 	)
 
-	callingConvention := opts.CallingConvention
-	if callingConvention == "" {
-		callingConvention = app.Spec.CallingConvention
-	}
-	if callingConvention == "" {
-		callingConvention = hlir.CallingConventionFiles
-	}
+	callingConvention := app.CallingConventionOr(opts.CallingConvention)
 
 	app.Spec.Command = fmt.Sprintf(`trap "$LUNCHPAIL_EXE component worker prestop %s" EXIT
-$LUNCHPAIL_EXE component worker run --pack %d --gunzip=%v --delay %d --calling-convention %v %s -- %s`,
+$LUNCHPAIL_EXE component worker run --pack %d --gunzip=%v --delay %d --calling-convention %v --speculate=%v --dispatcher=%v --scoped-credentials=%v --sandbox=%v --sandbox-allow-unconfined-network=%v %s -- %s`,
 		queueArgs,
 		opts.Pack,
 		opts.Gunzip,
 		startupDelay,
 		callingConvention,
+		opts.Speculate && app.CanSpeculate(opts.CallingConvention),
+		app.Spec.IsDispatcher,
+		ctx.Queue.Auto, // only our own queue server can mint scoped credentials
+		opts.Sandbox,
+		opts.SandboxUnconfinedNet,
 		queueArgs,
 		app.Spec.Command,
 	)
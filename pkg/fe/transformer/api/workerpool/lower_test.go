@@ -0,0 +1,56 @@
+package workerpool
+
+import (
+	"strings"
+	"testing"
+
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/llir"
+	"lunchpail.io/pkg/ir/queue"
+)
+
+func TestGpu(t *testing.T) {
+	tests := []struct {
+		name        string
+		gpuFlag     bool
+		poolGpus    int
+		supportsGpu bool
+		wantGpus    int
+		wantErr     string
+	}{
+		{"none", false, 0, false, 0, ""},
+		{"flag", true, 0, true, 1, ""},
+		{"pool", false, 2, true, 2, ""},
+		{"pool overrides flag", true, 2, true, 2, ""},
+		{"flag without support", true, 0, false, 0, "The --gpu option was given, but application app does not declare supportsGpu"},
+		{"pool without support", false, 2, false, 0, "Worker pool pool requests 2 GPUs, but application app does not declare supportsGpu"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var app hlir.Application
+			app.Metadata.Name = "app"
+			app.Spec.Command = "./main.sh"
+			app.Spec.SupportsGpu = tt.supportsGpu
+
+			pool := hlir.NewPool("pool", 1)
+			pool.Spec.Scheduling.Gpu = tt.poolGpus
+
+			opts := build.Options{HasGpuSupport: tt.gpuFlag, Log: &build.LogOptions{}}
+			c, err := Lower("test", llir.Context{Run: queue.RunContext{RunName: "r"}}, app, pool, opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.Scheduling.Gpu != tt.wantGpus {
+				t.Errorf("expected %d GPUs, got %d", tt.wantGpus, c.Scheduling.Gpu)
+			}
+		})
+	}
+}
@@ -12,8 +12,7 @@ import (
 func LowerAll(buildName string, ctx llir.Context, model hlir.HLIR, opts build.Options) ([]llir.ShellComponent, error) {
 	components := []llir.ShellComponent{}
 
-	app, found := model.GetWorkerApplication()
-	if !found {
+	if _, found := model.GetWorkerApplication(); !found {
 		return components, fmt.Errorf("No Application with role Worker found")
 	}
 
@@ -23,6 +22,11 @@ func LowerAll(buildName string, ctx llir.Context, model hlir.HLIR, opts build.Op
 	}
 
 	for _, pool := range model.WorkerPools {
+		app, found := model.GetWorkerApplicationFor(pool)
+		if !found {
+			return components, fmt.Errorf("No Application with role Worker and tags %v found for worker pool %s", pool.Spec.Application.Tags, pool.Metadata.Name)
+		}
+
 		if component, err := Lower(buildName, ctx, app, pool, opts); err != nil {
 			return components, err
 		} else {
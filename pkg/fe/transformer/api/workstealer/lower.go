@@ -1,14 +1,27 @@
 package workstealer
 
 import (
+	"fmt"
+	"os"
+
 	"lunchpail.io/pkg/build"
 	"lunchpail.io/pkg/fe/transformer/api/shell"
+	"lunchpail.io/pkg/ir/hlir"
 	"lunchpail.io/pkg/ir/llir"
 	"lunchpail.io/pkg/lunchpail"
 )
 
-func Lower(buildName string, ctx llir.Context, opts build.Options) (llir.ShellComponent, error) {
-	app, err := transpile(ctx, *opts.Log)
+func Lower(buildName string, ctx llir.Context, model hlir.HLIR, opts build.Options) (llir.ShellComponent, error) {
+	speculate := false
+	if opts.Speculate {
+		if app, ok := model.GetWorkerApplication(); ok && app.CanSpeculate(opts.CallingConvention) {
+			speculate = true
+		} else {
+			fmt.Fprintln(os.Stderr, "Warning: speculative re-execution is not supported with the stdio calling convention")
+		}
+	}
+
+	app, err := transpile(ctx, model.AlertSpecs(opts.Alerts), speculate, *opts.Log)
 	if err != nil {
 		return llir.ShellComponent{}, err
 	}
@@ -8,20 +8,30 @@ import (
 	"lunchpail.io/pkg/ir/hlir"
 	"lunchpail.io/pkg/ir/llir"
 	"lunchpail.io/pkg/lunchpail"
+	"lunchpail.io/pkg/runtime/alerts"
 )
 
 // Transpile workstealer to hlir.Application
-func transpile(ctx llir.Context, opts build.LogOptions) (hlir.Application, error) {
+func transpile(ctx llir.Context, alertSpecs []hlir.AlertSpec, speculate bool, opts build.LogOptions) (hlir.Application, error) {
 	app := hlir.NewSupportApplication(ctx.Run.RunName + "-workstealer")
 
 	app.Spec.Image = fmt.Sprintf("%s/%s/lunchpail:%s", lunchpail.ImageRegistry, lunchpail.ImageRepo, lunchpail.Version())
-	app.Spec.Command = fmt.Sprintf("$LUNCHPAIL_EXE component workstealer run --verbose=%v --debug=%v --self-destruct=true",
+	app.Spec.Command = fmt.Sprintf("$LUNCHPAIL_EXE component workstealer run --verbose=%v --debug=%v --self-destruct=true --speculate=%v",
 		opts.Verbose,
 		opts.Debug,
+		speculate,
 	)
 
 	app.Spec.Env = hlir.Env{}
 
+	if len(alertSpecs) > 0 {
+		encoded, err := alerts.EncodeSpecs(alertSpecs)
+		if err != nil {
+			return hlir.Application{}, err
+		}
+		app.Spec.Env[alerts.SpecsEnvVar] = encoded
+	}
+
 	// This can help with tests
 	app.Spec.Env["LUNCHPAIL_SLEEP_BEFORE_EXIT"] = os.Getenv("LUNCHPAIL_SLEEP_BEFORE_EXIT")
 
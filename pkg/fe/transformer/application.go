@@ -16,7 +16,7 @@ func lowerApplications(buildName string, ctx llir.Context, model hlir.HLIR, opts
 		// Note, the actual worker resources will be dealt
 		// with when a WorkerPool is created. Here, we only
 		// need to specify a WorkStealer.
-		c, err := workstealer.Lower(buildName, ctx, opts)
+		c, err := workstealer.Lower(buildName, ctx, model, opts)
 		if err != nil {
 			return nil, err
 		}
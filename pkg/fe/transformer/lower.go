@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"fmt"
 	"slices"
 
 	"lunchpail.io/pkg/build"
@@ -12,7 +13,18 @@ import (
 
 // HLIR -> LLIR
 func Lower(buildName string, model hlir.HLIR, ctx llir.Context, opts build.Options) (llir.LLIR, error) {
-	ir := llir.LLIR{AppName: buildName, Context: ctx}
+	ir := llir.LLIR{AppName: buildName, Context: ctx, Alerts: model.AlertSpecs(opts.Alerts)}
+	for _, spec := range ir.Alerts {
+		if err := spec.Validate(); err != nil {
+			return llir.LLIR{}, err
+		}
+	}
+
+	for _, app := range model.Applications {
+		if err := app.Spec.ValidateTags(); err != nil {
+			return llir.LLIR{}, fmt.Errorf("Invalid application %s: %v", app.Metadata.Name, err)
+		}
+	}
 
 	if minio, minioOk, err := minio.Lower(buildName, ctx, model, opts); err != nil {
 		return llir.LLIR{}, err
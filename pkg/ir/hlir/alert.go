@@ -0,0 +1,84 @@
+package hlir
+
+import (
+	"fmt"
+	"slices"
+)
+
+type AlertEvent string
+
+const (
+	AlertOnStart        AlertEvent = "start"
+	AlertOnFirstFailure AlertEvent = "first-failure"
+	AlertOnFailureRate  AlertEvent = "failure-rate"
+	AlertOnStall        AlertEvent = "stall"
+	AlertOnCompletion   AlertEvent = "completion"
+)
+
+var AllAlertEvents = []AlertEvent{AlertOnStart, AlertOnFirstFailure, AlertOnFailureRate, AlertOnStall, AlertOnCompletion}
+
+type AlertSpec struct {
+	// POST a JSON description of the event to these URLs
+	Webhooks []string `yaml:"webhooks,omitempty" json:"webhooks,omitempty"`
+
+	// POST a Slack-compatible {"text": ...} payload to these URLs
+	Slack []string `yaml:"slack,omitempty" json:"slack,omitempty"`
+
+	// Execute these commands on the machine that brought up the run
+	Exec []string `yaml:"exec,omitempty" json:"exec,omitempty"`
+
+	// Which events to fire on; if empty, fire on all events
+	On []string `yaml:"on,omitempty" json:"on,omitempty"`
+
+	// Fire a failure-rate event if this fraction (0-1) of tasks fail
+	FailureRate float64 `yaml:"failureRate,omitempty" json:"failureRate,omitempty"`
+
+	// Fire a stall event if no task completes for this duration, e.g. 10m
+	StallTimeout string `yaml:"stallTimeout,omitempty" json:"stallTimeout,omitempty"`
+}
+
+type Alert struct {
+	ApiVersion string `yaml:"apiVersion"`
+	Kind       string
+	Metadata   Metadata
+	Spec       AlertSpec
+}
+
+// Does this spec have anywhere to send alerts?
+func (spec AlertSpec) HasSinks() bool {
+	return len(spec.Webhooks) > 0 || len(spec.Slack) > 0 || len(spec.Exec) > 0
+}
+
+// Should this spec fire on the given event?
+func (spec AlertSpec) FiresOn(event AlertEvent) bool {
+	return len(spec.On) == 0 || slices.Contains(spec.On, string(event))
+}
+
+func (spec AlertSpec) Validate() error {
+	for _, on := range spec.On {
+		if !slices.Contains(AllAlertEvents, AlertEvent(on)) {
+			return fmt.Errorf("Unsupported alert event %s, expected one of %v", on, AllAlertEvents)
+		}
+	}
+
+	if spec.FailureRate < 0 || spec.FailureRate > 1 {
+		return fmt.Errorf("Alert failure rate must be between 0 and 1, got %v", spec.FailureRate)
+	}
+
+	return nil
+}
+
+// The alert specs given by Alert resources in the model, plus any
+// given by the `fromOptions` spec (e.g. from command line flags)
+func (model HLIR) AlertSpecs(fromOptions AlertSpec) []AlertSpec {
+	specs := []AlertSpec{}
+	if fromOptions.HasSinks() {
+		specs = append(specs, fromOptions)
+	}
+	for _, alert := range model.Alerts {
+		if alert.Spec.HasSinks() {
+			specs = append(specs, alert.Spec)
+		}
+	}
+	return specs
+}
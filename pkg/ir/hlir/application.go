@@ -1,5 +1,7 @@
 package hlir
 
+import "unicode/utf8"
+
 type Env map[string]string
 
 type Code struct {
@@ -7,6 +9,21 @@ type Code struct {
 	Source string
 }
 
+// Is this code binary, e.g. a compiled executable, rather than text?
+func (code Code) IsBinary() bool {
+	return !utf8.ValidString(code.Source)
+}
+
+// Is any of the given code binary?
+func HasBinaryCode(code []Code) bool {
+	for _, c := range code {
+		if c.IsBinary() {
+			return true
+		}
+	}
+	return false
+}
+
 type Needs struct {
 	Name         string
 	Version      string
@@ -14,7 +31,7 @@ type Needs struct {
 }
 
 type Spec struct {
-	Role                     role                     `yaml:",omitempty"`
+	Role                     Role                     `yaml:",omitempty"`
 	Code                     []Code                   `yaml:"code,omitempty"`
 	Description              string                   `yaml:"description,omitempty"`
 	SupportsGpu              bool                     `yaml:"supportsGpu,omitempty"`
@@ -40,9 +57,9 @@ type Application struct {
 	Spec
 }
 
-func newApplicationWithRole(name string, role role) Application {
+func newApplicationWithRole(name string, role Role) Application {
 	return Application{
-		ApiVersion: "v1alpha1",
+		ApiVersion: V1alpha1,
 		Kind:       "Application",
 		Metadata:   Metadata{name},
 		Spec:       Spec{Role: role},
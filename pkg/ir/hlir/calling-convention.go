@@ -39,3 +39,23 @@ func (cc *CallingConvention) Set(v string) error {
 func (cc *CallingConvention) Type() string {
 	return "CallingConvention"
 }
+
+// The calling convention of the given application, unless the given
+// `override` is specified (e.g. from the command line)
+func (app Application) CallingConventionOr(override CallingConvention) CallingConvention {
+	switch {
+	case override != "":
+		return override
+	case app.Spec.CallingConvention != "":
+		return app.Spec.CallingConvention
+	default:
+		return CallingConventionFiles
+	}
+}
+
+// Speculative re-execution of tasks relies on a worker holding back
+// its output until the task completes, which the stdio calling
+// convention does not do
+func (app Application) CanSpeculate(override CallingConvention) bool {
+	return app.CallingConventionOr(override) == CallingConventionFiles
+}
@@ -9,14 +9,27 @@ type CopyIn struct {
 	Delay int `yaml:"delay,omitempty"`
 }
 
+// Expose an S3 prefix as a read-only filesystem, fetching data
+// lazily as it is read, rather than copying it all in up front
+type Mount struct {
+	Path string
+}
+
 type EnvFrom struct {
 	Prefix string
 }
 
 type S3 struct {
 	Rclone
+
+	// Alternatively to Rclone, a reference to a secret holding
+	// endpoint, accessKeyID, and secretAccessKey,
+	// e.g. k8s://name or vault://mount/path
+	Secret string `yaml:"secret,omitempty"`
+
 	EnvFrom `yaml:"envFrom,omitempty"`
 	CopyIn  `yaml:"copyIn,omitempty"`
+	Mount   Mount `yaml:"mount,omitempty"`
 }
 
 type Blob struct {
@@ -24,6 +37,16 @@ type Blob struct {
 	Encoding string
 }
 
+// Does any of the given datasets mount an S3 prefix as a filesystem?
+func HasS3Mount(datasets []Dataset) bool {
+	for _, dataset := range datasets {
+		if dataset.S3.Mount.Path != "" {
+			return true
+		}
+	}
+	return false
+}
+
 type Dataset struct {
 	Name      string
 	MountPath string `yaml:"mountPath,omitempty"`
@@ -0,0 +1,301 @@
+package hlir
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// One difference between two HLIRs
+type Change struct {
+	// e.g. Application/main or WorkerPool/workers
+	Resource string
+
+	// e.g. spec.env.FOO or spec.datasets[data].mountPath; empty if
+	// the whole resource was added or removed, in which case Before
+	// or After is the Resource
+	Field string
+
+	// Empty if the field was added
+	Before string
+
+	// Empty if the field was removed
+	After string
+}
+
+func (c Change) IsAddition() bool {
+	return c.Before == "" && c.After != ""
+}
+
+func (c Change) IsRemoval() bool {
+	return c.Before != "" && c.After == ""
+}
+
+// The differences between two HLIRs, by resource and then by field.
+// List items that have a name, e.g. datasets, code, and needs, are
+// matched by name rather than by position.
+func Diff(before, after HLIR) ([]Change, error) {
+	a, err := before.flatten()
+	if err != nil {
+		return nil, err
+	}
+	b, err := after.flatten()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []Change{}
+	for _, resource := range union(a, b) {
+		fieldsA, inA := a[resource]
+		fieldsB, inB := b[resource]
+		switch {
+		case !inA:
+			changes = append(changes, Change{Resource: resource, After: resource})
+		case !inB:
+			changes = append(changes, Change{Resource: resource, Before: resource})
+		default:
+			for _, field := range union(fieldsA, fieldsB) {
+				if fieldsA[field] != fieldsB[field] {
+					changes = append(changes, Change{resource, field, fieldsA[field], fieldsB[field]})
+				}
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// Resource -> dotted field path -> value
+func (model HLIR) flatten() (map[string]map[string]string, error) {
+	resources := map[string]map[string]string{}
+	add := func(kind, name string, resource any) error {
+		b, err := yaml.Marshal(resource)
+		if err != nil {
+			return err
+		}
+
+		var generic map[string]any
+		if err := yaml.Unmarshal(b, &generic); err != nil {
+			return err
+		}
+
+		// These identify the resource, rather than describe it
+		delete(generic, "kind")
+		delete(generic, "metadata")
+
+		fields := map[string]string{}
+		flattenInto(fields, "", generic)
+		resources[kind+"/"+name] = fields
+		return nil
+	}
+
+	for _, app := range model.Applications {
+		if err := add("Application", app.Metadata.Name, app); err != nil {
+			return nil, err
+		}
+	}
+	for _, pool := range model.WorkerPools {
+		if err := add("WorkerPool", pool.Metadata.Name, pool); err != nil {
+			return nil, err
+		}
+	}
+	for _, alert := range model.Alerts {
+		if err := add("Alert", alert.Metadata.Name, alert); err != nil {
+			return nil, err
+		}
+	}
+
+	return resources, nil
+}
+
+func flattenInto(fields map[string]string, path string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			flattenInto(fields, join(path, key), child)
+		}
+	case []any:
+		for idx, item := range v {
+			if m, ok := item.(map[string]any); ok && m["name"] != nil {
+				flattenInto(fields, fmt.Sprintf("%s[%v]", path, m["name"]), without(m, "name"))
+			} else {
+				flattenInto(fields, fmt.Sprintf("%s[%d]", path, idx), item)
+			}
+		}
+	case nil:
+	default:
+		if s := fmt.Sprintf("%v", v); s != "" {
+			fields[path] = s
+		}
+	}
+}
+
+func without(m map[string]any, key string) map[string]any {
+	rest := map[string]any{}
+	for k, v := range m {
+		if k != key {
+			rest[k] = v
+		}
+	}
+	if len(rest) == 0 {
+		// Otherwise, an item with only a name would vanish
+		return map[string]any{"name": m[key]}
+	}
+	return rest
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// The sorted keys of both maps
+func union[V any](a, b map[string]V) []string {
+	keys := []string{}
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// e.g. ~ spec.command: python main.py -> python main2.py. For
+// multi-line values, only the field; see Lines for what changed.
+func (c Change) String() string {
+	switch {
+	case c.Field == "" && c.IsAddition():
+		return "+ " + c.Resource
+	case c.Field == "":
+		return "- " + c.Resource
+	case c.IsAddition() && c.IsMultiline():
+		return fmt.Sprintf("+ %s:", c.Field)
+	case c.IsRemoval() && c.IsMultiline():
+		return fmt.Sprintf("- %s:", c.Field)
+	case c.IsMultiline():
+		return fmt.Sprintf("~ %s:", c.Field)
+	case c.IsAddition():
+		return fmt.Sprintf("+ %s: %s", c.Field, summarize(c.After))
+	case c.IsRemoval():
+		return fmt.Sprintf("- %s: %s", c.Field, summarize(c.Before))
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", c.Field, summarize(c.Before), summarize(c.After))
+}
+
+// Whether the field holds more than one line on either side, as e.g.
+// code source does
+func (c Change) IsMultiline() bool {
+	return c.Field != "" && (isMultiline(c.Before) || isMultiline(c.After))
+}
+
+func isMultiline(value string) bool {
+	return strings.Contains(strings.TrimRight(value, "\n"), "\n")
+}
+
+// The number of unchanged lines to show around each changed line
+const diffContext = 2
+
+// For a multi-line change, the lines that differ, each prefixed with
+// "+ " or "- ", amid a few unchanged lines prefixed with "  ". Longer
+// runs of unchanged lines are elided as "…". Nil for other changes.
+func (c Change) Lines() []string {
+	if !c.IsMultiline() {
+		return nil
+	}
+
+	ops := diffLines(splitLines(c.Before), splitLines(c.After))
+
+	// Show the unchanged lines that are near a changed one
+	show := make([]bool, len(ops))
+	for i, op := range ops {
+		if op[0] != ' ' {
+			for j := max(0, i-diffContext); j <= min(len(ops)-1, i+diffContext); j++ {
+				show[j] = true
+			}
+		}
+	}
+
+	lines := []string{}
+	elided := false
+	for i, op := range ops {
+		if show[i] {
+			lines = append(lines, op)
+			elided = false
+		} else if !elided {
+			lines = append(lines, "…")
+			elided = true
+		}
+	}
+	return lines
+}
+
+func splitLines(value string) []string {
+	if value = strings.TrimRight(value, "\n"); value == "" {
+		return nil
+	}
+	return strings.Split(value, "\n")
+}
+
+// A line diff of a and b via their longest common subsequence, each
+// line prefixed with "+ ", "- ", or "  "
+func diffLines(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of
+	// a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := []string{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, "- "+a[i])
+			i++
+		default:
+			ops = append(ops, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, "+ "+b[j])
+	}
+	return ops
+}
+
+// The first line of the given value, elided if long
+func summarize(value string) string {
+	const maxlen = 60
+	first, _, multiline := strings.Cut(strings.TrimSpace(value), "\n")
+	if runes := []rune(first); len(runes) > maxlen {
+		return string(runes[:maxlen-1]) + "…"
+	} else if multiline {
+		return first + "…"
+	}
+	return first
+}
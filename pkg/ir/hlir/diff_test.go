@@ -0,0 +1,153 @@
+package hlir
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func app(name string, spec Spec) Application {
+	return Application{ApiVersion: V1alpha1, Kind: "Application", Metadata: Metadata{Name: name}, Spec: spec}
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	model := HLIR{Applications: []Application{app("main", Spec{Command: "./main.sh", Env: Env{"A": "1"}})}}
+	changes, err := Diff(model, model)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
+
+func TestDiffResources(t *testing.T) {
+	before := HLIR{Applications: []Application{app("main", Spec{}), app("old", Spec{})}}
+	after := HLIR{
+		Applications: []Application{app("main", Spec{})},
+		WorkerPools:  []WorkerPool{{Kind: "WorkerPool", Metadata: Metadata{Name: "pool"}}},
+	}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Change{
+		{Resource: "Application/old", Before: "Application/old"},
+		{Resource: "WorkerPool/pool", After: "WorkerPool/pool"},
+	}
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("expected %v, got %v", expected, changes)
+	}
+	if s := changes[0].String(); s != "- Application/old" {
+		t.Errorf("unexpected %q", s)
+	}
+	if s := changes[1].String(); s != "+ WorkerPool/pool" {
+		t.Errorf("unexpected %q", s)
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	before := HLIR{Applications: []Application{app("main", Spec{Command: "python main.py", Env: Env{"A": "1", "B": "2"}})}}
+	after := HLIR{Applications: []Application{app("main", Spec{Command: "python main2.py", Env: Env{"A": "1", "C": "3"}})}}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"~ spec.command: python main.py -> python main2.py",
+		"- spec.env.B: 2",
+		"+ spec.env.C: 3",
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %v", len(expected), changes)
+	}
+	for i, c := range changes {
+		if c.Resource != "Application/main" {
+			t.Errorf("unexpected resource %s", c.Resource)
+		}
+		if c.String() != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], c.String())
+		}
+	}
+}
+
+// Named list items are matched by name, so that reordering them is
+// not a change, and a change to one is reported against its name
+func TestDiffNamedListItems(t *testing.T) {
+	before := HLIR{Applications: []Application{app("main", Spec{Datasets: []Dataset{{Name: "a", MountPath: "/a"}, {Name: "b", MountPath: "/b"}}})}}
+	after := HLIR{Applications: []Application{app("main", Spec{Datasets: []Dataset{{Name: "b", MountPath: "/b2"}, {Name: "a", MountPath: "/a"}}})}}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Change{{Resource: "Application/main", Field: "spec.datasets[b].mountPath", Before: "/b", After: "/b2"}}
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("expected %v, got %v", expected, changes)
+	}
+}
+
+func TestDiffMultilineLines(t *testing.T) {
+	before := "line1\nline2\nline3\nline4\nline5\nline6\nline7\nline8\n"
+	after := "line1\nline2\nline3\nline4\nline5 changed\nline6\nline7\nline8\nline9\n"
+	c := Change{Resource: "Application/main", Field: "spec.code[main.sh].source", Before: before, After: after}
+
+	if s := c.String(); s != "~ spec.code[main.sh].source:" {
+		t.Errorf("unexpected %q", s)
+	}
+
+	expected := []string{
+		"…",
+		"  line3",
+		"  line4",
+		"- line5",
+		"+ line5 changed",
+		"  line6",
+		"  line7",
+		"  line8",
+		"+ line9",
+	}
+	if lines := c.Lines(); !reflect.DeepEqual(expected, lines) {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
+func TestDiffMultilineAddition(t *testing.T) {
+	c := Change{Field: "spec.code[new.sh].source", After: "a\nb\n"}
+	if s := c.String(); s != "+ spec.code[new.sh].source:" {
+		t.Errorf("unexpected %q", s)
+	}
+	if lines := c.Lines(); !reflect.DeepEqual([]string{"+ a", "+ b"}, lines) {
+		t.Errorf("unexpected %v", lines)
+	}
+}
+
+func TestDiffSingleLineHasNoLines(t *testing.T) {
+	c := Change{Field: "spec.command", Before: "a\n", After: "b"}
+	if lines := c.Lines(); lines != nil {
+		t.Errorf("expected no lines, got %v", lines)
+	}
+}
+
+func TestSummarizeRunes(t *testing.T) {
+	long := strings.Repeat("é", 100)
+	s := summarize(long)
+	if !utf8.ValidString(s) {
+		t.Errorf("summary is not valid UTF-8: %q", s)
+	}
+	if n := utf8.RuneCountInString(s); n != 60 {
+		t.Errorf("expected 60 runes, got %d", n)
+	}
+
+	if s := summarize("first\nsecond"); s != "first…" {
+		t.Errorf("unexpected %q", s)
+	}
+	if s := summarize("short"); s != "short" {
+		t.Errorf("unexpected %q", s)
+	}
+}
@@ -5,6 +5,7 @@ import "slices"
 type HLIR struct {
 	Applications []Application
 	WorkerPools  []WorkerPool
+	Alerts       []Alert
 	Others       []UnknownResource
 }
 
@@ -17,6 +18,18 @@ func (model HLIR) GetWorkerApplication() (Application, bool) {
 	return model.Applications[idx], true
 }
 
+// The worker Application selected by the given pool's tags
+func (model HLIR) GetWorkerApplicationFor(pool WorkerPool) (Application, bool) {
+	idx := slices.IndexFunc(model.Applications, func(app Application) bool {
+		return (app.Spec.Role == workerRole || app.Spec.Role == "") && app.Spec.HasTags(pool.Spec.Application.Tags)
+	})
+	if idx < 0 {
+		return Application{}, false
+	}
+
+	return model.Applications[idx], true
+}
+
 func (model HLIR) SupportApplications() <-chan Application {
 	c := make(chan Application)
 
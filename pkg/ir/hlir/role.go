@@ -1,8 +1,11 @@
 package hlir
 
-type role string
+type Role string
 
 const (
-	workerRole  role = "worker"
+	workerRole  Role = "worker"
 	supportRole      = "support"
 )
+
+// The roles an Application may declare
+var Roles = []string{string(workerRole), supportRole}
@@ -0,0 +1,75 @@
+package hlir
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type Toleration struct {
+	Key      string `yaml:"key,omitempty" json:"key,omitempty"`
+	Operator string `yaml:"operator,omitempty" json:"operator,omitempty"`
+	Value    string `yaml:"value,omitempty" json:"value,omitempty"`
+	Effect   string `yaml:"effect,omitempty" json:"effect,omitempty"`
+}
+
+// Where and how the workers of a WorkerPool should be scheduled
+type Scheduling struct {
+	// CPU cores needed by each worker, e.g. 2 or 500m
+	Cpu string `yaml:"cpu,omitempty" json:"cpu,omitempty"`
+
+	// GPUs needed by each worker
+	Gpu int `yaml:"gpu,omitempty" json:"gpu,omitempty"`
+
+	// Only run on nodes with these labels
+	NodeSelector map[string]string `yaml:"nodeSelector,omitempty" json:"nodeSelector,omitempty"`
+
+	// Allow running on nodes with matching taints
+	Tolerations []Toleration `yaml:"tolerations,omitempty" json:"tolerations,omitempty"`
+
+	// Kubernetes affinity rules, passed through as-is
+	Affinity map[string]any `yaml:"affinity,omitempty" json:"affinity,omitempty"`
+}
+
+// Does this spec constrain placement beyond resource requirements?
+func (s Scheduling) HasPlacement() bool {
+	return len(s.NodeSelector) > 0 || len(s.Tolerations) > 0 || len(s.Affinity) > 0
+}
+
+// The CPU requirement in cores, or 0 if unspecified
+func (s Scheduling) CpuCores() (float64, error) {
+	if s.Cpu == "" {
+		return 0, nil
+	}
+
+	cores, err := strconv.ParseFloat(strings.TrimSuffix(s.Cpu, "m"), 64)
+	if err != nil || cores < 0 {
+		return 0, fmt.Errorf("Invalid cpu requirement %s, expected e.g. 2 or 500m", s.Cpu)
+	}
+	if strings.HasSuffix(s.Cpu, "m") {
+		cores /= 1000
+	}
+	return cores, nil
+}
+
+func (s Scheduling) Validate() error {
+	if _, err := s.CpuCores(); err != nil {
+		return err
+	}
+
+	if s.Gpu < 0 {
+		return fmt.Errorf("Invalid gpu requirement %d", s.Gpu)
+	}
+
+	for _, t := range s.Tolerations {
+		if t.Operator != "" && !slices.Contains([]string{"Equal", "Exists"}, t.Operator) {
+			return fmt.Errorf("Unsupported toleration operator %s, expected Equal or Exists", t.Operator)
+		}
+		if t.Effect != "" && !slices.Contains([]string{"NoSchedule", "PreferNoSchedule", "NoExecute"}, t.Effect) {
+			return fmt.Errorf("Unsupported toleration effect %s, expected NoSchedule, PreferNoSchedule, or NoExecute", t.Effect)
+		}
+	}
+
+	return nil
+}
@@ -13,4 +13,7 @@ type ContainerSecurityContext struct {
 		Type  string `yaml:"type,omitempty"`
 		Level string `yaml:"level,omitempty"`
 	} `yaml:"seLinuxOptions,omitempty"`
+	Capabilities struct {
+		Add []string `yaml:"add,omitempty"`
+	} `yaml:"capabilities,omitempty"`
 }
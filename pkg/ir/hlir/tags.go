@@ -0,0 +1,43 @@
+package hlir
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// Tags become Kubernetes labels with this prefix
+const TagLabelPrefix = "tags.lunchpail.io/"
+
+// Tags must be valid as the name part of a Kubernetes label key
+var tagPattern = regexp.MustCompile(`^[a-zA-Z0-9]([-_.a-zA-Z0-9]{0,61}[a-zA-Z0-9])?$`)
+
+func (spec Spec) ValidateTags() error {
+	for _, tag := range spec.Tags {
+		if !tagPattern.MatchString(tag) {
+			return fmt.Errorf("Invalid tag '%s', expected at most 63 alphanumeric characters, '-', '_', or '.'", tag)
+		}
+	}
+
+	return nil
+}
+
+// Does this spec carry all of the given tags?
+func (spec Spec) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(spec.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// The Kubernetes labels that represent the given tags
+func TagLabels(tags []string) map[string]string {
+	labels := make(map[string]string)
+	for _, tag := range tags {
+		labels[TagLabelPrefix+tag] = "true"
+	}
+	return labels
+}
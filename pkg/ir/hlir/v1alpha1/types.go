@@ -0,0 +1,10 @@
+package v1alpha1
+
+import "lunchpail.io/pkg/ir/hlir"
+
+const ApiVersion = hlir.V1alpha1
+
+// The hlir types still have the shape of v1alpha1, and so serve as the
+// representation to which all versions convert
+type Application = hlir.Application
+type WorkerPool = hlir.WorkerPool
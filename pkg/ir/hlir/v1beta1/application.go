@@ -0,0 +1,41 @@
+package v1beta1
+
+import "lunchpail.io/pkg/ir/hlir"
+
+const ApiVersion = hlir.V1beta1
+
+// What each instance of an Application needs
+type Resources struct {
+	// e.g. 512Mi; in v1alpha1, spec.minMemory
+	MinMemory string `yaml:"minMemory,omitempty"`
+
+	// Whether the Application can make use of a GPU; in v1alpha1,
+	// spec.supportsGpu
+	Gpu bool `yaml:"gpu,omitempty"`
+}
+
+type Spec struct {
+	Role                     hlir.Role                     `yaml:"role,omitempty"`
+	Code                     []hlir.Code                   `yaml:"code,omitempty"`
+	Description              string                        `yaml:"description,omitempty"`
+	Resources                Resources                     `yaml:"resources,omitempty"`
+	Expose                   []string                      `yaml:"expose,omitempty"`
+	Tags                     []string                      `yaml:"tags,omitempty"`
+	Command                  string                        `yaml:"command,omitempty"`
+	Image                    string                        `yaml:"image,omitempty"`
+	Env                      hlir.Env                      `yaml:"env,omitempty"`
+	Datasets                 []hlir.Dataset                `yaml:"datasets,omitempty"`
+	SecurityContext          hlir.SecurityContext          `yaml:"securityContext,omitempty"`
+	ContainerSecurityContext hlir.ContainerSecurityContext `yaml:"containerSecurityContext,omitempty"`
+	Needs                    []hlir.Needs                  `yaml:"needs,omitempty"`
+	IsDispatcher             bool                          `yaml:"isDispatcher,omitempty"`
+	CallingConvention        hlir.CallingConvention        `yaml:"callingConvention,omitempty"`
+	TestData                 hlir.TestData                 `yaml:"testData,omitempty"`
+}
+
+type Application struct {
+	ApiVersion string        `yaml:"apiVersion"`
+	Kind       string        `yaml:"kind"`
+	Metadata   hlir.Metadata `yaml:"metadata"`
+	Spec       Spec          `yaml:"spec"`
+}
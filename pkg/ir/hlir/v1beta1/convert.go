@@ -0,0 +1,87 @@
+package v1beta1
+
+import "lunchpail.io/pkg/ir/hlir/v1alpha1"
+
+// Note: keep these in sync with the migrations in pkg/fe/migrate, which
+// rewrite YAML rather than convert values
+
+func ApplicationFromV1alpha1(app v1alpha1.Application) Application {
+	s := app.Spec
+	return Application{
+		ApiVersion: ApiVersion,
+		Kind:       app.Kind,
+		Metadata:   app.Metadata,
+		Spec: Spec{
+			Role:                     s.Role,
+			Code:                     s.Code,
+			Description:              s.Description,
+			Resources:                Resources{MinMemory: s.MinMemory, Gpu: s.SupportsGpu},
+			Expose:                   s.Expose,
+			Tags:                     s.Tags,
+			Command:                  s.Command,
+			Image:                    s.Image,
+			Env:                      s.Env,
+			Datasets:                 s.Datasets,
+			SecurityContext:          s.SecurityContext,
+			ContainerSecurityContext: s.ContainerSecurityContext,
+			Needs:                    s.Needs,
+			IsDispatcher:             s.IsDispatcher,
+			CallingConvention:        s.CallingConvention,
+			TestData:                 s.TestData,
+		},
+	}
+}
+
+func (app Application) ToV1alpha1() v1alpha1.Application {
+	out := v1alpha1.Application{ApiVersion: app.ApiVersion, Kind: app.Kind, Metadata: app.Metadata}
+
+	s := app.Spec
+	out.Spec.Role = s.Role
+	out.Spec.Code = s.Code
+	out.Spec.Description = s.Description
+	out.Spec.MinMemory = s.Resources.MinMemory
+	out.Spec.SupportsGpu = s.Resources.Gpu
+	out.Spec.Expose = s.Expose
+	out.Spec.Tags = s.Tags
+	out.Spec.Command = s.Command
+	out.Spec.Image = s.Image
+	out.Spec.Env = s.Env
+	out.Spec.Datasets = s.Datasets
+	out.Spec.SecurityContext = s.SecurityContext
+	out.Spec.ContainerSecurityContext = s.ContainerSecurityContext
+	out.Spec.Needs = s.Needs
+	out.Spec.IsDispatcher = s.IsDispatcher
+	out.Spec.CallingConvention = s.CallingConvention
+	out.Spec.TestData = s.TestData
+
+	return out
+}
+
+func WorkerPoolFromV1alpha1(pool v1alpha1.WorkerPool) WorkerPool {
+	out := WorkerPool{ApiVersion: ApiVersion, Kind: pool.Kind, Metadata: pool.Metadata}
+
+	s := pool.Spec
+	out.Spec.StartupDelay = s.StartupDelay
+	out.Spec.Env = s.Env
+	out.Spec.Workers.Count = s.Workers.Count
+	out.Spec.Workers.Size = s.Workers.Size
+	out.Spec.Scheduling = Scheduling{Scheduling: s.Scheduling, MinMemory: s.Workers.MinMemory}
+	out.Spec.Application.Tags = s.Application.Tags
+
+	return out
+}
+
+func (pool WorkerPool) ToV1alpha1() v1alpha1.WorkerPool {
+	out := v1alpha1.WorkerPool{ApiVersion: pool.ApiVersion, Kind: pool.Kind, Metadata: pool.Metadata}
+
+	s := pool.Spec
+	out.Spec.StartupDelay = s.StartupDelay
+	out.Spec.Env = s.Env
+	out.Spec.Workers.Count = s.Workers.Count
+	out.Spec.Workers.MinMemory = s.Scheduling.MinMemory
+	out.Spec.Workers.Size = s.Workers.Size
+	out.Spec.Scheduling = s.Scheduling.Scheduling
+	out.Spec.Application.Tags = s.Application.Tags
+
+	return out
+}
@@ -0,0 +1,153 @@
+package v1beta1_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"lunchpail.io/pkg/fe/migrate"
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/hlir/v1alpha1"
+	"lunchpail.io/pkg/ir/hlir/v1beta1"
+)
+
+// Every field set, so that a conversion that forgets one shows up
+func application() v1alpha1.Application {
+	app := v1alpha1.Application{ApiVersion: v1alpha1.ApiVersion, Kind: "Application", Metadata: hlir.Metadata{Name: "a"}}
+	app.Spec.Role = hlir.Role("worker")
+	app.Spec.Code = []hlir.Code{{Name: "main.sh", Source: "echo hi"}}
+	app.Spec.Description = "d"
+	app.Spec.SupportsGpu = true
+	app.Spec.Expose = []string{"8080"}
+	app.Spec.MinMemory = "512Mi"
+	app.Spec.Tags = []string{"t"}
+	app.Spec.Command = "./main.sh"
+	app.Spec.Image = "docker.io/alpine:3"
+	app.Spec.Env = hlir.Env{"A": "1"}
+	app.Spec.Datasets = []hlir.Dataset{{Name: "ds"}}
+	app.Spec.SecurityContext = hlir.SecurityContext{RunAsUser: 1000}
+	app.Spec.ContainerSecurityContext = hlir.ContainerSecurityContext{RunAsUser: 1000}
+	app.Spec.Needs = []hlir.Needs{{Name: "python", Version: "3.12"}}
+	app.Spec.IsDispatcher = true
+	app.Spec.CallingConvention = hlir.CallingConventionStdio
+	app.Spec.TestData = hlir.TestData{{Name: "td"}}
+	return app
+}
+
+func workerPool() v1alpha1.WorkerPool {
+	pool := v1alpha1.WorkerPool{ApiVersion: v1alpha1.ApiVersion, Kind: "WorkerPool", Metadata: hlir.Metadata{Name: "p"}}
+	pool.Spec.StartupDelay = "5s"
+	pool.Spec.Env = hlir.Env{"A": "1"}
+	pool.Spec.Workers.Count = 2
+	pool.Spec.Workers.MinMemory = "2Gi"
+	pool.Spec.Workers.Size = "xxs"
+	pool.Spec.Scheduling.Cpu = "2"
+	pool.Spec.Application.Tags = []string{"t"}
+	return pool
+}
+
+// Fail if any field of the struct v is left unset
+func checkAllSet(t *testing.T, what string, v reflect.Value) {
+	t.Helper()
+	for i := range v.NumField() {
+		if v.Field(i).IsZero() {
+			t.Errorf("test fixture does not set %s.%s", what, v.Type().Field(i).Name)
+		}
+	}
+}
+
+func TestFixturesAreComplete(t *testing.T) {
+	checkAllSet(t, "Application.Spec", reflect.ValueOf(application().Spec))
+	pool := workerPool().Spec
+	checkAllSet(t, "WorkerPool.Spec", reflect.ValueOf(pool))
+	checkAllSet(t, "WorkerPool.Spec.Workers", reflect.ValueOf(pool.Workers))
+	checkAllSet(t, "WorkerPool.Spec.Application", reflect.ValueOf(pool.Application))
+}
+
+func TestApplicationRoundTrip(t *testing.T) {
+	in := application()
+	out := v1beta1.ApplicationFromV1alpha1(in)
+
+	if out.ApiVersion != v1beta1.ApiVersion {
+		t.Errorf("expected apiVersion %s, got %s", v1beta1.ApiVersion, out.ApiVersion)
+	}
+	if out.Spec.Resources.MinMemory != in.Spec.MinMemory || out.Spec.Resources.Gpu != in.Spec.SupportsGpu {
+		t.Errorf("expected minMemory and supportsGpu under resources, got %+v", out.Spec.Resources)
+	}
+
+	back := out.ToV1alpha1()
+	back.ApiVersion = in.ApiVersion
+	if !reflect.DeepEqual(in, back) {
+		t.Errorf("round trip changed the Application\n in: %+v\nout: %+v", in, back)
+	}
+}
+
+func TestWorkerPoolRoundTrip(t *testing.T) {
+	in := workerPool()
+	out := v1beta1.WorkerPoolFromV1alpha1(in)
+
+	if out.ApiVersion != v1beta1.ApiVersion {
+		t.Errorf("expected apiVersion %s, got %s", v1beta1.ApiVersion, out.ApiVersion)
+	}
+	if out.Spec.Scheduling.MinMemory != in.Spec.Workers.MinMemory {
+		t.Errorf("expected minMemory under scheduling, got %+v", out.Spec.Scheduling)
+	}
+
+	back := out.ToV1alpha1()
+	back.ApiVersion = in.ApiVersion
+	if !reflect.DeepEqual(in, back) {
+		t.Errorf("round trip changed the WorkerPool\n in: %+v\nout: %+v", in, back)
+	}
+}
+
+// The conversions and the YAML migrations must agree: decoding
+// migrated YAML as v1beta1 gives what decoding the original as
+// v1alpha1 did
+func TestConvertAgreesWithMigrate(t *testing.T) {
+	appYaml, err := yaml.Marshal(application())
+	if err != nil {
+		t.Fatal(err)
+	}
+	poolYaml, err := yaml.Marshal(workerPool())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, changed, err := migrate.Migrate(string(appYaml) + "---\n" + string(poolYaml))
+	if err != nil {
+		t.Fatal(err)
+	} else if !changed {
+		t.Fatal("expected the migration to change something")
+	}
+
+	dec := yaml.NewDecoder(strings.NewReader(migrated))
+	var app v1beta1.Application
+	if err := dec.Decode(&app); err != nil {
+		t.Fatal(err)
+	}
+	var pool v1beta1.WorkerPool
+	if err := dec.Decode(&pool); err != nil {
+		t.Fatal(err)
+	}
+
+	sameYaml(t, v1beta1.ApplicationFromV1alpha1(application()), app)
+	sameYaml(t, v1beta1.WorkerPoolFromV1alpha1(workerPool()), pool)
+}
+
+// Compare as YAML, as decoding may turn a nil slice into an empty one
+func sameYaml(t *testing.T, converted, migrated any) {
+	t.Helper()
+	a, err := yaml.Marshal(converted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := yaml.Marshal(migrated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(a) != string(b) {
+		t.Errorf("migrated YAML differs from converted\nconverted:\n%s\nmigrated:\n%s", a, b)
+	}
+}
@@ -0,0 +1,37 @@
+package v1beta1
+
+import "lunchpail.io/pkg/ir/hlir"
+
+// Where and how the workers of a WorkerPool should be scheduled, and
+// what each needs
+type Scheduling struct {
+	hlir.Scheduling `yaml:",inline"`
+
+	// e.g. 2Gi; in v1alpha1, spec.workers.minMemory
+	MinMemory string `yaml:"minMemory,omitempty"`
+}
+
+type WorkerPoolSpec struct {
+	StartupDelay string   `yaml:"startupDelay,omitempty"`
+	Env          hlir.Env `yaml:"env,omitempty"`
+	Workers      struct {
+		Count int `yaml:"count"`
+
+		// e.g. xxs or auto. Accepted, as older applications
+		// specify it, but not used.
+		Size string `yaml:"size,omitempty"`
+	} `yaml:"workers"`
+	Scheduling Scheduling `yaml:"scheduling,omitempty"`
+
+	// Run the worker Application that carries all of these tags
+	Application struct {
+		Tags []string `yaml:"tags,omitempty"`
+	} `yaml:"application,omitempty"`
+}
+
+type WorkerPool struct {
+	ApiVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   hlir.Metadata  `yaml:"metadata"`
+	Spec       WorkerPoolSpec `yaml:"spec"`
+}
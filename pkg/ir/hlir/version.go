@@ -0,0 +1,38 @@
+package hlir
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The apiVersions of HLIR resources. The types in this package have
+// the shape of V1alpha1; see the v1beta1 package for how newer
+// versions convert to them.
+const (
+	V1alpha1 = "lunchpail.io/v1alpha1"
+	V1beta1  = "lunchpail.io/v1beta1"
+)
+
+// Oldest first
+var ApiVersions = []string{V1alpha1, V1beta1}
+
+// What `dev migrate` rewrites application YAML to
+const LatestApiVersion = V1beta1
+
+// Resolve the given apiVersion to one of ApiVersions. Early
+// applications, and those built by older versions of lunchpail, may
+// omit the group, or the apiVersion altogether.
+func ParseApiVersion(apiVersion string) (string, error) {
+	switch apiVersion {
+	case "", "v1alpha1":
+		return V1alpha1, nil
+	}
+
+	for _, v := range ApiVersions {
+		if v == apiVersion {
+			return v, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unsupported apiVersion %s, expected one of %s", apiVersion, strings.Join(ApiVersions, ", "))
+}
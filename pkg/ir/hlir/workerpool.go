@@ -10,13 +10,23 @@ type WorkerPool struct {
 		Workers      struct {
 			Count     int
 			MinMemory string `yaml:"minMemory,omitempty"`
+
+			// e.g. xxs or auto. Accepted, as older
+			// applications specify it, but not used.
+			Size string `yaml:"size,omitempty"`
 		}
+		Scheduling Scheduling `yaml:"scheduling,omitempty"`
+
+		// Run the worker Application that carries all of these tags
+		Application struct {
+			Tags []string `yaml:"tags,omitempty"`
+		} `yaml:"application,omitempty"`
 	}
 }
 
 func NewPool(name string, count int) WorkerPool {
 	p := WorkerPool{
-		ApiVersion: "v1alpha1",
+		ApiVersion: V1alpha1,
 		Kind:       "WorkerPool",
 		Metadata:   Metadata{Name: name},
 	}
@@ -1,5 +1,11 @@
 package llir
 
+import (
+	"slices"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
 type LLIR struct {
 	AppName string
 
@@ -12,6 +18,9 @@ type LLIR struct {
 
 	// One Component per WorkerPool, one for WorkerStealer, etc.
 	Components []ShellComponent
+
+	// Where to send notifications of run events
+	Alerts []hlir.AlertSpec
 }
 
 func (ir LLIR) HasDispatcher() bool {
@@ -23,3 +32,18 @@ func (ir LLIR) HasDispatcher() bool {
 
 	return false
 }
+
+// The sorted union of the tags of the applications in this run
+func (ir LLIR) Tags() []string {
+	tags := []string{}
+	for _, c := range ir.Components {
+		for _, tag := range c.Application.Spec.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	slices.Sort(tags)
+	return tags
+}
@@ -26,6 +26,9 @@ type ShellComponent struct {
 
 	// Sizing of this instance
 	MinMemoryBytes uint64
+
+	// Resource and placement requirements of the workers
+	Scheduling hlir.Scheduling
 }
 
 // part of llir.Component interface
@@ -0,0 +1,44 @@
+package queue
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Components may be handed the queue credentials via a file named by
+// this environment variable, rather than via the environment itself
+const CredentialsFileEnvVar = "lunchpail_queue_credentials"
+
+type Credentials struct {
+	AccessKeyID     string `json:"accessKeyID"`
+	SecretAccessKey string `json:"secretAccessKey"`
+
+	// For short-lived credentials
+	SessionToken string `json:"sessionToken,omitempty"`
+}
+
+func (spec Spec) Credentials() Credentials {
+	return Credentials{AccessKeyID: spec.AccessKey, SecretAccessKey: spec.SecretKey}
+}
+
+// Write the credentials to a file readable only by the current user
+func WriteCredentials(path string, creds Credentials) error {
+	b, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0600)
+}
+
+func ReadCredentials(path string) (Credentials, error) {
+	var creds Credentials
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return creds, err
+	}
+
+	err = json.Unmarshal(b, &creds)
+	return creds, err
+}
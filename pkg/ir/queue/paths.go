@@ -11,6 +11,15 @@ const (
 	FinishedWithCode           = "lunchpail/run/{{.RunName}}/meta/step/{{.Step}}/exitcode/pool/{{.PoolName}}/worker/{{.WorkerName}}/{{.Task}}"
 	FinishedWithStdout         = "lunchpail/run/{{.RunName}}/meta/step/{{.Step}}/stdout/pool/{{.PoolName}}/worker/{{.WorkerName}}/{{.Task}}"
 	FinishedWithStderr         = "lunchpail/run/{{.RunName}}/meta/step/{{.Step}}/stderr/pool/{{.PoolName}}/worker/{{.WorkerName}}/{{.Task}}"
+	TaskAttempts               = "lunchpail/run/{{.RunName}}/meta/step/{{.Step}}/attempts/{{.Task}}"
+	TaskClaim                  = "lunchpail/run/{{.RunName}}/meta/step/{{.Step}}/claimed/{{.Task}}"
+	Logs                       = "lunchpail/run/{{.RunName}}/meta/logs"
+	LogsArchiver               = "lunchpail/run/{{.RunName}}/meta/logs-archiver" // someone will archive the logs before the queue is torn down
+	LogsFlushed                = "lunchpail/run/{{.RunName}}/meta/logs-flushed"  // the queue has shipped its final logs
+	LogsArchived               = "lunchpail/run/{{.RunName}}/meta/logs-archived" // the queue may now be torn down
+	Alerts                     = "lunchpail/run/{{.RunName}}/meta/alerts"
+	CredentialsRequest         = "lunchpail/run/{{.RunName}}/meta/credentials/request/step/{{.Step}}/pool/{{.PoolName}}/worker/{{.WorkerName}}" // a worker asks for scoped credentials for its handler
+	ScopedCredentials          = "lunchpail/run/{{.RunName}}/meta/credentials/scoped/step/{{.Step}}/pool/{{.PoolName}}/worker/{{.WorkerName}}"  // ... and the queue server answers here
 	FinishedWithSucceeded      = "lunchpail/run/{{.RunName}}/queue/step/{{.Step}}/succeeded/pool/{{.PoolName}}/worker/{{.WorkerName}}/{{.Task}}"
 	FinishedWithFailed         = "lunchpail/run/{{.RunName}}/queue/step/{{.Step}}/failed/pool/{{.PoolName}}/worker/{{.WorkerName}}/{{.Task}}"
 	WorkerKillFile             = "lunchpail/run/{{.RunName}}/queue/step/{{.Step}}/killfiles/pool/{{.PoolName}}/worker/{{.WorkerName}}"
@@ -19,4 +28,9 @@ const (
 	WorkerAliveMarker          = "lunchpail/run/{{.RunName}}/queue/step/{{.Step}}/marker/alive/pool/{{.PoolName}}/worker/{{.WorkerName}}"
 	WorkerDeadMarker           = "lunchpail/run/{{.RunName}}/queue/step/{{.Step}}/marker/dead/pool/{{.PoolName}}/worker/{{.WorkerName}}"
 	Blobs                      = "lunchpail/run/{{.RunName}}/blobs"
+	Wheelhouse                 = "lunchpail/run/{{.RunName}}/wheelhouse"       // Python wheels, for workers whose executable does not carry them
+	WheelhouseReadyMarker      = "lunchpail/run/{{.RunName}}/wheelhouse-ready" // ... all of which have been uploaded
+
+	Code            = "lunchpail/run/{{.RunName}}/code/step/{{.Step}}"       // binary code, e.g. executables, that Kubernetes workers cannot take from a ConfigMap
+	CodeReadyMarker = "lunchpail/run/{{.RunName}}/code-ready/step/{{.Step}}" // ... all of which has been uploaded
 )
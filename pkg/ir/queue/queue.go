@@ -7,6 +7,29 @@ type Spec struct {
 	Port      int    `json:"port"`
 	AccessKey string `json:"accessKey"`
 	SecretKey string `json:"secretKey"`
+
+	// Consume the credentials directly from this Kubernetes
+	// Secret, rather than from AccessKey and SecretKey
+	SecretRef string `json:"secretRef,omitempty"`
+
+	// Serve the internal queue over TLS
+	TLS *TLS `json:"tls,omitempty"`
+}
+
+// A per-run certificate authority, and a server certificate signed
+// by it, all PEM-encoded
+type TLS struct {
+	CA   string `json:"ca"`
+	Cert string `json:"cert"`
+	Key  string `json:"key,omitempty"`
+}
+
+// The CA that clients should trust, or "" if not using TLS
+func (spec Spec) CA() string {
+	if spec.TLS == nil {
+		return ""
+	}
+	return spec.TLS.CA
 }
 
 func (spec Spec) UpdateEndpoint(endpoint string) Spec {
@@ -18,3 +41,15 @@ func (spec Spec) NoAuto() Spec {
 	spec.Auto = false
 	return spec
 }
+
+// Without the credentials, e.g. for saving to disk
+func (spec Spec) WithoutCredentials() Spec {
+	spec.AccessKey = ""
+	spec.SecretKey = ""
+	if spec.TLS != nil {
+		tls := *spec.TLS
+		tls.Key = ""
+		spec.TLS = &tls
+	}
+	return spec
+}
@@ -0,0 +1,95 @@
+package info
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/fe"
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/observe/colors"
+)
+
+// Compare this application with the one built into the given binary
+func Diff(other string) error {
+	opts, err := build.RestoreOptions()
+	if err != nil {
+		return err
+	}
+
+	before, err := fe.PrepareHLIR(opts)
+	if err != nil {
+		return err
+	}
+	beforeName := build.Name()
+
+	if err := build.LoadFrom(other); err != nil {
+		return err
+	} else if opts, err = build.RestoreOptions(); err != nil {
+		return err
+	}
+
+	after, err := fe.PrepareHLIR(opts)
+	if err != nil {
+		return err
+	}
+
+	changes, err := hlir.Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s -> %s\n", colors.Bold.Render("Comparing"), colors.Cyan.Render(beforeName), colors.Cyan.Render(build.Name()))
+	PrintDiff(os.Stdout, changes)
+	return nil
+}
+
+// Print the given changes, grouped by resource
+func PrintDiff(w io.Writer, changes []hlir.Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, colors.Dim.Render("No changes to the application"))
+		return
+	}
+
+	resource := ""
+	for _, c := range changes {
+		if c.Field == "" {
+			fmt.Fprintln(w, style(c).Render(c.String()))
+			resource = ""
+			continue
+		}
+
+		if c.Resource != resource {
+			fmt.Fprintln(w, colors.Bold.Render(c.Resource))
+			resource = c.Resource
+		}
+		fmt.Fprintf(w, "  %s\n", style(c).Render(c.String()))
+		for _, line := range c.Lines() {
+			fmt.Fprintf(w, "      %s\n", lineStyle(line).Render(line))
+		}
+	}
+}
+
+func style(c hlir.Change) lipgloss.Style {
+	switch {
+	case c.IsAddition():
+		return colors.Green
+	case c.IsRemoval():
+		return colors.Red
+	}
+	return colors.Yellow
+}
+
+func lineStyle(line string) lipgloss.Style {
+	switch {
+	case strings.HasPrefix(line, "+ "):
+		return colors.Green
+	case strings.HasPrefix(line, "- "):
+		return colors.Red
+	}
+	return colors.Dim
+}
@@ -0,0 +1,24 @@
+package info
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"lunchpail.io/pkg/build"
+)
+
+// Print the bill of materials of this build
+func BillOfMaterials() error {
+	sbom, err := build.BillOfMaterials()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(sbom, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(b))
+	return nil
+}
@@ -0,0 +1,38 @@
+package info
+
+import (
+	"fmt"
+	"os"
+
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/observe/colors"
+)
+
+// Check the given binary against its detached signature and the given
+// trusted public key. A binary cannot vouch for itself, so if none is
+// given, we check ourselves only as a convenience.
+func Verify(exe, sigPath, pubKeyPath string) error {
+	if pubKeyPath == "" {
+		return fmt.Errorf("Please provide the trusted public key of whoever signed %s", exe)
+	}
+
+	if exe == "" {
+		self, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		exe = self
+		fmt.Fprintln(os.Stderr, "Note: this only shows that this binary matches its signature. To check that it was not tampered with, verify it with a binary you trust, e.g. `lunchpail verify "+exe+" --key "+pubKeyPath+"`")
+	}
+
+	if sigPath == "" {
+		sigPath = build.SignatureFile(exe)
+	}
+
+	if err := build.Verify(exe, sigPath, pubKeyPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s\n", colors.Green.Render("Verified"), exe)
+	return nil
+}
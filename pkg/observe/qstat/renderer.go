@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
@@ -38,20 +39,27 @@ func newRenderer(run queue.RunContext) renderer {
 		re.NewStyle().Padding(0, 1), // Live
 		re.NewStyle().Bold(true).Background(lipgloss.Color("2")).Foreground(black).Padding(0, 1), // Done
 		re.NewStyle().Bold(true).Background(lipgloss.Color("1")).Foreground(black).Padding(0, 1), // Fail
+		re.NewStyle().Bold(true).Foreground(lipgloss.Color("5")).Padding(0, 1),                   // Slow
 	}
 
 	return renderer{run, 0, highlight, italic, dead, styles}
 }
 
-func (r renderer) workerRow(stepIdx int, t *table.Table, worker queuestreamer.Worker, isAlive bool) {
+func (r renderer) workerRow(step queuestreamer.Step, t *table.Table, worker queuestreamer.Worker, isAlive bool) {
+	slow := ""
+	if n := step.NumStragglers(worker); n > 0 {
+		slow = strconv.Itoa(n)
+	}
+
 	t.Row(
-		strconv.Itoa(stepIdx),
+		strconv.Itoa(step.Index),
 		worker.Pool,
 		r.name(worker.Pool, worker.Name, isAlive),
 		strconv.Itoa(len(worker.AssignedTasks)),
 		strconv.Itoa(len(worker.ProcessingTasks)),
 		strconv.FormatUint(uint64(worker.NSuccess), 10),
 		strconv.FormatUint(uint64(worker.NFail), 10),
+		slow,
 	)
 }
 
@@ -72,7 +80,7 @@ func (r renderer) showInbox(step queuestreamer.Step) bool {
 	return len(step.UnassignedTasks) > 0
 }
 
-func (r *renderer) render(t *table.Table) {
+func (r *renderer) render(t *table.Table, footer string) {
 	if r.prevNumRows > 0 {
 		reset := ""
 		for range r.prevNumRows + 1 {
@@ -80,7 +88,7 @@ func (r *renderer) render(t *table.Table) {
 		}
 		fmt.Printf(reset)
 	}
-	s := t.Render()
+	s := t.Render() + footer
 	r.prevNumRows = strings.Count(s, "\n")
 	fmt.Println(s)
 }
@@ -99,7 +107,7 @@ func (r renderer) table(model queuestreamer.Model) *table.Table {
 	t := table.New().
 		Border(lipgloss.NormalBorder()).
 		BorderStyle(lipgloss.NewStyle().Foreground(r.highlight)).
-		Headers("Step", "Pool", "Worker", "Pend", "Live", "Done", "Fail").
+		Headers("Step", "Pool", "Worker", "Pend", "Live", "Done", "Fail", "Slow").
 		StyleFunc(func(row, col int) lipgloss.Style {
 			switch {
 			case row == table.HeaderRow:
@@ -121,15 +129,31 @@ func (r renderer) step(idx int, model queuestreamer.Step, t *table.Table) {
 	//t.Row("done", "", "", strconv.Itoa(model.Success), strconv.Itoa(model.Failure))
 
 	if r.showInbox(model) {
-		t.Row(strconv.Itoa(idx), r.italic.Render("queued"), "", strconv.Itoa(len(model.UnassignedTasks)), "", "", "")
+		t.Row(strconv.Itoa(idx), r.italic.Render("queued"), "", strconv.Itoa(len(model.UnassignedTasks)), "", "", "", "")
 	}
 
 	for _, worker := range model.LiveWorkers {
-		r.workerRow(idx, t, worker, true)
+		r.workerRow(model, t, worker, true)
 	}
 	for _, worker := range model.DeadWorkers {
-		r.workerRow(idx, t, worker, false)
+		r.workerRow(model, t, worker, false)
+	}
+}
+
+// Task duration statistics and stragglers, one line per step that has any
+func (r renderer) durations(model queuestreamer.Model) string {
+	var b strings.Builder
+	for _, step := range model.Steps {
+		if step.Durations.N == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\nStep %d task durations: %s", step.Index, step.Durations)
+		for _, s := range step.Stragglers {
+			fmt.Fprintf(&b, "\n  Straggler %s on %s has been running for %s", s.Task, r.name(s.Pool, s.Worker, true), s.Elapsed.Round(time.Second))
+		}
 	}
+	return b.String()
 }
 
 func (r renderer) name(pool, worker string, isAlive bool) string {
@@ -56,7 +56,7 @@ func UI(ctx context.Context, runnameIn string, backend be.Backend, opts Options)
 					r.step(idx, step, t)
 				}
 				// fmt.Printf("%s\tWorkers: %d\n", model.Timestamp, model.LiveWorkers())
-				r.render(t)
+				r.render(t, r.durations(model))
 			})
 		}
 
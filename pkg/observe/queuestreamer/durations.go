@@ -0,0 +1,166 @@
+package queuestreamer
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Don't judge stragglers until at least this many tasks have succeeded
+var minTasksForStragglers = 5
+
+// A task is a straggler if it has been processing this many times
+// longer than the median...
+var stragglerFactor = 3.0
+
+// ...and for at least this long
+var minStragglerTime = 10 * time.Second
+
+// Summary of how long tasks have taken to complete
+type DurationStats struct {
+	// Number of completed tasks covered by these statistics
+	N int
+
+	P50 time.Duration
+	P95 time.Duration
+	Max time.Duration
+}
+
+func (stats DurationStats) String() string {
+	return fmt.Sprintf("n=%d p50=%s p95=%s max=%s", stats.N, stats.P50.Round(time.Millisecond), stats.P95.Round(time.Millisecond), stats.Max.Round(time.Millisecond))
+}
+
+// A Task that has been processing for much longer than its peers
+type Straggler struct {
+	AssignedTask
+
+	// How long the Task has been processing
+	Elapsed time.Duration
+}
+
+// The processing marker for a Task disappears once the Task
+// completes, so we need to remember start times across Models.
+type durationTracker struct {
+	// Start time of each copy of a Task, indexed by step/task and
+	// then by pool/worker. A Task may have more than one copy, if
+	// it was speculatively duplicated, or reassigned after its
+	// worker died.
+	started map[string]map[string]time.Time
+
+	// Tasks we have already accounted for, indexed by step/task
+	done map[string]bool
+
+	// Sorted durations of successful tasks, indexed by step
+	durations map[int][]time.Duration
+}
+
+func newDurationTracker() *durationTracker {
+	return &durationTracker{
+		started:   make(map[string]map[string]time.Time),
+		done:      make(map[string]bool),
+		durations: make(map[int][]time.Duration),
+	}
+}
+
+func taskKey(step int, task AssignedTask) string {
+	return fmt.Sprintf("%d/%s", step, task.Task)
+}
+
+func copyKey(task AssignedTask) string {
+	return task.Pool + "/" + task.Worker
+}
+
+// Update the duration statistics and stragglers of the given model
+func (t *durationTracker) observe(model *Model, now time.Time) {
+	for i := range model.Steps {
+		step := &model.Steps[i]
+
+		for _, task := range step.ProcessingTasks {
+			k := taskKey(step.Index, task)
+			if t.done[k] {
+				continue
+			}
+			if _, ok := t.started[k]; !ok {
+				t.started[k] = make(map[string]time.Time)
+			}
+			if _, ok := t.started[k][copyKey(task)]; !ok {
+				t.started[k][copyKey(task)] = task.Modified
+			}
+		}
+
+		// Whichever copy of a Task completes first, we are
+		// done with all of its copies
+		for _, task := range step.SuccessfulTasks {
+			k := taskKey(step.Index, task)
+			if t.done[k] {
+				continue
+			}
+			t.done[k] = true
+
+			if start, ok := t.started[k][copyKey(task)]; ok {
+				if d := task.Modified.Sub(start); d >= 0 {
+					ds := t.durations[step.Index]
+					idx, _ := slices.BinarySearch(ds, d)
+					t.durations[step.Index] = slices.Insert(ds, idx, d)
+				}
+			}
+			delete(t.started, k)
+		}
+
+		for _, task := range step.FailedTasks {
+			k := taskKey(step.Index, task)
+			t.done[k] = true
+			delete(t.started, k)
+		}
+
+		step.Durations = stats(t.durations[step.Index])
+		step.Stragglers = stragglers(*step, now)
+	}
+}
+
+func stats(sorted []time.Duration) DurationStats {
+	n := len(sorted)
+	if n == 0 {
+		return DurationStats{}
+	}
+
+	return DurationStats{
+		N:   n,
+		P50: sorted[n/2],
+		P95: sorted[min(n-1, n*95/100)],
+		Max: sorted[n-1],
+	}
+}
+
+func stragglers(step Step, now time.Time) []Straggler {
+	if step.Durations.N < minTasksForStragglers {
+		return nil
+	}
+
+	threshold := max(time.Duration(stragglerFactor*float64(step.Durations.P50)), minStragglerTime)
+
+	S := []Straggler{}
+	for _, task := range step.ProcessingTasks {
+		if elapsed := now.Sub(task.Modified); elapsed > threshold {
+			S = append(S, Straggler{task, elapsed})
+		}
+	}
+
+	slices.SortFunc(S, func(a, b Straggler) int {
+		return cmp.Compare(b.Elapsed, a.Elapsed)
+	})
+
+	return S
+}
+
+// How many stragglers is the given worker processing?
+func (step Step) NumStragglers(worker Worker) int {
+	n := 0
+	for _, s := range step.Stragglers {
+		if s.Pool == worker.Pool && s.Worker == worker.Name {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,158 @@
+package queuestreamer
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func at(pool, worker, task string, seconds int) AssignedTask {
+	return AssignedTask{pool, worker, task, t0.Add(time.Duration(seconds) * time.Second)}
+}
+
+func TestStats(t *testing.T) {
+	seconds := func(n ...int) []time.Duration {
+		ds := []time.Duration{}
+		for _, s := range n {
+			ds = append(ds, time.Duration(s)*time.Second)
+		}
+		return ds
+	}
+	oneToHundred := []int{}
+	for i := 1; i <= 100; i++ {
+		oneToHundred = append(oneToHundred, i)
+	}
+
+	tests := []struct {
+		name   string
+		sorted []time.Duration
+		want   DurationStats
+	}{
+		{"empty", nil, DurationStats{}},
+		{"one", seconds(7), DurationStats{N: 1, P50: 7 * time.Second, P95: 7 * time.Second, Max: 7 * time.Second}},
+		{"two", seconds(1, 3), DurationStats{N: 2, P50: 3 * time.Second, P95: 3 * time.Second, Max: 3 * time.Second}},
+		{"five", seconds(1, 2, 3, 4, 50), DurationStats{N: 5, P50: 3 * time.Second, P95: 50 * time.Second, Max: 50 * time.Second}},
+		{"hundred", seconds(oneToHundred...), DurationStats{N: 100, P50: 51 * time.Second, P95: 96 * time.Second, Max: 100 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stats(tt.sorted); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDurationTracker(t *testing.T) {
+	// Each observation is the model as of a moment in time
+	type observation struct {
+		processing []AssignedTask
+		succeeded  []AssignedTask
+		failed     []AssignedTask
+	}
+
+	tests := []struct {
+		name         string
+		observations []observation
+		want         []time.Duration
+	}{
+		{
+			"one task",
+			[]observation{
+				{processing: []AssignedTask{at("p", "w0", "t1", 0)}},
+				{succeeded: []AssignedTask{at("p", "w0", "t1", 5)}},
+			},
+			[]time.Duration{5 * time.Second},
+		},
+		{
+			"completion seen again",
+			[]observation{
+				{processing: []AssignedTask{at("p", "w0", "t1", 0)}},
+				{succeeded: []AssignedTask{at("p", "w0", "t1", 5)}},
+				{succeeded: []AssignedTask{at("p", "w0", "t1", 5)}},
+			},
+			[]time.Duration{5 * time.Second},
+		},
+		{
+			"completion without a start",
+			[]observation{
+				{succeeded: []AssignedTask{at("p", "w0", "t1", 5)}},
+			},
+			nil,
+		},
+		{
+			"speculative copy finishes first",
+			[]observation{
+				{processing: []AssignedTask{at("p", "w0", "t1", 0)}},
+				{processing: []AssignedTask{at("p", "w0", "t1", 0), at("p", "w1", "t1", 30)}},
+				{succeeded: []AssignedTask{at("p", "w1", "t1", 40)}, processing: []AssignedTask{at("p", "w0", "t1", 0)}},
+				{succeeded: []AssignedTask{at("p", "w1", "t1", 40), at("p", "w0", "t1", 60)}},
+			},
+			[]time.Duration{10 * time.Second},
+		},
+		{
+			"retry after a dead worker",
+			[]observation{
+				{processing: []AssignedTask{at("p", "w0", "t1", 0)}},
+				{processing: []AssignedTask{at("p", "w1", "t1", 100)}},
+				{succeeded: []AssignedTask{at("p", "w1", "t1", 103)}},
+			},
+			[]time.Duration{3 * time.Second},
+		},
+		{
+			"failure",
+			[]observation{
+				{processing: []AssignedTask{at("p", "w0", "t1", 0), at("p", "w1", "t2", 0)}},
+				{failed: []AssignedTask{at("p", "w0", "t1", 2)}, succeeded: []AssignedTask{at("p", "w1", "t2", 4)}},
+			},
+			[]time.Duration{4 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newDurationTracker()
+			for _, o := range tt.observations {
+				model := Model{Steps: []Step{{ProcessingTasks: o.processing, SuccessfulTasks: o.succeeded, FailedTasks: o.failed}}}
+				tracker.observe(&model, t0)
+			}
+
+			if got := tracker.durations[0]; !slices.Equal(got, tt.want) {
+				t.Errorf("expected durations %v, got %v", tt.want, got)
+			}
+			if len(tracker.started) != 0 {
+				t.Errorf("expected no start times left for completed tasks, got %v", tracker.started)
+			}
+		})
+	}
+}
+
+func TestStragglers(t *testing.T) {
+	tracker := newDurationTracker()
+
+	var processing, succeeded []AssignedTask
+	for i := range minTasksForStragglers {
+		processing = append(processing, at("p", "w0", string(rune('a'+i)), 0))
+		succeeded = append(succeeded, at("p", "w0", string(rune('a'+i)), 10))
+	}
+	tracker.observe(&Model{Steps: []Step{{ProcessingTasks: processing}}}, t0)
+
+	slow := at("p", "w1", "slow", 10)
+	fine := at("p", "w2", "fine", 50)
+	model := Model{Steps: []Step{{SuccessfulTasks: succeeded, ProcessingTasks: []AssignedTask{slow, fine}}}}
+	tracker.observe(&model, t0.Add(60*time.Second))
+
+	step := model.Steps[0]
+	if step.Durations.P50 != 10*time.Second {
+		t.Errorf("expected a p50 of 10s, got %s", step.Durations.P50)
+	}
+	if len(step.Stragglers) != 1 || step.Stragglers[0].Task != "slow" || step.Stragglers[0].Elapsed != 50*time.Second {
+		t.Errorf("expected only the slow task to straggle, got %v", step.Stragglers)
+	}
+	if n := step.NumStragglers(Worker{Pool: "p", Name: "w1"}); n != 1 {
+		t.Errorf("expected w1 to have 1 straggler, got %d", n)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"slices"
 	"strconv"
+	"time"
 )
 
 // We want to identify four classes of changes:
@@ -101,7 +102,7 @@ func key(pool, worker string) string {
 }
 
 // We will be passed a stream of diffs
-func (model *Model) update(filepath string, patterns PathPatterns) {
+func (model *Model) update(filepath string, modified time.Time, patterns PathPatterns) {
 	what, step, pool, worker, task, err := model.whatChanged(filepath, patterns)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Invalid path", filepath, err)
@@ -153,7 +154,7 @@ func (model *Model) update(filepath string, patterns PathPatterns) {
 		}
 		w.KillfilePresent = true
 	case AssignedTaskByWorker:
-		m.AssignedTasks = append(m.AssignedTasks, AssignedTask{pool, worker, task})
+		m.AssignedTasks = append(m.AssignedTasks, AssignedTask{pool, worker, task, modified})
 		w, ok := m._workersLookup[k]
 		if !ok {
 			w = &Worker{Pool: pool, Name: worker}
@@ -161,7 +162,7 @@ func (model *Model) update(filepath string, patterns PathPatterns) {
 		}
 		w.AssignedTasks = append(w.AssignedTasks, task)
 	case ProcessingTaskByWorker:
-		m.ProcessingTasks = append(m.ProcessingTasks, AssignedTask{pool, worker, task})
+		m.ProcessingTasks = append(m.ProcessingTasks, AssignedTask{pool, worker, task, modified})
 		w, ok := m._workersLookup[k]
 		if !ok {
 			w = &Worker{Pool: pool, Name: worker}
@@ -169,7 +170,7 @@ func (model *Model) update(filepath string, patterns PathPatterns) {
 		}
 		w.ProcessingTasks = append(w.ProcessingTasks, task)
 	case SuccessfulTaskByWorker:
-		m.SuccessfulTasks = append(m.SuccessfulTasks, AssignedTask{pool, worker, task})
+		m.SuccessfulTasks = append(m.SuccessfulTasks, AssignedTask{pool, worker, task, modified})
 		w, ok := m._workersLookup[k]
 		if !ok {
 			w = &Worker{Pool: pool, Name: worker}
@@ -177,7 +178,7 @@ func (model *Model) update(filepath string, patterns PathPatterns) {
 		}
 		w.NSuccess++
 	case FailedTaskByWorker:
-		m.FailedTasks = append(m.FailedTasks, AssignedTask{pool, worker, task})
+		m.FailedTasks = append(m.FailedTasks, AssignedTask{pool, worker, task, modified})
 		w, ok := m._workersLookup[k]
 		if !ok {
 			w = &Worker{Pool: pool, Name: worker}
@@ -215,9 +216,10 @@ func (c client) fetchModel(anyStep bool) Model {
 		if c.LogOptions.Debug {
 			fmt.Fprintf(os.Stderr, "Updating model for: %s\n", o.Key)
 		}
-		m.update(o.Key, c.pathPatterns)
+		m.update(o.Key, o.LastModified, c.pathPatterns)
 	}
 
 	m.finishUp()
+	c.durations.observe(&m, time.Now())
 	return m
 }
@@ -1,12 +1,19 @@
 package queuestreamer
 
-import "slices"
+import (
+	"slices"
+	"time"
+)
 
 // A Task that was assigned to a given Worker
 type AssignedTask struct {
 	Pool   string
 	Worker string
 	Task   string
+
+	// When the Task entered its current state, e.g. when it
+	// started processing
+	Modified time.Time
 }
 
 type Worker struct {
@@ -46,6 +53,12 @@ type Step struct {
 	SuccessfulTasks []AssignedTask
 	FailedTasks     []AssignedTask
 
+	// How long successful tasks have taken to complete
+	Durations DurationStats
+
+	// Tasks that have been processing for much longer than their peers
+	Stragglers []Straggler
+
 	_workersLookup map[string]*Worker
 }
 
@@ -18,6 +18,7 @@ type client struct {
 	queue.RunContext
 	pathPatterns PathPatterns
 	build.LogOptions
+	durations *durationTracker
 }
 
 type StreamOptions struct {
@@ -31,7 +32,7 @@ type StreamOptions struct {
 }
 
 func StreamModel(ctx context.Context, s3 s3.S3Client, run queue.RunContext, modelChan chan Model, doneChan chan struct{}, opts StreamOptions) error {
-	c := client{s3, run, NewPathPatterns(run), opts.LogOptions}
+	c := client{s3, run, NewPathPatterns(run), opts.LogOptions, newDurationTracker()}
 
 	if err := c.s3.Mkdirp(c.RunContext.Bucket); err != nil {
 		return err
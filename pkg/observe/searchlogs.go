@@ -0,0 +1,103 @@
+package observe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"lunchpail.io/pkg/be"
+	"lunchpail.io/pkg/be/local/files"
+	"lunchpail.io/pkg/be/runs/util"
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/observe/colors"
+	"lunchpail.io/pkg/runtime/logs"
+	s3 "lunchpail.io/pkg/runtime/queue"
+)
+
+type SearchLogsOptions struct {
+	logs.SearchOptions
+	io.Writer
+}
+
+// Search the logs that components have shipped to the run's queue.
+// Unlike Logs(), this works across all steps and does not depend on
+// the components (or their pods) still being around: once a run is
+// done, we search the logs that `up` archived from its queue.
+func SearchLogs(ctx context.Context, runname string, backend be.Backend, opts SearchLogsOptions) error {
+	if runname == "" {
+		run, err := util.LatestP(ctx, backend, true)
+		if err != nil {
+			// Perhaps the run has been torn down
+			archived, aerr := files.LatestLogArchive()
+			if aerr != nil {
+				return err
+			}
+			run.Name = archived
+		}
+		runname = run.Name
+	}
+
+	lines, err := searchLogs(ctx, runname, backend, opts)
+	if err != nil {
+		return err
+	}
+
+	out := opts.Writer
+	if out == nil {
+		out = os.Stdout
+	}
+
+	for _, line := range lines {
+		timestamp := "            " // the per-task stdout/stderr fallback has no timestamps
+		if !line.Timestamp.IsZero() {
+			timestamp = line.Timestamp.Format("15:04:05.000")
+		}
+
+		task := ""
+		switch {
+		case line.Task != "" && line.Attempt > 0:
+			task = colors.Gray.Render(fmt.Sprintf("[%s #%d]", line.Task, line.Attempt)) + " "
+		case line.Task != "":
+			task = colors.Gray.Render(fmt.Sprintf("[%s]", line.Task)) + " "
+		}
+
+		fmt.Fprintf(out, "%s %s %s%s\n", timestamp, LogsComponentPrefix(line.Component)(line.Instance), task, line.Message)
+	}
+
+	return nil
+}
+
+func searchLogs(ctx context.Context, runname string, backend be.Backend, opts SearchLogsOptions) ([]logs.Line, error) {
+	archive, err := files.LogArchiveDir(runname)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(archive); err == nil {
+		if opts.Verbose {
+			fmt.Fprintln(os.Stderr, "Searching logs archived in", archive)
+		}
+		return logs.SearchArchive(archive, opts.SearchOptions)
+	}
+
+	c, err := s3.NewS3ClientForRun(ctx, backend, queue.RunContext{RunName: runname}, queue.Spec{}, build.LogOptions{Verbose: opts.Verbose})
+	if err != nil {
+		return nil, err
+	}
+	defer c.Stop()
+
+	lines, err := logs.Search(c.S3Client, c.RunContext, opts.SearchOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 && opts.Task != "" {
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "No archived logs found for task %s, falling back to its stdout and stderr\n", opts.Task)
+		}
+		return logs.SearchTaskOutput(c.S3Client, c.RunContext, opts.SearchOptions)
+	}
+
+	return lines, nil
+}
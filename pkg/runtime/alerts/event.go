@@ -0,0 +1,62 @@
+package alerts
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+// A run event that may be of interest to a human
+type Event struct {
+	Kind      hlir.AlertEvent `json:"event"`
+	RunName   string          `json:"run"`
+	Timestamp time.Time       `json:"timestamp"`
+	Message   string          `json:"message"`
+
+	// Task counts, omitted if zero or unknown
+	Succeeded int `json:"succeeded,omitempty"`
+	Failed    int `json:"failed,omitempty"`
+	Remaining int `json:"remaining,omitempty"`
+
+	// Index of the AlertSpec that fired this event
+	Spec int `json:"spec"`
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("[lunchpail] run %s %s: %s (succeeded=%d failed=%d remaining=%d)", e.RunName, e.Kind, e.Message, e.Succeeded, e.Failed, e.Remaining)
+}
+
+// The environment variable by which alert specs are passed to a
+// component. Webhook and Slack URLs usually embed a secret, so we keep
+// them off of the command line.
+const SpecsEnvVar = "LUNCHPAIL_ALERTS"
+
+// Serialize the given specs for passing to a component via SpecsEnvVar
+func EncodeSpecs(specs []hlir.AlertSpec) (string, error) {
+	b, err := json.Marshal(specs)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Inverse of EncodeSpecs()
+func DecodeSpecs(encoded string) ([]hlir.AlertSpec, error) {
+	specs := []hlir.AlertSpec{}
+	if encoded == "" {
+		return specs, nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &specs); err != nil {
+		return nil, fmt.Errorf("Invalid alert specification: %v", err)
+	}
+	return specs, nil
+}
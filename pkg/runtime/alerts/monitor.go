@@ -0,0 +1,237 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/observe/queuestreamer"
+	s3 "lunchpail.io/pkg/runtime/queue"
+)
+
+// Don't judge the failure rate until at least this many tasks have finished
+var minTasksForFailureRate = 10
+
+// A Monitor watches the progress of a run and fires alerts as
+// events of interest occur. Webhook and Slack sinks are fired
+// directly. Events destined for exec sinks are recorded in the queue,
+// so that the client that brought up the run can execute them
+// locally. A nil Monitor is valid, and fires nothing.
+type Monitor struct {
+	ctx    context.Context
+	client s3.S3Client
+	run    queue.RunContext
+	specs  []hlir.AlertSpec
+	stalls []time.Duration
+
+	mu           sync.Mutex
+	fired        map[string]bool
+	succeeded    int
+	failed       int
+	remaining    int
+	lastProgress time.Time
+
+	// Webhook deliveries that are still in flight
+	pending sync.WaitGroup
+}
+
+// Returns nil if there are no alert specs
+func NewMonitor(ctx context.Context, client s3.S3Client, run queue.RunContext, specs []hlir.AlertSpec) (*Monitor, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	stalls := make([]time.Duration, len(specs))
+	for idx, spec := range specs {
+		if spec.StallTimeout != "" {
+			d, err := time.ParseDuration(spec.StallTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid alert stall timeout %s: %v", spec.StallTimeout, err)
+			}
+			stalls[idx] = d
+		}
+	}
+
+	return &Monitor{
+		ctx:          ctx,
+		client:       client,
+		run:          run,
+		specs:        specs,
+		stalls:       stalls,
+		fired:        make(map[string]bool),
+		lastProgress: time.Now(),
+	}, nil
+}
+
+// Fire the start event, and begin watching for stalls
+func (m *Monitor) Start() {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fireAll(hlir.AlertOnStart, "Run has started")
+
+	go m.watchForStalls()
+}
+
+// Consider the latest state of the run
+func (m *Monitor) Update(model queuestreamer.Model) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	succeeded, failed, remaining := 0, 0, 0
+	for _, step := range model.Steps {
+		succeeded += len(step.SuccessfulTasks)
+		failed += len(step.FailedTasks)
+		remaining += len(step.UnassignedTasks) + len(step.AssignedTasks) + len(step.ProcessingTasks)
+	}
+
+	if succeeded+failed != m.succeeded+m.failed {
+		m.lastProgress = time.Now()
+		for idx := range m.specs {
+			// re-arm stall detection
+			delete(m.fired, firedKey(idx, hlir.AlertOnStall))
+		}
+	}
+	m.succeeded, m.failed, m.remaining = succeeded, failed, remaining
+
+	if failed > 0 {
+		m.fireAll(hlir.AlertOnFirstFailure, "A task has failed")
+	}
+
+	finished := succeeded + failed
+	if finished >= minTasksForFailureRate {
+		rate := float64(failed) / float64(finished)
+		for idx, spec := range m.specs {
+			if spec.FailureRate > 0 && rate >= spec.FailureRate {
+				m.fire(idx, hlir.AlertOnFailureRate, fmt.Sprintf("%.0f%% of tasks have failed", 100*rate))
+			}
+		}
+	}
+}
+
+// Fire the completion event
+func (m *Monitor) Complete() {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fireAll(hlir.AlertOnCompletion, "Run has completed")
+}
+
+// Wait for any in-flight webhook deliveries to finish
+func (m *Monitor) Wait() {
+	if m == nil {
+		return
+	}
+	m.pending.Wait()
+}
+
+func (m *Monitor) watchForStalls() {
+	interval := time.Duration(0)
+	for _, d := range m.stalls {
+		if d > 0 && (interval == 0 || d/4 < interval) {
+			interval = d / 4
+		}
+	}
+	if interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(max(interval, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.mu.Lock()
+			idle := time.Since(m.lastProgress)
+			for idx, d := range m.stalls {
+				if d > 0 && m.remaining > 0 && idle >= d {
+					m.fire(idx, hlir.AlertOnStall, fmt.Sprintf("No task has completed in %s", idle.Round(time.Second)))
+				}
+			}
+			m.mu.Unlock()
+		}
+	}
+}
+
+func firedKey(idx int, kind hlir.AlertEvent) string {
+	return strconv.Itoa(idx) + "/" + string(kind)
+}
+
+func (m *Monitor) fireAll(kind hlir.AlertEvent, message string) {
+	for idx := range m.specs {
+		m.fire(idx, kind, message)
+	}
+}
+
+// Fire the given event, at most once, for the spec at index `idx`.
+// Caller must hold the lock.
+func (m *Monitor) fire(idx int, kind hlir.AlertEvent, message string) {
+	key := firedKey(idx, kind)
+	if m.fired[key] {
+		return
+	}
+	m.fired[key] = true
+
+	spec := m.specs[idx]
+	if !spec.FiresOn(kind) {
+		return
+	}
+
+	event := Event{
+		Kind:      kind,
+		RunName:   m.run.RunName,
+		Timestamp: time.Now(),
+		Message:   message,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+		Remaining: m.remaining,
+		Spec:      idx,
+	}
+
+	fmt.Fprintf(os.Stderr, "Firing alert %s\n", event)
+
+	m.pending.Add(1)
+	go func() {
+		defer m.pending.Done()
+		if err := PostToWebhooks(m.ctx, spec, event); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	if len(spec.Exec) > 0 {
+		if err := m.record(event); err != nil {
+			fmt.Fprintf(os.Stderr, "Error recording %s alert: %v\n", kind, err)
+		}
+	}
+}
+
+// Record the event in the queue, for the client to pick up
+func (m *Monitor) record(event Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d-%d-%s", event.Timestamp.UnixNano(), event.Spec, event.Kind)
+	return m.client.PutBytes(m.run.Bucket, filepath.Join(m.run.AsFile(queue.Alerts), name), b)
+}
@@ -0,0 +1,111 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+// How long we give any one sink to accept an event
+var sinkTimeout = 10 * time.Second
+
+// Send the given event to the webhook and Slack sinks of `spec`
+func PostToWebhooks(ctx context.Context, spec hlir.AlertSpec, event Event) error {
+	if !spec.FiresOn(event.Kind) {
+		return nil
+	}
+
+	generic, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	slack, err := json.Marshal(struct {
+		Text string `json:"text"`
+	}{event.String()})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, url := range spec.Webhooks {
+		if err := post(ctx, url, generic); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, url := range spec.Slack {
+		if err := post(ctx, url, slack); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Error sending %s alert: %v", event.Kind, errs)
+	}
+	return nil
+}
+
+func post(ctx context.Context, url string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, sinkTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with %s", url, resp.Status)
+	}
+	return nil
+}
+
+// Run the exec sinks of `spec` for the given event. The event is
+// passed as JSON on stdin, and summarized in the environment.
+func Exec(ctx context.Context, spec hlir.AlertSpec, event Event) error {
+	if !spec.FiresOn(event.Kind) {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, command := range spec.Exec {
+		cctx, cancel := context.WithTimeout(ctx, sinkTimeout)
+		cmd := exec.CommandContext(cctx, "/bin/sh", "-c", command)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(),
+			"LUNCHPAIL_ALERT_EVENT="+string(event.Kind),
+			"LUNCHPAIL_ALERT_RUN="+event.RunName,
+			"LUNCHPAIL_ALERT_MESSAGE="+event.Message,
+		)
+		if err := cmd.Run(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", command, err))
+		}
+		cancel()
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Error executing %s alert: %v", event.Kind, errs)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"lunchpail.io/pkg/ir/hlir"
+)
+
+type received struct {
+	sync.Mutex
+	bodies map[string][]byte
+}
+
+func (r *received) server(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", req.Method)
+		}
+		if ct := req.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected application/json, got %s", ct)
+		}
+
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		r.Lock()
+		r.bodies[req.URL.Path] = b
+		r.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestPostToWebhooks(t *testing.T) {
+	r := &received{bodies: map[string][]byte{}}
+	s := r.server(t, http.StatusOK)
+
+	spec := hlir.AlertSpec{
+		Webhooks: []string{s.URL + "/webhook"},
+		Slack:    []string{s.URL + "/slack"},
+	}
+	event := Event{Kind: hlir.AlertOnFirstFailure, RunName: "r1", Message: "task.1 failed", Failed: 1, Remaining: 3}
+
+	if err := PostToWebhooks(context.Background(), spec, event); err != nil {
+		t.Fatal(err)
+	}
+
+	var generic Event
+	if err := json.Unmarshal(r.bodies["/webhook"], &generic); err != nil {
+		t.Fatal(err)
+	}
+	if generic.Kind != hlir.AlertOnFirstFailure || generic.RunName != "r1" || generic.Failed != 1 || generic.Remaining != 3 {
+		t.Fatalf("unexpected webhook payload %+v", generic)
+	}
+
+	var slack struct{ Text string }
+	if err := json.Unmarshal(r.bodies["/slack"], &slack); err != nil {
+		t.Fatal(err)
+	}
+	if slack.Text != event.String() {
+		t.Fatalf("unexpected slack text %q", slack.Text)
+	}
+}
+
+func TestPostToWebhooksFiltersEvents(t *testing.T) {
+	r := &received{bodies: map[string][]byte{}}
+	s := r.server(t, http.StatusOK)
+
+	spec := hlir.AlertSpec{Webhooks: []string{s.URL + "/webhook"}, On: []string{string(hlir.AlertOnCompletion)}}
+	if err := PostToWebhooks(context.Background(), spec, Event{Kind: hlir.AlertOnStall}); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.bodies) != 0 {
+		t.Fatalf("expected no delivery of an unsubscribed event, got %v", r.bodies)
+	}
+}
+
+func TestPostToWebhooksReportsFailures(t *testing.T) {
+	r := &received{bodies: map[string][]byte{}}
+	s := r.server(t, http.StatusInternalServerError)
+
+	spec := hlir.AlertSpec{Webhooks: []string{s.URL + "/webhook"}}
+	err := PostToWebhooks(context.Background(), spec, Event{Kind: hlir.AlertOnStart})
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("expected a 500 error, got %v", err)
+	}
+}
+
+func TestSpecsRoundTrip(t *testing.T) {
+	specs := []hlir.AlertSpec{{Slack: []string{"https://hooks.slack.com/services/T0/B0/secret"}, FailureRate: 0.5}}
+
+	encoded, err := EncodeSpecs(specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeSpecs(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || decoded[0].Slack[0] != specs[0].Slack[0] || decoded[0].FailureRate != 0.5 {
+		t.Fatalf("unexpected round trip %+v", decoded)
+	}
+}
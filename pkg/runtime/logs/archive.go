@@ -0,0 +1,80 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"lunchpail.io/pkg/ir/queue"
+	s3 "lunchpail.io/pkg/runtime/queue"
+)
+
+// How long the queue waits for an archiver to collect the logs, and
+// how long an archiver waits for the queue to ship its own, before
+// giving up
+var archiveTimeout = 30 * time.Second
+
+// Announce that we will archive the logs of the given run, so that
+// the queue will wait for us before it is torn down
+func WillArchive(c s3.S3Client, run queue.RunContext) error {
+	return c.Touch(run.Bucket, run.AsFile(queue.LogsArchiver))
+}
+
+// Download the log chunks of the given run into `dir`, once the
+// queue has shipped its own final logs, and then let the queue know
+// that it may be torn down
+func Archive(c s3.S3Client, run queue.RunContext, dir string) error {
+	if !waitTillExistsWithin(c, run.Bucket, run.AsFile(queue.LogsFlushed), archiveTimeout) {
+		fmt.Fprintf(os.Stderr, "Warning: archiving logs without the final logs of the queue\n")
+	}
+
+	prefix := run.AsFile(queue.Logs) + "/"
+	for o := range c.ListObjects(run.Bucket, prefix, true) {
+		if o.Err != nil {
+			return o.Err
+		}
+
+		localPath := filepath.Join(dir, strings.TrimPrefix(o.Key, prefix))
+		if _, err := os.Stat(localPath); err == nil {
+			// Chunks are immutable
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			return err
+		}
+		if err := c.Download(run.Bucket, o.Key, localPath); err != nil {
+			return err
+		}
+	}
+
+	return c.TouchP(run.Bucket, run.AsFile(queue.LogsArchived), false)
+}
+
+// Ship our final logs as the queue is about to be torn down, and
+// give any archiver a chance to collect them
+func (s *Shipper) Finish(c s3.S3Client, run queue.RunContext) {
+	s.Stop()
+
+	if err := c.Touch(run.Bucket, run.AsFile(queue.LogsFlushed)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unable to mark logs as flushed: %v\n", err)
+		return
+	}
+
+	if c.ExistsNow(run.Bucket, run.AsFile(queue.LogsArchiver)) {
+		fmt.Fprintf(os.Stderr, "Waiting for logs to be archived\n")
+		if !waitTillExistsWithin(c, run.Bucket, run.AsFile(queue.LogsArchived), archiveTimeout) {
+			fmt.Fprintf(os.Stderr, "Warning: gave up waiting for logs to be archived\n")
+		}
+	}
+}
+
+func waitTillExistsWithin(c s3.S3Client, bucket, object string, timeout time.Duration) bool {
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(500 * time.Millisecond) {
+		if c.ExistsNow(bucket, object) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package logs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/lunchpail"
+)
+
+// One line of output from a component, as archived in the queue
+type Line struct {
+	Timestamp time.Time           `json:"ts"`
+	Component lunchpail.Component `json:"component"`
+	Instance  string              `json:"instance"`
+	Step      int                 `json:"step"`
+	Task      string              `json:"task,omitempty"`
+	Attempt   int                 `json:"attempt,omitempty"`
+	Stream    string              `json:"stream"`
+	Message   string              `json:"msg"`
+}
+
+// Chunks are named by the time at which they were flushed, which
+// lets searches skip chunks that are too old without downloading them
+const chunkSuffix = ".jsonl.gz"
+
+// The prefix under which the given component instance stores its chunks
+func chunkPrefix(run queue.RunContext, component lunchpail.Component, instance string) string {
+	return filepath.Join(run.AsFile(queue.Logs), "step", strconv.Itoa(run.Step), string(component), instance)
+}
+
+func chunkName(run queue.RunContext, component lunchpail.Component, instance string, flushTime time.Time) string {
+	return filepath.Join(chunkPrefix(run, component, instance), strconv.FormatInt(flushTime.UnixNano(), 10)+chunkSuffix)
+}
+
+// Parse the flush time out of the given chunk name
+func chunkTime(name string) (time.Time, error) {
+	base := filepath.Base(name)
+	if !strings.HasSuffix(base, chunkSuffix) {
+		return time.Time{}, fmt.Errorf("Not a log chunk: %s", name)
+	}
+
+	nanos, err := strconv.ParseInt(strings.TrimSuffix(base, chunkSuffix), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, nanos), nil
+}
+
+// Serialize the given lines as gzipped JSON lines
+func encode(lines []Line) ([]byte, error) {
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	enc := json.NewEncoder(zw)
+	for _, line := range lines {
+		if err := enc.Encode(line); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
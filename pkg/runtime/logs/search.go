@@ -0,0 +1,187 @@
+package logs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/lunchpail"
+	s3 "lunchpail.io/pkg/runtime/queue"
+)
+
+type SearchOptions struct {
+	// Only include lines that match this pattern
+	Grep *regexp.Regexp
+
+	// Only include lines emitted while processing this task
+	Task string
+
+	// Only include lines emitted after this time
+	Since time.Time
+
+	// Only include lines from these components (empty means all)
+	Components []lunchpail.Component
+
+	Verbose bool
+}
+
+func (opts SearchOptions) matches(line Line) bool {
+	switch {
+	case !opts.Since.IsZero() && line.Timestamp.Before(opts.Since):
+		return false
+	case opts.Task != "" && line.Task != opts.Task:
+		return false
+	case len(opts.Components) > 0 && !slices.Contains(opts.Components, line.Component):
+		return false
+	case opts.Grep != nil && !opts.Grep.MatchString(line.Message):
+		return false
+	}
+	return true
+}
+
+// Search the log chunks that have been shipped to the queue for the
+// given run, across all steps, returning matching lines in time order
+func Search(c s3.S3Client, run queue.RunContext, opts SearchOptions) ([]Line, error) {
+	chunks := []string{}
+	for o := range c.ListObjects(run.Bucket, run.AsFile(queue.Logs)+"/", true) {
+		if o.Err != nil {
+			return nil, o.Err
+		}
+		chunks = append(chunks, o.Key)
+	}
+
+	return search(chunks, func(chunk string) (io.ReadCloser, error) { return c.Open(run.Bucket, chunk) }, opts)
+}
+
+// Search the log chunks that were archived in the given directory
+func SearchArchive(dir string, opts SearchOptions) ([]Line, error) {
+	chunks := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if !d.IsDir() && strings.HasSuffix(path, chunkSuffix) {
+			chunks = append(chunks, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return search(chunks, func(chunk string) (io.ReadCloser, error) { return os.Open(chunk) }, opts)
+}
+
+func search(chunks []string, open func(chunk string) (io.ReadCloser, error), opts SearchOptions) ([]Line, error) {
+	matches := []Line{}
+
+	for _, chunk := range chunks {
+		if !opts.Since.IsZero() {
+			// A chunk is flushed after all of its lines were
+			// emitted, so older chunks cannot contain matches
+			if flushTime, err := chunkTime(chunk); err != nil {
+				continue
+			} else if flushTime.Before(opts.Since) {
+				continue
+			}
+		}
+
+		lines, err := readChunk(chunk, open)
+		if err != nil {
+			if opts.Verbose {
+				fmt.Fprintf(os.Stderr, "Skipping unreadable log chunk %s: %v\n", chunk, err)
+			}
+			continue
+		}
+
+		for _, line := range lines {
+			if opts.matches(line) {
+				matches = append(matches, line)
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Timestamp.Before(matches[j].Timestamp) })
+	return matches, nil
+}
+
+// Fall back to the per-task stdout and stderr objects for the task
+// named in `opts`, e.g. for runs whose logs were not shipped
+func SearchTaskOutput(c s3.S3Client, run queue.RunContext, opts SearchOptions) ([]Line, error) {
+	anyStep := run.ForStep(queue.AnyStep)
+	streams := map[string]*regexp.Regexp{
+		"stdout": anyStep.PatternFor(queue.FinishedWithStdout),
+		"stderr": anyStep.PatternFor(queue.FinishedWithStderr),
+	}
+
+	matches := []Line{}
+	for o := range c.ListObjects(run.Bucket, run.AsFile(queue.Meta)+"/step/", true) {
+		if o.Err != nil {
+			return nil, o.Err
+		}
+
+		for stream, pattern := range streams {
+			match := pattern.FindStringSubmatch(o.Key)
+			if len(match) != 5 || match[4] != opts.Task {
+				continue
+			}
+
+			step, err := strconv.Atoi(match[1])
+			if err != nil {
+				continue
+			}
+
+			content, err := c.Get(run.Bucket, o.Key)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, message := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+				line := Line{Component: lunchpail.WorkersComponent, Instance: match[3], Step: step, Task: opts.Task, Stream: stream, Message: message}
+				if message != "" && (opts.Grep == nil || opts.Grep.MatchString(message)) {
+					matches = append(matches, line)
+				}
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+func readChunk(chunk string, open func(chunk string) (io.ReadCloser, error)) ([]Line, error) {
+	r, err := open(chunk)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	lines := []Line{}
+	scanner := bufio.NewScanner(zr)
+	scanner.Buffer(make([]byte, 0, 64*1024), 2*1024*1024)
+	for scanner.Scan() {
+		var line Line
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return lines, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
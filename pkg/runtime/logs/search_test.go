@@ -0,0 +1,80 @@
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/lunchpail"
+)
+
+func writeChunk(t *testing.T, dir string, flushTime time.Time, lines ...Line) {
+	t.Helper()
+
+	b, err := encode(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "step", "0", string(lines[0].Component), lines[0].Instance, filepath.Base(chunkName(queue.RunContext{RunName: "test"}, lines[0].Component, lines[0].Instance, flushTime)))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearchArchive(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	old := now.Add(-time.Hour)
+
+	writeChunk(t, dir, old,
+		Line{Timestamp: old.Add(-time.Second), Component: lunchpail.WorkersComponent, Instance: "w0", Task: "task.1", Message: "error: stale"},
+	)
+	writeChunk(t, dir, now,
+		Line{Timestamp: now.Add(-2 * time.Second), Component: lunchpail.WorkersComponent, Instance: "w0", Task: "task.2", Message: "error: boom"},
+		Line{Timestamp: now.Add(-time.Second), Component: lunchpail.WorkersComponent, Instance: "w0", Task: "task.2", Message: "ok"},
+	)
+	writeChunk(t, dir, now,
+		Line{Timestamp: now.Add(-3 * time.Second), Component: lunchpail.DispatcherComponent, Instance: "d0", Message: "error: dispatch"},
+	)
+
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want []string
+	}{
+		{"all, in time order", SearchOptions{}, []string{"error: stale", "error: dispatch", "error: boom", "ok"}},
+		{"grep", SearchOptions{Grep: regexp.MustCompile("^error")}, []string{"error: stale", "error: dispatch", "error: boom"}},
+		{"task", SearchOptions{Task: "task.2"}, []string{"error: boom", "ok"}},
+		{"since skips old chunks", SearchOptions{Since: now.Add(-10 * time.Minute)}, []string{"error: dispatch", "error: boom", "ok"}},
+		{"component", SearchOptions{Components: []lunchpail.Component{lunchpail.DispatcherComponent}}, []string{"error: dispatch"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := SearchArchive(dir, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := []string{}
+			for _, line := range lines {
+				got = append(got, line.Message)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/lunchpail"
+	s3 "lunchpail.io/pkg/runtime/queue"
+)
+
+// How often we flush buffered lines to the queue
+var flushInterval = 5 * time.Second
+
+// Flush early if we have buffered this many lines
+var flushLines = 2000
+
+// If the queue is unreachable, hold on to at most this many lines
+var maxBufferedLines = 50000
+
+// A Shipper captures the stdout and stderr of this process, and
+// periodically uploads the lines as compressed chunks to the run's
+// queue. A nil Shipper is valid, and ships nothing.
+type Shipper struct {
+	client    s3.S3Client
+	run       queue.RunContext
+	component lunchpail.Component
+	instance  string
+	opts      build.LogOptions
+
+	// The original stdout and stderr of this process
+	stdout *os.File
+	stderr *os.File
+
+	// The write side of our stdout and stderr capture pipes
+	stdoutW *os.File
+	stderrW *os.File
+
+	mu     sync.Mutex
+	buffer []Line
+
+	stopped sync.Once
+	capture sync.WaitGroup
+	flusher sync.WaitGroup
+	kick    chan struct{}
+	done    chan struct{}
+}
+
+// Name of this component instance, as seen by the backend
+func instanceName() string {
+	if name := os.Getenv("LUNCHPAIL_POD_NAME"); name != "" {
+		return name
+	}
+	if name, err := os.Hostname(); err == nil {
+		return name
+	}
+	return "unknown"
+}
+
+// Start capturing the output of this process, shipping it to the
+// queue on behalf of the given `component`. Callers must Stop() the
+// returned Shipper before exiting, so that the final chunk is flushed.
+func Start(ctx context.Context, client s3.S3Client, run queue.RunContext, component lunchpail.Component, opts build.LogOptions) (*Shipper, error) {
+	if run.Bucket == "" {
+		return nil, fmt.Errorf("Unable to ship logs without a queue bucket")
+	}
+
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Shipper{
+		client:    client,
+		run:       run,
+		component: component,
+		instance:  instanceName(),
+		opts:      opts,
+		stdout:    os.Stdout,
+		stderr:    os.Stderr,
+		stdoutW:   stdoutW,
+		stderrW:   stderrW,
+		kick:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+
+	os.Stdout = stdoutW
+	os.Stderr = stderrW
+
+	s.capture.Add(2)
+	go s.tee(stdoutR, s.stdout, "stdout")
+	go s.tee(stderrR, s.stderr, "stderr")
+
+	s.flusher.Add(1)
+	go s.flushLoop(ctx)
+
+	return s, nil
+}
+
+// Stop capturing output, and flush anything that remains. Only the
+// first call has any effect.
+func (s *Shipper) Stop() {
+	if s == nil {
+		return
+	}
+
+	s.stopped.Do(func() {
+		os.Stdout = s.stdout
+		os.Stderr = s.stderr
+		s.stdoutW.Close()
+		s.stderrW.Close()
+		s.capture.Wait()
+
+		close(s.done)
+		s.flusher.Wait()
+		s.Flush()
+	})
+}
+
+// A writer that passes each line through to our original stdout,
+// prefixed with the given task and attempt, and also ships it
+func (s *Shipper) Stdout(task string, attempt int) io.WriteCloser {
+	out := os.Stdout
+	if s != nil {
+		out = s.stdout
+	}
+	return &lineWriter{s: s, out: out, task: task, attempt: attempt, stream: "stdout"}
+}
+
+// A writer that passes each line through to our original stderr,
+// prefixed with the given task and attempt, and also ships it
+func (s *Shipper) Stderr(task string, attempt int) io.WriteCloser {
+	out := os.Stderr
+	if s != nil {
+		out = s.stderr
+	}
+	return &lineWriter{s: s, out: out, task: task, attempt: attempt, stream: "stderr"}
+}
+
+// Copy `r` to `out` line by line, shipping each line as we go
+func (s *Shipper) tee(r *os.File, out *os.File, stream string) {
+	defer s.capture.Done()
+	defer r.Close()
+
+	scanner := bufio.NewScanner(io.TeeReader(r, out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		s.add("", 0, stream, scanner.Text())
+	}
+
+	// e.g. a line that is too long for the scanner; we can no
+	// longer ship, but must continue to pass output through
+	io.Copy(out, r)
+}
+
+func (s *Shipper) add(task string, attempt int, stream, message string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buffer = append(s.buffer, Line{
+		Timestamp: time.Now(),
+		Component: s.component,
+		Instance:  s.instance,
+		Step:      s.run.Step,
+		Task:      task,
+		Attempt:   attempt,
+		Stream:    stream,
+		Message:   message,
+	})
+
+	if len(s.buffer) >= flushLines {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *Shipper) flushLoop(ctx context.Context) {
+	defer s.flusher.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Flush()
+		case <-s.kick:
+			s.Flush()
+		case <-ctx.Done():
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Upload any buffered lines as a new chunk. If the upload fails,
+// the lines are retained for the next attempt.
+func (s *Shipper) Flush() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	lines := s.buffer
+	s.buffer = nil
+	s.mu.Unlock()
+
+	if len(lines) == 0 {
+		return
+	}
+
+	if err := s.upload(lines); err != nil {
+		if s.opts.Verbose {
+			fmt.Fprintf(s.stderr, "Unable to ship %d log lines: %v\n", len(lines), err)
+		}
+
+		s.mu.Lock()
+		s.buffer = append(lines, s.buffer...)
+		if excess := len(s.buffer) - maxBufferedLines; excess > 0 {
+			s.buffer = s.buffer[excess:]
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Shipper) upload(lines []Line) error {
+	b, err := encode(lines)
+	if err != nil {
+		return err
+	}
+
+	return s.client.PutBytes(s.run.Bucket, chunkName(s.run, s.component, s.instance, time.Now()), b)
+}
+
+// Buffers partial lines written by a task until they are complete,
+// so that each line can be attributed to the task
+type lineWriter struct {
+	s       *Shipper
+	out     io.Writer
+	task    string
+	attempt int
+	stream  string
+	partial []byte
+}
+
+// The prefix we add to every line of output from the task
+func (w *lineWriter) prefix() string {
+	return fmt.Sprintf("[%s #%d] ", w.task, w.attempt)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		idx := bytes.IndexByte(w.partial, '\n')
+		if idx < 0 {
+			break
+		}
+		if err := w.emit(string(w.partial[:idx])); err != nil {
+			return 0, err
+		}
+		w.partial = w.partial[idx+1:]
+	}
+
+	return len(p), nil
+}
+
+func (w *lineWriter) emit(line string) error {
+	w.s.add(w.task, w.attempt, w.stream, line)
+	_, err := io.WriteString(w.out, w.prefix()+line+"\n")
+	return err
+}
+
+// Emit any trailing partial line
+func (w *lineWriter) Close() error {
+	if len(w.partial) > 0 {
+		line := string(w.partial)
+		w.partial = nil
+		return w.emit(line)
+	}
+	return nil
+}
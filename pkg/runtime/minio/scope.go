@@ -0,0 +1,136 @@
+package minio
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"lunchpail.io/pkg/ir/queue"
+	s3 "lunchpail.io/pkg/runtime/queue"
+)
+
+// The user code for which we mint scoped credentials
+type Role string
+
+const (
+	// A task handler may only write its outbox and its step's meta paths
+	WorkerRole Role = "worker"
+
+	// A dispatcher may only write unassigned tasks (and mark itself done)
+	DispatcherRole Role = "dispatcher"
+)
+
+// How long scoped credentials live before they must be re-minted
+var ScopedCredentialsTTL = 12 * time.Hour
+
+type statement struct {
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource []string `json:"Resource"`
+}
+
+type policy struct {
+	Version   string      `json:"Version"`
+	Statement []statement `json:"Statement"`
+}
+
+// The session policy for the given role. Each role may read and
+// write only the paths it requires; no role may delete anything, nor
+// read the credentials vended to other handlers.
+func Policy(role Role, run queue.RunContext) (string, error) {
+	runPrefix := strings.TrimSuffix(run.AsFile(queue.Meta), "/meta")
+	next := run.ForStep(run.Step + 1)
+
+	var readable, writable []string
+	switch role {
+	case WorkerRole:
+		readable = []string{
+			run.ForTask("").AsFile(queue.AssignedAndPending) + "/*",
+			run.ForTask("").AsFile(queue.AssignedAndProcessing) + "/*",
+			run.AsFile(queue.Blobs) + "/*",
+			run.AsFile(queue.AllDoneMarker),
+		}
+		writable = []string{
+			run.ForTask("").AsFile(queue.AssignedAndFinished) + "/*",
+			fmt.Sprintf("%s/step/%d/*", run.AsFile(queue.Meta), run.Step),
+		}
+	case DispatcherRole:
+		// e.g. `queue add --wait` watches for task completion
+		readable = []string{runPrefix + "/*"}
+		writable = []string{
+			run.ForTask("").AsFile(queue.Unassigned) + "/*",
+			next.ForTask("").AsFile(queue.Unassigned) + "/*",
+			run.AsFile(queue.DispatcherDoneMarker),
+			next.AsFile(queue.DispatcherDoneMarker),
+		}
+	default:
+		return "", fmt.Errorf("Unsupported role for scoped credentials: %s", role)
+	}
+
+	arns := func(paths []string) []string {
+		A := []string{}
+		for _, path := range paths {
+			A = append(A, "arn:aws:s3:::"+run.Bucket+"/"+path)
+		}
+		return A
+	}
+
+	b, err := json.Marshal(policy{
+		Version: "2012-10-17",
+		Statement: []statement{
+			{"Allow", []string{"s3:ListBucket", "s3:GetBucketLocation"}, []string{"arn:aws:s3:::" + run.Bucket}},
+			{"Allow", []string{"s3:GetObject"}, arns(readable)},
+			{"Allow", []string{"s3:PutObject"}, arns(writable)},
+
+			// No role may see the credentials we vend to others
+			{"Deny", []string{"s3:*"}, arns([]string{run.AsFile(queue.Meta) + "/credentials/*"})},
+		},
+	})
+	return string(b), err
+}
+
+// Mint short-lived credentials scoped to the given role, via the STS
+// AssumeRole API of the minio server behind the given endpoint. The
+// returned credentials re-mint themselves upon expiry.
+func Mint(endpoint, ca string, root queue.Credentials, role Role, run queue.RunContext) (*credentials.Credentials, error) {
+	policy, err := Policy(role, run)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		endpoint = "http://" + endpoint
+	}
+
+	if root.AccessKeyID == "" || root.SecretAccessKey == "" {
+		return nil, fmt.Errorf("Missing queue credentials")
+	}
+
+	transport, err := s3.TransportFor(ca)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := credentials.New(&credentials.STSAssumeRole{
+		Client:      &http.Client{Transport: transport},
+		STSEndpoint: endpoint,
+		Options: credentials.STSAssumeRoleOptions{
+			AccessKey:       root.AccessKeyID,
+			SecretKey:       root.SecretAccessKey,
+			SessionToken:    root.SessionToken,
+			Policy:          policy,
+			DurationSeconds: int(ScopedCredentialsTTL.Seconds()),
+		},
+	})
+
+	// Fail fast if the server does not support STS
+	if _, err := creds.Get(); err != nil {
+		return nil, err
+	}
+
+	return creds, nil
+}
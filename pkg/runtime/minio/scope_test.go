@@ -0,0 +1,122 @@
+package minio
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"lunchpail.io/pkg/ir/queue"
+)
+
+var testRun = queue.RunContext{Bucket: "test", RunName: "r", Step: 0, PoolName: "p", WorkerName: "w0"}
+
+// Would the given session policy allow `action` on the object `key`?
+// This follows IAM semantics: an explicit Deny beats any Allow.
+func allows(t *testing.T, p string, action, key string) bool {
+	t.Helper()
+
+	var parsed policy
+	if err := json.Unmarshal([]byte(p), &parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := false
+	for _, s := range parsed.Statement {
+		if !slices.Contains(s.Action, action) && !slices.Contains(s.Action, "s3:*") {
+			continue
+		}
+		for _, resource := range s.Resource {
+			// As in IAM, * matches any sequence of characters, including /
+			pattern := regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(resource), `\*`, ".*") + "$")
+			if ok := pattern.MatchString("arn:aws:s3:::test/" + key); ok && s.Effect == "Deny" {
+				return false
+			} else if ok && s.Effect == "Allow" {
+				allowed = true
+			}
+		}
+	}
+	return allowed
+}
+
+func TestWorkerPolicy(t *testing.T) {
+	p, err := Policy(WorkerRole, testRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := testRun.ForTask("t1")
+	tests := []struct {
+		action string
+		key    string
+		want   bool
+	}{
+		{"s3:GetObject", task.AsFile(queue.AssignedAndPending), true},
+		{"s3:PutObject", task.AsFile(queue.AssignedAndFinished), true},
+		{"s3:PutObject", task.AsFile(queue.FinishedWithStdout), true},
+
+		// Not the input of our step, nor another worker's inbox
+		{"s3:PutObject", task.AsFile(queue.Unassigned), false},
+		{"s3:PutObject", task.AsFile(queue.AssignedAndPending), false},
+		{"s3:GetObject", task.ForWorker("w1").AsFile(queue.AssignedAndPending), false},
+
+		// Nor anyone's credentials
+		{"s3:GetObject", testRun.AsFile(queue.ScopedCredentials), false},
+		{"s3:GetObject", testRun.ForWorker("w1").AsFile(queue.ScopedCredentials), false},
+		{"s3:PutObject", testRun.AsFile(queue.CredentialsRequest), false},
+
+		// Nor anything to do with tearing down the run
+		{"s3:PutObject", testRun.AsFile(queue.AllDoneMarker), false},
+		{"s3:DeleteObject", task.AsFile(queue.AssignedAndPending), false},
+	}
+
+	for _, tt := range tests {
+		if got := allows(t, p, tt.action, tt.key); got != tt.want {
+			t.Errorf("%s %s: expected %v, got %v", tt.action, tt.key, tt.want, got)
+		}
+	}
+}
+
+func TestDispatcherPolicy(t *testing.T) {
+	p, err := Policy(DispatcherRole, testRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := testRun.ForTask("t1")
+	tests := []struct {
+		action string
+		key    string
+		want   bool
+	}{
+		{"s3:PutObject", task.AsFile(queue.Unassigned), true},
+		{"s3:PutObject", testRun.AsFile(queue.DispatcherDoneMarker), true},
+		{"s3:GetObject", task.AsFile(queue.FinishedWithCode), true},
+
+		// A dispatcher may read the whole run, except for credentials
+		{"s3:GetObject", testRun.AsFile(queue.ScopedCredentials), false},
+		{"s3:PutObject", task.AsFile(queue.AssignedAndPending), false},
+		{"s3:PutObject", testRun.AsFile(queue.AllDoneMarker), false},
+	}
+
+	for _, tt := range tests {
+		if got := allows(t, p, tt.action, tt.key); got != tt.want {
+			t.Errorf("%s %s: expected %v, got %v", tt.action, tt.key, tt.want, got)
+		}
+	}
+}
+
+func TestUnknownRole(t *testing.T) {
+	if _, err := Policy(Role("root"), testRun); err == nil {
+		t.Fatal("expected an error for an unknown role")
+	}
+}
+
+func TestRolesAreTyped(t *testing.T) {
+	for _, role := range []any{WorkerRole, DispatcherRole} {
+		if _, ok := role.(Role); !ok {
+			t.Errorf("expected %v to be a Role, got %T", role, role)
+		}
+	}
+}
@@ -5,41 +5,68 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"slices"
 	"strings"
 
 	"golang.org/x/sync/errgroup"
 
+	"lunchpail.io/pkg/build"
 	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/lunchpail"
+	"lunchpail.io/pkg/runtime/logs"
 	s3 "lunchpail.io/pkg/runtime/queue"
 	"lunchpail.io/pkg/util"
 )
 
 func Server(ctx context.Context, port int, run queue.RunContext) error {
 	fmt.Fprintf(os.Stderr, "Lunchpail Minio component starting up\n")
-	fmt.Fprintf(os.Stderr, "%v\n", os.Environ())
 
-	accessKey := os.Getenv("lunchpail_queue_accessKeyID")
+	creds, err := s3.CredentialsFromEnv()
+	if err != nil {
+		return err
+	}
+
+	accessKey := creds.AccessKeyID
 	if accessKey == "" {
 		return fmt.Errorf("Missing env var lunchpail_queue_accessKeyID")
 	}
 
-	secretKey := os.Getenv("lunchpail_queue_secretAccessKey")
+	secretKey := creds.SecretAccessKey
 	if secretKey == "" {
 		return fmt.Errorf("Missing env var lunchpail_queue_secretAccessKey")
 	}
 
 	group, _ := errgroup.WithContext(ctx)
 
+	certsDir, removeCertsDir, err := prepareCertsDir()
+	if err != nil {
+		return err
+	}
+	defer removeCertsDir()
+
+	endpoint := fmt.Sprintf("localhost:%d", port)
+	if certsDir != "" {
+		endpoint = "https://" + endpoint
+	}
+
 	c, err := s3.NewS3ClientFromOptions(ctx, s3.S3ClientOptions{
-		Endpoint:        fmt.Sprintf("localhost:%d", port),
+		Endpoint:        endpoint,
 		AccessKeyID:     accessKey,
 		SecretAccessKey: secretKey,
+		CA:              os.Getenv("lunchpail_queue_ca"),
 	})
 	if err != nil {
 		return err
 	}
 
+	// Until the bucket exists, shipped lines will be buffered
+	shipper, err := logs.Start(ctx, c, run, lunchpail.MinioComponent, build.LogOptions{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unable to ship logs to the queue: %v\n", err)
+	}
+	defer shipper.Stop()
+
 	minio, err := exec.LookPath("minio")
 	if err != nil {
 		return err
@@ -52,7 +79,12 @@ func Server(ctx context.Context, port int, run queue.RunContext) error {
 
 	fmt.Fprintf(os.Stderr, "Launching Minio server with minio=%s bucket=%s run=%s\n", minio, run.Bucket, run.RunName)
 	// NOT CommandContext, as group.Wait() below will otherwise kill the minio server
-	cmd := exec.CommandContext(ctx, "minio", "server", datadir, "--address", fmt.Sprintf(":%d", port))
+	args := []string{"server", datadir, "--address", fmt.Sprintf(":%d", port)}
+	if certsDir != "" {
+		fmt.Fprintf(os.Stderr, "Serving TLS with certs=%s\n", certsDir)
+		args = append(args, "--certs-dir", certsDir)
+	}
+	cmd := exec.CommandContext(ctx, "minio", args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Env = slices.Concat(os.Environ(), []string{
@@ -69,6 +101,10 @@ func Server(ctx context.Context, port int, run queue.RunContext) error {
 	}
 	fmt.Fprintf(os.Stderr, "Ensuring bucket exists bucket=%s <-- READY!\n", run.Bucket)
 
+	// Only we hold the root credentials, so we mint the scoped
+	// credentials that workers hand to their handlers
+	go vendCredentials(ctx, c, endpoint, os.Getenv("lunchpail_queue_ca"), creds, run)
+
 	// This watches for minio server death
 	gotKillFile := false
 	group.Go(func() error {
@@ -82,6 +118,10 @@ func Server(ctx context.Context, port int, run queue.RunContext) error {
 		util.SleepBeforeExit()
 		fmt.Fprintf(os.Stderr, "Minio initiating self-destruct\n")
 
+		// Our final logs must be shipped, and archived, while
+		// the server is still up
+		shipper.Finish(c, run)
+
 		if err := cmd.Process.Kill(); err != nil {
 			return err
 		}
@@ -101,6 +141,44 @@ func Server(ctx context.Context, port int, run queue.RunContext) error {
 	return nil
 }
 
+// The directory from which minio should read its TLS certificate and
+// key, or "" if the queue does not serve TLS. The returned function
+// removes that directory, if we created it.
+func prepareCertsDir() (string, func(), error) {
+	noop := func() {}
+	if dir := os.Getenv("lunchpail_queue_certsDir"); dir != "" {
+		return dir, noop, nil
+	}
+
+	cert := os.Getenv("lunchpail_queue_tlsCert")
+	key := os.Getenv("lunchpail_queue_tlsKey")
+	if cert == "" || key == "" {
+		return "", noop, nil
+	}
+
+	dir, err := os.MkdirTemp("", "lunchpail-minio-certs-")
+	if err != nil {
+		return "", noop, err
+	}
+	cleanup := func() {
+		if err := os.RemoveAll(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to remove minio certs directory %s: %v\n", dir, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "public.crt"), []byte(cert), 0644); err != nil {
+		cleanup()
+		return "", noop, err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "private.key"), []byte(key), 0600); err != nil {
+		cleanup()
+		return "", noop, err
+	}
+
+	return dir, cleanup, nil
+}
+
 func waitForKillFile(c s3.S3Client, run queue.RunContext) error {
 	return c.WaitTillExists(run.Bucket, run.AsFile(queue.AllDoneMarker))
 }
@@ -0,0 +1,41 @@
+package minio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrepareCertsDirCleansUp(t *testing.T) {
+	t.Setenv("lunchpail_queue_certsDir", "")
+	t.Setenv("lunchpail_queue_tlsCert", "cert")
+	t.Setenv("lunchpail_queue_tlsKey", "key")
+
+	dir, cleanup, err := prepareCertsDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "private.key")); err != nil || string(b) != "key" {
+		t.Fatalf("expected private.key to hold the key, got %q err=%v", b, err)
+	}
+
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to have been removed, got %v", dir, err)
+	}
+}
+
+func TestPrepareCertsDirLeavesGivenDir(t *testing.T) {
+	given := t.TempDir()
+	t.Setenv("lunchpail_queue_certsDir", given)
+
+	dir, cleanup, err := prepareCertsDir()
+	if err != nil || dir != given {
+		t.Fatalf("expected %s, got %s err=%v", given, dir, err)
+	}
+
+	cleanup()
+	if _, err := os.Stat(given); err != nil {
+		t.Fatalf("expected %s to remain, got %v", given, err)
+	}
+}
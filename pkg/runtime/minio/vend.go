@@ -0,0 +1,140 @@
+package minio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"lunchpail.io/pkg/ir/queue"
+	s3 "lunchpail.io/pkg/runtime/queue"
+)
+
+// How long a worker waits for the queue server to answer its request
+// for credentials
+var vendTimeout = 2 * time.Minute
+
+// Credentials vended to a worker, for use by its handler
+type VendedCredentials struct {
+	queue.Credentials
+
+	// When these credentials expire, after which the worker must
+	// request new ones
+	Expiration time.Time `json:"expiration"`
+
+	// Why we could not mint credentials, if we could not
+	Error string `json:"error,omitempty"`
+}
+
+// Should these credentials be replaced, as they will soon expire?
+func (creds VendedCredentials) IsExpiring() bool {
+	return !creds.Expiration.IsZero() && time.Until(creds.Expiration) < time.Minute
+}
+
+// Mint scoped credentials for handlers, as their workers ask for
+// them. Only the queue server holds the root credentials needed to
+// mint; the worker only relays them to its handler.
+func vendCredentials(ctx context.Context, c s3.S3Client, endpoint, ca string, root queue.Credentials, run queue.RunContext) {
+	requests := run.ForStep(queue.AnyStep).PatternFor(queue.CredentialsRequest)
+	prefix := run.AsFile(queue.Meta) + "/credentials/request/"
+
+	objs, errs := c.Listen(run.Bucket, prefix, "", false)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-errs:
+			// Listen falls back to polling
+		case key, ok := <-objs:
+			if !ok {
+				return
+			}
+
+			match := requests.FindStringSubmatch(key)
+			if len(match) != 4 {
+				fmt.Fprintf(os.Stderr, "Warning: ignoring invalid credentials request %s\n", key)
+				continue
+			}
+			step, err := strconv.Atoi(match[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: ignoring invalid credentials request %s\n", key)
+				continue
+			}
+
+			if err := vend(c, endpoint, ca, root, run.ForStep(step).ForPool(match[2]).ForWorker(match[3])); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: unable to answer credentials request %s: %v\n", key, err)
+			}
+		}
+	}
+}
+
+// Answer the credentials request of the worker of the given context
+func vend(c s3.S3Client, endpoint, ca string, root queue.Credentials, run queue.RunContext) error {
+	request := run.AsFile(queue.CredentialsRequest)
+	role, err := c.Get(run.Bucket, request)
+	if err != nil {
+		return err
+	}
+
+	var answer VendedCredentials
+	if creds, err := Mint(endpoint, ca, root, Role(role), run); err != nil {
+		answer.Error = err.Error()
+	} else if v, err := creds.Get(); err != nil {
+		answer.Error = err.Error()
+	} else {
+		answer.Credentials = queue.Credentials{AccessKeyID: v.AccessKeyID, SecretAccessKey: v.SecretAccessKey, SessionToken: v.SessionToken}
+		answer.Expiration = v.Expiration
+	}
+
+	b, err := json.Marshal(answer)
+	if err != nil {
+		return err
+	}
+	if err := c.PutBytes(run.Bucket, run.AsFile(queue.ScopedCredentials), b); err != nil {
+		return err
+	}
+
+	return c.Rm(run.Bucket, request)
+}
+
+// Ask the queue server for credentials scoped to the given role, for
+// the worker of the given context
+func RequestCredentials(ctx context.Context, c s3.S3Client, run queue.RunContext, role Role) (VendedCredentials, error) {
+	answer := run.AsFile(queue.ScopedCredentials)
+
+	// Forget any prior answer, so that we wait for a fresh one
+	if err := c.Rm(run.Bucket, answer); err != nil {
+		return VendedCredentials{}, err
+	}
+	if err := c.Mark(run.Bucket, run.AsFile(queue.CredentialsRequest), string(role)); err != nil {
+		return VendedCredentials{}, err
+	}
+
+	deadline := time.Now().Add(vendTimeout)
+	for !c.ExistsNow(run.Bucket, answer) {
+		if time.Now().After(deadline) {
+			return VendedCredentials{}, fmt.Errorf("Timed out waiting for the queue server to mint credentials")
+		}
+		select {
+		case <-ctx.Done():
+			return VendedCredentials{}, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+
+	b, err := c.Get(run.Bucket, answer)
+	if err != nil {
+		return VendedCredentials{}, err
+	}
+
+	var creds VendedCredentials
+	if err := json.Unmarshal([]byte(b), &creds); err != nil {
+		return VendedCredentials{}, err
+	} else if creds.Error != "" {
+		return VendedCredentials{}, fmt.Errorf("The queue server was unable to mint credentials: %s", creds.Error)
+	}
+
+	return creds, nil
+}
@@ -0,0 +1,57 @@
+package minio
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/runtime/queue/queuetest"
+)
+
+func TestVendReportsMintFailure(t *testing.T) {
+	c := queuetest.NewClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go vendCredentials(ctx, c, "localhost:1", "", queue.Credentials{AccessKeyID: "root", SecretAccessKey: "rootsecret"}, testRun)
+
+	// No such role, so the worker must hear of the failure,
+	// rather than e.g. falling back to the root credentials
+	_, err := RequestCredentials(ctx, c, testRun, Role("root"))
+	if err == nil || !strings.Contains(err.Error(), "Unsupported role") {
+		t.Fatalf("expected an unsupported role error, got %v", err)
+	}
+
+	if c.ExistsNow(testRun.Bucket, testRun.AsFile(queue.CredentialsRequest)) {
+		t.Fatal("expected the request to have been consumed")
+	}
+}
+
+func TestRequestCredentialsWaitsForFreshAnswer(t *testing.T) {
+	c := queuetest.NewClient(t)
+
+	// A stale answer from a prior request
+	stale, _ := json.Marshal(VendedCredentials{Credentials: queue.Credentials{AccessKeyID: "stale"}})
+	if err := c.PutBytes(testRun.Bucket, testRun.AsFile(queue.ScopedCredentials), stale); err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for !c.ExistsNow(testRun.Bucket, testRun.AsFile(queue.CredentialsRequest)) {
+			time.Sleep(100 * time.Millisecond)
+		}
+		fresh, _ := json.Marshal(VendedCredentials{Credentials: queue.Credentials{AccessKeyID: "fresh"}, Expiration: time.Now().Add(time.Hour)})
+		c.PutBytes(testRun.Bucket, testRun.AsFile(queue.ScopedCredentials), fresh)
+	}()
+
+	creds, err := RequestCredentials(context.Background(), c, testRun, WorkerRole)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "fresh" || creds.IsExpiring() {
+		t.Fatalf("expected fresh credentials, got %+v", creds)
+	}
+}
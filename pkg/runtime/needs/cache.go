@@ -0,0 +1,362 @@
+package needs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A virtual environment in the cache kept by requirementsInstall, or
+// a conda environment in the cache kept by condaEnvInstall
+type CachedVenv struct {
+	Path string
+
+	// The SHA256 of its requirements, by which it is keyed
+	Key string
+
+	// A conda environment, rather than a venv
+	Conda bool
+
+	// e.g. 3.12.4, from its pyvenv.cfg or conda-meta
+	Python string
+
+	// For a conda environment, the dependencies listed in its
+	// environment.yml, one per line
+	Requirements string
+	Bytes        int64
+	LastUsed     time.Time
+
+	// Otherwise, an install is in progress, or failed
+	Complete bool
+}
+
+// Touched whenever a cached venv is installed or re-used, so that we
+// can tell which have gone stale
+const lastUsedFile = "last-used"
+
+func markUsed(venvPath string) error {
+	return os.WriteFile(filepath.Join(venvPath, lastUsedFile), []byte(time.Now().UTC().Format(time.RFC3339)), 0644)
+}
+
+// Mark the given venv, whose lock we hold, as used, and if given a
+// pid, keep it from being pruned until that process exits
+func inUse(venvPath string, lockfile *os.File, pid int) error {
+	if err := markUsed(venvPath); err != nil {
+		return err
+	} else if pid <= 0 {
+		return nil
+	}
+
+	// Prune takes an exclusive lock, so a shared one keeps it
+	// away. We hand that lock to a process that holds it while
+	// the given one lives, as we are about to exit.
+	if err := syscall.Flock(int(lockfile.Fd()), syscall.LOCK_SH); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	// Note: no stdout, as our caller may be reading ours until EOF
+	cmd := exec.Command(exe, "needs", "hold", strconv.Itoa(pid))
+	cmd.ExtraFiles = []*os.File{lockfile}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// Wait for the given process to exit. Meanwhile, whatever lock our
+// caller passed us, via inUse, stays held.
+func Hold(pid int) {
+	for {
+		if err := syscall.Kill(pid, 0); err != nil && err != syscall.EPERM {
+			return
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// The virtual and conda environments in the cache, most recently
+// used first
+func CachedVenvs() ([]CachedVenv, error) {
+	dir, err := venvsdir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	venvs := []CachedVenv{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			venvs = append(venvs, describe(filepath.Join(dir, entry.Name())))
+		}
+	}
+
+	dir, err = condadir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err = os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		// Skipping .root, micromamba's package cache
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			venvs = append(venvs, describeConda(filepath.Join(dir, entry.Name())))
+		}
+	}
+
+	slices.SortFunc(venvs, func(a, b CachedVenv) int { return b.LastUsed.Compare(a.LastUsed) })
+	return venvs, nil
+}
+
+func describe(path string) CachedVenv {
+	venv := CachedVenv{Path: path, Key: filepath.Base(path)}
+
+	if b, err := os.ReadFile(filepath.Join(path, "requirements.txt")); err == nil {
+		venv.Requirements = string(b)
+	}
+	if _, err := os.Stat(filepath.Join(path, "bin")); err == nil {
+		venv.Complete = true
+	}
+
+	// Venvs from before we kept track only have their lock
+	for _, file := range []string{lastUsedFile, "lock.txt"} {
+		if info, err := os.Stat(filepath.Join(path, file)); err == nil {
+			venv.LastUsed = info.ModTime()
+			break
+		}
+	}
+
+	if f, err := os.Open(filepath.Join(path, "pyvenv.cfg")); err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			key, value, ok := strings.Cut(scanner.Text(), "=")
+			key = strings.TrimSpace(key)
+			if ok && (key == "version" || key == "version_info") {
+				venv.Python = strings.TrimSpace(value)
+			}
+		}
+	}
+
+	venv.Bytes = du(path)
+	return venv
+}
+
+// condaEnvInstall keeps the environment.yml and the lock alongside
+// the environment, and marks it as used only once it is complete
+func describeConda(path string) CachedVenv {
+	venv := CachedVenv{Path: path, Key: filepath.Base(path), Conda: true}
+
+	if b, err := os.ReadFile(path + ".yml"); err == nil {
+		venv.Requirements = condaDependencies(b)
+	}
+	if info, err := os.Stat(filepath.Join(path, lastUsedFile)); err == nil {
+		venv.Complete = true
+		venv.LastUsed = info.ModTime()
+	} else if info, err := os.Stat(path + ".lock"); err == nil {
+		venv.LastUsed = info.ModTime()
+	}
+
+	// e.g. conda-meta/python-3.12.4-h5148396_1.json
+	if matches, err := filepath.Glob(filepath.Join(path, "conda-meta", "python-[0-9]*.json")); err == nil && len(matches) > 0 {
+		venv.Python = strings.Split(filepath.Base(matches[0]), "-")[1]
+	}
+
+	venv.Bytes = du(path)
+	return venv
+}
+
+// The dependencies of the given environment.yml, including those
+// installed via pip
+func condaDependencies(environment []byte) string {
+	var env struct {
+		Dependencies []any `yaml:"dependencies"`
+	}
+	if err := yaml.Unmarshal(environment, &env); err != nil {
+		return ""
+	}
+
+	deps := []string{}
+	for _, dep := range env.Dependencies {
+		switch d := dep.(type) {
+		case string:
+			deps = append(deps, d)
+		case map[string]any:
+			if pip, ok := d["pip"].([]any); ok {
+				for _, p := range pip {
+					if s, ok := p.(string); ok {
+						deps = append(deps, s)
+					}
+				}
+			}
+		}
+	}
+	return strings.Join(deps, "\n")
+}
+
+// The bytes taken by the files under the given directory
+func du(path string) int64 {
+	var bytes int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				bytes += info.Size()
+			}
+		}
+		return nil
+	})
+	return bytes
+}
+
+type PruneOptions struct {
+	// Remove venvs not used in this long
+	OlderThan time.Duration
+
+	// Remove all venvs, regardless of when they were last used
+	All bool
+}
+
+// Remove stale venvs from the cache, along with any whose install
+// failed. Venvs being installed or in use are left alone.
+func PruneVenvs(opts PruneOptions) ([]CachedVenv, error) {
+	venvs, err := CachedVenvs()
+	if err != nil {
+		return nil, err
+	}
+
+	stale := []CachedVenv{}
+	for _, venv := range venvs {
+		if opts.All || !venv.Complete || time.Since(venv.LastUsed) >= opts.OlderThan {
+			stale = append(stale, venv)
+		}
+	}
+
+	pruned, err := RemoveVenvs(stale)
+	if err != nil {
+		return pruned, err
+	}
+
+	// The output of failed installs, and the environment.yml of
+	// failed conda installs, whose environments are already gone
+	venvDir, err := venvsdir()
+	if err != nil {
+		return pruned, err
+	}
+	condaDir, err := condadir()
+	if err != nil {
+		return pruned, err
+	}
+	for _, pattern := range []string{filepath.Join(venvDir, "*.log"), filepath.Join(condaDir, "*.log"), filepath.Join(condaDir, "*.yml")} {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return pruned, err
+		}
+		for _, file := range files {
+			if _, err := os.Stat(strings.TrimSuffix(file, filepath.Ext(file))); os.IsNotExist(err) {
+				if err := os.Remove(file); err != nil {
+					return pruned, err
+				}
+			}
+		}
+	}
+
+	return pruned, nil
+}
+
+// Remove the given venv, unless it is being installed or in use
+func remove(venv CachedVenv) (bool, error) {
+	// We leave the lock of a conda environment, which lives
+	// alongside it, as an install may be about to take it
+	lock := filepath.Join(venv.Path, "lock.txt")
+	if venv.Conda {
+		lock = venv.Path + ".lock"
+	}
+
+	lockfile, err := os.OpenFile(lock, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer lockfile.Close()
+
+	if err := syscall.Flock(int(lockfile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		// Someone is installing into it, or using it
+		return false, nil
+	}
+
+	if err := os.RemoveAll(venv.Path); err != nil {
+		return false, err
+	}
+
+	// Along with the output of its installer, if it failed, and
+	// the environment.yml of a conda environment
+	for _, file := range []string{venv.Path + ".log", venv.Path + ".yml"} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// Check that the given venv is usable, and that the packages
+// installed in it are consistent with one another
+func (venv CachedVenv) Verify(ctx context.Context) error {
+	if !venv.Complete {
+		return fmt.Errorf("Incomplete; its install failed or is in progress")
+	}
+
+	python := filepath.Join(venv.Path, "bin", "python")
+	if _, err := os.Stat(python); err != nil {
+		if venv.Conda {
+			// A conda environment need not have python
+			return nil
+		}
+		return fmt.Errorf("Missing its python executable")
+	}
+
+	cmd := exec.CommandContext(ctx, python, "-m", "pip", "check")
+	if uv, err := exec.LookPath("uv"); err == nil {
+		// venvs created by uv do not have pip
+		cmd = exec.CommandContext(ctx, uv, "pip", "check", "--python", python)
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// Remove the given venvs, regardless of when they were last used
+func RemoveVenvs(venvs []CachedVenv) ([]CachedVenv, error) {
+	removed := []CachedVenv{}
+	for _, venv := range venvs {
+		if ok, err := remove(venv); err != nil {
+			return removed, err
+		} else if ok {
+			removed = append(removed, venv)
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,175 @@
+package needs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// A complete cached venv, keyed by the given name
+func mkVenv(t *testing.T, dir, key string) string {
+	t.Helper()
+	venv := filepath.Join(dir, key)
+	if err := os.MkdirAll(filepath.Join(venv, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := markUsed(venv); err != nil {
+		t.Fatal(err)
+	}
+	return venv
+}
+
+func TestPruneSkipsVenvsInUse(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LUNCHPAIL_VENV_CACHEDIR", dir)
+	t.Setenv("LUNCHPAIL_CONDA_CACHEDIR", t.TempDir())
+	inuse := mkVenv(t, dir, "inuse")
+	idle := mkVenv(t, dir, "idle")
+
+	// As inUse leaves it for the duration of a run
+	lockfile, err := os.OpenFile(filepath.Join(inuse, "lock.txt"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lockfile.Close()
+	if err := syscall.Flock(int(lockfile.Fd()), syscall.LOCK_SH); err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, err := PruneVenvs(PruneOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 1 || pruned[0].Path != idle {
+		t.Errorf("expected only the idle venv to be pruned, got %v", pruned)
+	}
+	if _, err := os.Stat(inuse); err != nil {
+		t.Errorf("expected the venv in use to remain: %v", err)
+	}
+
+	// Once the run is done, it may be pruned
+	if err := lockfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if pruned, err := PruneVenvs(PruneOptions{All: true}); err != nil || len(pruned) != 1 {
+		t.Errorf("expected the venv to be pruned once no longer in use, got %v %v", pruned, err)
+	}
+}
+
+// A complete conda environment, as condaEnvInstall leaves it
+func mkConda(t *testing.T, dir, key, environment string) string {
+	t.Helper()
+	env := filepath.Join(dir, key)
+	if err := os.MkdirAll(filepath.Join(env, "conda-meta"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(env, "conda-meta", "python-3.12.4-h5148396_1.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(env+".yml", []byte(environment), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := markUsed(env); err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+func TestCachedCondaEnvs(t *testing.T) {
+	venvDir := t.TempDir()
+	condaDir := t.TempDir()
+	t.Setenv("LUNCHPAIL_VENV_CACHEDIR", venvDir)
+	t.Setenv("LUNCHPAIL_CONDA_CACHEDIR", condaDir)
+	mkVenv(t, venvDir, "venv")
+	env := mkConda(t, condaDir, "env", `name: test
+dependencies:
+  - python=3.12
+  - gdal
+  - pip:
+    - six==1.16.0
+`)
+
+	// micromamba's package cache is not an environment
+	if err := os.MkdirAll(filepath.Join(condaDir, ".root"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	venvs, err := CachedVenvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(venvs) != 2 {
+		t.Fatalf("expected a venv and a conda environment, got %v", venvs)
+	}
+
+	var conda CachedVenv
+	for _, venv := range venvs {
+		if venv.Conda {
+			conda = venv
+		}
+	}
+	if conda.Path != env || !conda.Complete || conda.Python != "3.12.4" {
+		t.Errorf("unexpected conda environment %+v", conda)
+	}
+	if conda.Requirements != "python=3.12\ngdal\nsix==1.16.0" {
+		t.Errorf("unexpected conda dependencies %q", conda.Requirements)
+	}
+
+	pruned, err := PruneVenvs(PruneOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 2 {
+		t.Errorf("expected both environments to be pruned, got %v", pruned)
+	}
+	for _, file := range []string{env, env + ".yml"} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", file)
+		}
+	}
+}
+
+func TestPruneSkipsCondaEnvsInUse(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LUNCHPAIL_VENV_CACHEDIR", t.TempDir())
+	t.Setenv("LUNCHPAIL_CONDA_CACHEDIR", dir)
+	env := mkConda(t, dir, "env", "dependencies: [gdal]\n")
+
+	lockfile, err := os.OpenFile(env+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lockfile.Close()
+	if err := syscall.Flock(int(lockfile.Fd()), syscall.LOCK_SH); err != nil {
+		t.Fatal(err)
+	}
+
+	if pruned, err := PruneVenvs(PruneOptions{All: true}); err != nil || len(pruned) != 0 {
+		t.Errorf("expected nothing to be pruned, got %v %v", pruned, err)
+	}
+	if _, err := os.Stat(env + ".yml"); err != nil {
+		t.Errorf("expected the environment.yml of an environment in use to remain: %v", err)
+	}
+}
+
+func TestHoldReturnsOnceProcessExits(t *testing.T) {
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		Hold(cmd.Process.Pid)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Hold to return for a process that has exited")
+	}
+}
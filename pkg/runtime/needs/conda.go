@@ -0,0 +1,155 @@
+package needs
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"lunchpail.io/pkg/util"
+)
+
+// The version of micromamba we install, unless asked for another
+const MicromambaVersion = "2.0.5-0"
+
+// Create (if needed) a conda environment from the given
+// base64-encoded environment.yml. The version is that of micromamba,
+// should we need to install it.
+// @return the bin directory of the environment, to add to PATH
+func InstallConda(ctx context.Context, version, requirements string, opts Options) (string, error) {
+	if requirements == "" {
+		return "", fmt.Errorf("An environment.yml is required to create a conda environment")
+	}
+
+	environment, err := base64.StdEncoding.DecodeString(requirements)
+	if err != nil {
+		return "", err
+	}
+
+	installer, err := condaInstaller(ctx, version, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return condaEnvInstall(ctx, installer, environment, opts)
+}
+
+// Prefer micromamba, as it needs no base environment and is the
+// fastest, then mamba, then conda. If we have none of these, install
+// micromamba.
+func condaInstaller(ctx context.Context, version string, opts Options) (string, error) {
+	for _, exe := range []string{"micromamba", "mamba", "conda"} {
+		if path, err := exec.LookPath(exe); err == nil {
+			if opts.Verbose {
+				fmt.Fprintf(os.Stderr, "needs found %s\n", path)
+			}
+			return path, nil
+		} else if !errors.Is(err, exec.ErrNotFound) {
+			return "", err
+		}
+	}
+
+	if opts.Verbose {
+		fmt.Fprintf(os.Stderr, "needs installing micromamba %s\n", version)
+	}
+	dir, err := installMicromamba(ctx, version, opts.Verbose)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "micromamba"), nil
+}
+
+// Create the environment in a cache directory keyed by the contents
+// of environment.yml
+func condaEnvInstall(ctx context.Context, installer string, environment []byte, opts Options) (string, error) {
+	verbose := opts.Verbose
+	dir, err := condadir()
+	if err != nil {
+		return "", err
+	}
+	sha, err := getSHA256Sum(environment)
+	if err != nil {
+		return "", err
+	}
+	envPath := filepath.Join(dir, sha)
+
+	// conda and mamba refuse to create an environment in a
+	// non-empty directory, so we keep the lock and the
+	// environment.yml alongside, rather than inside, it
+	lockfile, err := os.OpenFile(envPath+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer lockfile.Close()
+	if err := syscall.Flock(int(lockfile.Fd()), syscall.LOCK_EX); err != nil {
+		return "", err
+	}
+
+	// We mark the environment as used only once it is complete
+	bin := filepath.Join(envPath, "bin")
+	if _, err := os.Stat(filepath.Join(envPath, lastUsedFile)); err == nil {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Skipping conda install since environment exists\n")
+		}
+		return bin, inUse(envPath, lockfile, opts.HoldFor)
+	}
+
+	// Start afresh, should a prior install have been interrupted
+	if err := os.RemoveAll(envPath); err != nil {
+		return "", err
+	}
+
+	environmentFile := envPath + ".yml"
+	if err := os.WriteFile(environmentFile, environment, 0644); err != nil {
+		return "", err
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Creating conda environment\n%s\n", string(environment))
+	}
+
+	name := filepath.Base(installer)
+	args := []string{"env", "create", "-p", envPath, "-f", environmentFile}
+	if name == "micromamba" {
+		args = []string{"create", "-y", "-p", envPath, "-f", environmentFile}
+	}
+
+	cmd := exec.CommandContext(ctx, installer, args...)
+	if name == "micromamba" && os.Getenv("MAMBA_ROOT_PREFIX") == "" {
+		// Otherwise, micromamba keeps its package cache in ~/micromamba
+		cmd.Env = append(os.Environ(), "MAMBA_ROOT_PREFIX="+filepath.Join(dir, ".root"))
+	}
+
+	inst := installation{what: "a conda environment", installer: name, logfile: envPath + ".log"}
+
+	t1s := time.Now()
+	if err := inst.run(cmd, verbose); err != nil {
+		// Clean up the environment, since we failed at populating it
+		if err := os.RemoveAll(envPath); err != nil {
+			fmt.Fprintln(os.Stderr, "Unable to clean up conda environment after failed install", err)
+		}
+		return "", err
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "METRICS: Took %s for %s installs\n", util.RelTime(t1s, time.Now()), name)
+	}
+
+	return bin, inUse(envPath, lockfile, opts.HoldFor)
+}
+
+func condadir() (string, error) {
+	dir := os.Getenv("LUNCHPAIL_CONDA_CACHEDIR")
+	if dir == "" {
+		cachedir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(cachedir, "lunchpail", "conda")
+	}
+
+	return dir, os.MkdirAll(dir, os.ModePerm)
+}
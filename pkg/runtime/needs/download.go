@@ -0,0 +1,86 @@
+package needs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Download the given url
+func download(ctx context.Context, url string, verbose bool) ([]byte, error) {
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Downloading %s\n", url)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unable to download %s: %s", url, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// Error unless the sha256 of b is the one listed for name in sums,
+// which has lines of the form `<sha256>  <name>`, as printed by
+// sha256sum. Other lines, e.g. of a PGP signature, are ignored. Sums
+// that are only a checksum, as published alongside a single file,
+// are taken to be that of name.
+func verifySha256(b []byte, name string, sums []byte) error {
+	var want string
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			want = strings.ToLower(fields[0])
+			break
+		}
+	}
+	if fields := strings.Fields(string(sums)); want == "" && len(fields) == 1 && len(fields[0]) == sha256.Size*2 {
+		want = strings.ToLower(fields[0])
+	}
+	if want == "" {
+		return fmt.Errorf("No checksum listed for %s", name)
+	}
+
+	got := sha256.Sum256(b)
+	if hex.EncodeToString(got[:]) != want {
+		return fmt.Errorf("Checksum mismatch for %s: expected sha256 %s, got %s", name, want, hex.EncodeToString(got[:]))
+	}
+
+	return nil
+}
+
+// Download the given url, verifying it against the checksums
+// published at sumsUrl
+func downloadVerified(ctx context.Context, url, name, sumsUrl string, verbose bool) ([]byte, error) {
+	sums, err := download(ctx, sumsUrl, verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := download(ctx, url, verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySha256(b, name, sums); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
@@ -0,0 +1,50 @@
+package needs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestVerifySha256(t *testing.T) {
+	b := []byte("rclone")
+	sum := sha256.Sum256(b)
+	sums := []byte(`-----BEGIN PGP SIGNED MESSAGE-----
+Hash: SHA1
+
+0000000000000000000000000000000000000000000000000000000000000000  rclone-v1.68.2-linux-arm64.zip
+` + hex.EncodeToString(sum[:]) + `  rclone-v1.68.2-linux-amd64.zip
+-----BEGIN PGP SIGNATURE-----
+`)
+
+	if err := verifySha256(b, "rclone-v1.68.2-linux-amd64.zip", sums); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifySha256(b, "rclone-v1.68.2-linux-arm64.zip", sums); err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	if err := verifySha256(b, "rclone-v1.68.2-osx-amd64.zip", sums); err == nil {
+		t.Fatal("expected an error for a file with no listed checksum")
+	}
+}
+
+func TestVerifySha256BinaryMode(t *testing.T) {
+	b := []byte("micromamba")
+	sum := sha256.Sum256(b)
+	if err := verifySha256(b, "micromamba-linux-64", []byte(strings.ToUpper(hex.EncodeToString(sum[:]))+" *micromamba-linux-64\n")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// As micromamba-releases publishes alongside each asset
+func TestVerifySha256Bare(t *testing.T) {
+	b := []byte("micromamba")
+	sum := sha256.Sum256(b)
+	if err := verifySha256(b, "micromamba-linux-64", []byte(hex.EncodeToString(sum[:])+"\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifySha256([]byte("other"), "micromamba-linux-64", []byte(hex.EncodeToString(sum[:])+"\n")); err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package needs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// A failure to install Python requirements or a conda environment, as
+// best we can diagnose it from the output of the installer
+type InstallError struct {
+	// What we were installing, e.g. "Python requirements"
+	What string
+
+	// e.g. pip, uv, or micromamba
+	Installer string
+
+	// What went wrong, e.g. "no matching distribution for foo==1.2"
+	Reason string
+
+	// What might be done about it, if we have a suggestion
+	Hint string
+
+	// Where the full output of the installer was kept
+	LogFile string
+
+	Err error
+}
+
+func (e InstallError) Error() string {
+	msg := fmt.Sprintf("Unable to install %s via %s: %s", e.What, e.Installer, e.Reason)
+	if e.Hint != "" {
+		msg += ". " + e.Hint
+	}
+	if e.LogFile != "" {
+		msg += fmt.Sprintf(" (see %s for the full output)", e.LogFile)
+	}
+	return msg
+}
+
+func (e InstallError) Unwrap() error {
+	return e.Err
+}
+
+// A known way for pip or uv to fail
+type diagnosis struct {
+	pattern *regexp.Regexp
+
+	// May refer to the first submatch of pattern as %s
+	reason string
+
+	hint        string
+	offlineHint string
+}
+
+// In order of precedence, as e.g. a failure to reach the index will
+// also result in there being no matching distribution
+var pythonDiagnoses = []diagnosis{
+	{
+		pattern: regexp.MustCompile(`Failed to establish a new connection|Name or service not known|Temporary failure in name resolution|Network is unreachable|Connection refused|dns error|error sending request`),
+		reason:  "unable to reach the package index",
+		hint:    "If workers cannot reach PyPI, re-build with --offline-python",
+	},
+	{
+		pattern: regexp.MustCompile("THESE PACKAGES DO NOT MATCH THE HASHES|Hash mismatch for `?([^`\\s]+)"),
+		reason:  "downloaded packages do not match the hashes recorded in the lockfile",
+		hint:    "The lockfile may be out of date; re-lock and re-build",
+	},
+	{
+		pattern:     regexp.MustCompile(`No matching distribution found for (\S+)`),
+		reason:      "no matching distribution for %s",
+		hint:        "Check its name and version, and that it is published for this platform and Python version",
+		offlineHint: "The wheels bundled by --offline-python do not include it; re-build with --python-platform for this platform",
+	},
+	{
+		pattern: regexp.MustCompile(`Because (\S+) was not found in the package registry|(\S+) was not found in the package registry`),
+		reason:  "no matching distribution for %s",
+		hint:    "Check its name and version, and that it is published for this platform and Python version",
+	},
+	{
+		pattern: regexp.MustCompile(`ResolutionImpossible|conflicting dependencies|No solution found when resolving dependencies`),
+		reason:  "the requirements conflict with one another",
+		hint:    "Loosen or re-pin the conflicting requirements, e.g. via a lockfile",
+	},
+	{
+		pattern: regexp.MustCompile("Failed (?:building wheel for|to build:?) `?([^`\\s]+)"),
+		reason:  "unable to build %s from source",
+		hint:    "It may need a compiler or system libraries; consider pinning a version that has wheels for this platform",
+	},
+	{
+		pattern: regexp.MustCompile(`No module named venv|ensurepip is not available|No module named pip`),
+		reason:  "python lacks venv or pip support",
+		hint:    "Install the python3-venv package, or make uv available",
+	},
+	{
+		pattern: regexp.MustCompile(`python[0-9.]*: (?:command )?not found|No interpreter found`),
+		reason:  "python is not installed",
+	},
+}
+
+// Make sense of the output of a failed install
+func (inst installation) diagnose(output string, err error) InstallError {
+	e := InstallError{What: inst.what, Installer: inst.installer, LogFile: inst.logfile, Err: err}
+	offline := inst.offline
+	for _, d := range inst.diagnoses {
+		m := d.pattern.FindStringSubmatch(output)
+		if m == nil {
+			continue
+		}
+
+		culprit := ""
+		for _, s := range m[1:] {
+			if s != "" {
+				culprit = s
+				break
+			}
+		}
+		e.Reason = d.reason
+		if strings.Contains(d.reason, "%s") {
+			e.Reason = fmt.Sprintf(d.reason, culprit)
+		}
+
+		e.Hint = d.hint
+		if offline && d.offlineHint != "" {
+			e.Hint = d.offlineHint
+		} else if offline && strings.Contains(d.hint, "--offline-python") {
+			e.Hint = ""
+		}
+		return e
+	}
+
+	// Otherwise, the last thing the installer had to say
+	e.Reason = err.Error()
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" && !strings.Contains(line, "METRICS") {
+			e.Reason = line
+			break
+		}
+	}
+	return e
+}
@@ -0,0 +1,25 @@
+package needs
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Find and install (if needed) fuse, which rclone needs to mount
+// @return the command line that unmounts, given a mount point
+func InstallFuse(ctx context.Context, opts Options) ([]string, error) {
+	if err := installFuse(ctx, opts.Verbose); err != nil {
+		return nil, fmt.Errorf("Unable to install fuse: %v", err)
+	}
+
+	cmdline, err := unmountCommand()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Verbose {
+		fmt.Fprintln(os.Stderr, "needs fuse found", cmdline[0])
+	}
+	return cmdline, nil
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"os/exec"
 	"os/user"
+	"path/filepath"
+	"strings"
 )
 
 func homedir() (string, error) {
@@ -30,6 +32,14 @@ func installMinio(ctx context.Context, version string, verbose bool) (string, er
 	return "", brewInstall(ctx, "minio/stable/minio", version, verbose) //Todo: versions other than latest
 }
 
+func installRclone(ctx context.Context, version string, verbose bool) (string, error) {
+	if err := setenv(); err != nil { //$HOME must be set for brew
+		return "", err
+	}
+
+	return "", brewInstall(ctx, "rclone", version, verbose) //Todo: versions other than latest
+}
+
 func installPython(ctx context.Context, version string, verbose bool) (string, error) {
 	if err := setenv(); err != nil { //$HOME must be set for brew
 		return "", err
@@ -42,6 +52,43 @@ func installPython(ctx context.Context, version string, verbose bool) (string, e
 	return "", brewInstall(ctx, python, version, verbose) //Todo: versions other than latest
 }
 
+// Some formulae (e.g. node@22 or openjdk) are not linked onto PATH
+// @return the bin directory of the given formula
+func brewInstallBin(ctx context.Context, pkg string, version string, verbose bool) (string, error) {
+	if err := setenv(); err != nil { //$HOME must be set for brew
+		return "", err
+	}
+	if err := brewInstall(ctx, pkg, version, verbose); err != nil {
+		return "", err
+	}
+
+	prefix, err := exec.CommandContext(ctx, "brew", "--prefix", pkg).Output()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(strings.TrimSpace(string(prefix)), "bin"), nil
+}
+
+func installNode(ctx context.Context, version string, verbose bool) (string, error) {
+	node := "node"
+	if version != "" && version != "latest" {
+		node = "node@" + version
+	}
+	return brewInstallBin(ctx, node, version, verbose)
+}
+
+func installR(ctx context.Context, version string, verbose bool) (string, error) {
+	return brewInstallBin(ctx, "r", version, verbose)
+}
+
+func installJava(ctx context.Context, version string, verbose bool) (string, error) {
+	jdk := "openjdk"
+	if version != "" && version != "latest" {
+		jdk = "openjdk@" + version
+	}
+	return brewInstallBin(ctx, jdk, version, verbose)
+}
+
 func brewInstall(ctx context.Context, pkg string, version string, verbose bool) error {
 	var cmd *exec.Cmd
 	if verbose {
@@ -62,3 +109,18 @@ func setenv() error {
 	}
 	return os.Setenv("HOME", dir)
 }
+
+func installMicromamba(ctx context.Context, version string, verbose bool) (string, error) {
+	return brewInstallBin(ctx, "micromamba", version, verbose)
+}
+
+// rclone mounts via macFUSE, which must be installed by hand, as it
+// needs a kernel extension to be approved
+func installFuse(ctx context.Context, verbose bool) error {
+	return nil
+}
+
+// The command line that unmounts a fuse filesystem, given its mount point
+func unmountCommand() ([]string, error) {
+	return []string{"umount"}, nil
+}
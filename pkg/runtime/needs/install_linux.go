@@ -1,11 +1,16 @@
 package needs
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
 )
 
 func bindir() (string, error) {
@@ -45,6 +50,90 @@ func installMinio(ctx context.Context, version string, verbose bool) (string, er
 	return dir, os.Chmod(filepath.Join(dir, "minio"), 0755)
 }
 
+func installRclone(ctx context.Context, version string, verbose bool) (string, error) {
+	dir, err := bindir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	// The release is a zip that holds rclone-<version>-linux-<arch>/rclone
+	name := fmt.Sprintf("rclone-%s-linux-%s.zip", version, runtime.GOARCH)
+	url := fmt.Sprintf("https://downloads.rclone.org/%s/%s", version, name)
+	b, err := downloadVerified(ctx, url, name, fmt.Sprintf("https://downloads.rclone.org/%s/SHA256SUMS", version), verbose)
+	if err != nil {
+		return "", err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return "", err
+	}
+
+	for _, f := range archive.File {
+		if filepath.Base(f.Name) != "rclone" {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+
+		w, err := os.OpenFile(filepath.Join(dir, "rclone"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+		if err != nil {
+			return "", err
+		}
+		defer w.Close()
+
+		if _, err := io.Copy(w, r); err != nil {
+			return "", err
+		}
+
+		return dir, setenv(dir)
+	}
+
+	return "", fmt.Errorf("Unable to find rclone in %s", url)
+}
+
+func installMicromamba(ctx context.Context, version string, verbose bool) (string, error) {
+	dir, err := bindir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	platform := "linux-64"
+	if runtime.GOARCH == "arm64" {
+		platform = "linux-aarch64"
+	}
+	if version == "" || version == "latest" {
+		version = MicromambaVersion
+	}
+
+	// The release is the micromamba executable itself, with its
+	// checksum alongside
+	name := "micromamba-" + platform
+	url := fmt.Sprintf("https://github.com/mamba-org/micromamba-releases/releases/download/%s/%s", version, name)
+	b, err := downloadVerified(ctx, url, name, url+".sha256", verbose)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "micromamba"), b, 0755); err != nil {
+		return "", err
+	}
+
+	return dir, setenv(dir)
+}
+
 func installPython(ctx context.Context, version string, verbose bool) (string, error) {
 	if version == "" || version == "latest" {
 		version = "3"
@@ -89,6 +178,85 @@ func installPython(ctx context.Context, version string, verbose bool) (string, e
 	return "", nil
 }
 
+// Install the given system packages, if we have apt
+func aptInstall(ctx context.Context, what string, verbose bool, packages ...string) (string, error) {
+	if _, err := exec.LookPath("apt"); err != nil {
+		return "", fmt.Errorf("Unable to install %s", what)
+	}
+
+	sudo := "sudo"
+	if _, err := exec.LookPath("sudo"); err != nil {
+		sudo = ""
+	}
+	cmdline := fmt.Sprintf("%s apt update && %s apt install -y %s", sudo, sudo, strings.Join(packages, " "))
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Installing %s via command line %s\n", what, cmdline)
+	}
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", cmdline)
+	cmd.Stdout = os.Stderr // Stderr so as not to collide with `lunchpail needs` stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(os.Stderr, "Successfully installed %s\n", what)
+	return "", nil
+}
+
+// Note: apt offers whichever node the distribution has
+func installNode(ctx context.Context, version string, verbose bool) (string, error) {
+	return aptInstall(ctx, "node", verbose, "nodejs", "npm")
+}
+
+func installR(ctx context.Context, version string, verbose bool) (string, error) {
+	return aptInstall(ctx, "R", verbose, "r-base-core")
+}
+
+func installJava(ctx context.Context, version string, verbose bool) (string, error) {
+	pkg := "default-jre-headless"
+	if version != "" && version != "latest" {
+		pkg = "openjdk-" + version + "-jre-headless"
+	}
+	return aptInstall(ctx, "java", verbose, pkg)
+}
+
 func setenv(dir string) error {
 	return os.Setenv("PATH", os.Getenv("PATH")+":"+dir)
 }
+
+// The fusermount executable, of fuse3 or of fuse2
+func fusermount() (string, error) {
+	for _, name := range []string{"fusermount3", "fusermount"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", exec.ErrNotFound
+}
+
+// Install fuse, if we have apk or apt
+func installFuse(ctx context.Context, verbose bool) error {
+	if _, err := fusermount(); err == nil {
+		return nil
+	}
+
+	if _, err := exec.LookPath("apk"); err == nil {
+		cmd := exec.CommandContext(ctx, "apk", "add", "--no-cache", "fuse3")
+		cmd.Stdout = os.Stderr // Stderr so as not to collide with `lunchpail needs` stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+
+	_, err := aptInstall(ctx, "fuse", verbose, "fuse3")
+	return err
+}
+
+// The command line that unmounts a fuse filesystem, given its mount point
+func unmountCommand() ([]string, error) {
+	path, err := fusermount()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to find fusermount: %v", err)
+	}
+	return []string{path, "-u"}, nil
+}
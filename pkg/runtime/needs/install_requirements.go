@@ -8,13 +8,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"syscall"
 	"time"
 
+	"lunchpail.io/pkg/build"
 	"lunchpail.io/pkg/util"
 )
 
-func requirementsInstall(ctx context.Context, version, requirements string, verbose bool) (string, error) {
+func requirementsInstall(ctx context.Context, version, requirements string, opts Options) (string, error) {
+	verbose := opts.Verbose
 	var verboseFlag string
 	var reqmtsByte []byte
 	var reqmtsFile *os.File
@@ -63,7 +66,7 @@ func requirementsInstall(ctx context.Context, version, requirements string, verb
 		if verbose {
 			fmt.Fprintf(os.Stderr, "Skipping requirements install since virtual env exists\n")
 		}
-		return path, nil
+		return path, inUse(venvPath, lockfile, opts.HoldFor)
 	}
 
 	// otherwise populate the venv
@@ -76,6 +79,25 @@ func requirementsInstall(ctx context.Context, version, requirements string, verb
 		return "", err
 	}
 
+	// If we have wheels for these requirements, install strictly
+	// from those, rather than from a package index
+	wheelhouse := opts.Wheelhouse
+	if wheelhouse == "" {
+		carried, stagePath, err := build.WheelhouseWithStage(reqmtsByte, runtime.GOOS, runtime.GOARCH)
+		if stagePath != "" {
+			defer os.RemoveAll(stagePath)
+		}
+		if err != nil {
+			return "", err
+		}
+		wheelhouse = carried
+	}
+	if wheelhouse == "" && opts.Offline {
+		return "", fmt.Errorf("Python requirements are to be installed offline, but no wheels were found for %s/%s", runtime.GOOS, runtime.GOARCH)
+	} else if wheelhouse != "" && verbose {
+		fmt.Fprintf(os.Stderr, "Installing requirements offline from %s\n", wheelhouse)
+	}
+
 	nocache := ""
 	if os.Getenv("LUNCHPAIL_NO_CACHE") != "" {
 		nocache = "--no-cache-dir"
@@ -88,28 +110,67 @@ func requirementsInstall(ctx context.Context, version, requirements string, verb
 		version = "3"
 	}
 
+	index := ""
+	upgradePip := fmt.Sprintf("if ! which pip%s > /dev/null; then python%s -m pip install pip %s; fi", version, version, verboseFlag)
+	if wheelhouse != "" {
+		// Only from the wheels, and never build from source,
+		// which may need build dependencies from an index
+		index = "--no-index --only-binary=:all: --disable-pip-version-check --find-links " + wheelhouse
+		upgradePip = ""
+	}
+
 	sudo := "sudo"
 	if _, err := exec.LookPath("sudo"); err != nil {
 		sudo = ""
 	}
 	apt := ""
-	if _, err := exec.LookPath("apt"); err == nil {
+	if _, err := exec.LookPath("apt"); err == nil && wheelhouse == "" {
 		apt = fmt.Sprintf(`%s apt install -y python%s-venv python%s-distutils`, sudo, version, version)
 	}
 
+	// Prefer uv, as it is much faster than pip
+	installer := "pip"
+	uv, uvErr := exec.LookPath("uv")
+	if uvErr == nil && os.Getenv("LUNCHPAIL_NO_UV") == "" {
+		installer = "uv"
+	}
+
 	cmdline := fmt.Sprintf(`%s
-python%s -m venv %s
+python%s -m venv %s || exit $?
 source %s/bin/activate
-if ! which pip%s > /dev/null; then python%s -m pip install pip %s; fi
+%s
 s=%s
-pip%s install %s %s -r %s %s 1>&2
+pip%s install %s %s %s -r %s %s 1>&2 || exit $?
 e=%s
-echo \"METRICS: Took $(($e-$s)) seconds for pip installs\"`, apt, version, venvPath, venvPath, version, version, verboseFlag, "$(date +%s)", version, nocache, quiet, reqmtsFile.Name(), verboseFlag, "$(date +%s)")
+echo \"METRICS: Took $(($e-$s)) seconds for pip installs\"`, apt, version, venvPath, venvPath, upgradePip, "$(date +%s)", version, nocache, quiet, index, reqmtsFile.Name(), verboseFlag, "$(date +%s)")
+
+	if installer == "uv" {
+		python, err := exec.LookPath("python" + version)
+		if err != nil {
+			python = "python" + version
+		}
+		if nocache != "" {
+			nocache = "--no-cache"
+		}
+		if wheelhouse != "" {
+			index = "--offline --no-index --no-build --find-links " + wheelhouse
+		}
+
+		cmdline = fmt.Sprintf(`%s venv --python %s %s || exit $?
+s=%s
+%s pip install --python %s/bin/python %s %s -r %s %s 1>&2 || exit $?
+e=%s
+echo \"METRICS: Took $(($e-$s)) seconds for uv installs\"`, uv, python, venvPath, "$(date +%s)", uv, venvPath, nocache, index, reqmtsFile.Name(), verboseFlag, "$(date +%s)")
+	}
 
 	cmd := exec.CommandContext(ctx, "/bin/bash", "-c", cmdline)
+	if wheelhouse != "" {
+		// So that any other pip or uv invocation, e.g. by a
+		// requirement of the form `-r other.txt`, also fails
+		// rather than reaching a package index
+		cmd.Env = append(os.Environ(), "PIP_NO_INDEX=1", "UV_OFFLINE=1")
+	}
 	cmd.Dir = filepath.Dir(venvPath)
-	cmd.Stdout = os.Stderr // Stderr so as not to collide with `lunchpail needs` stdout
-	cmd.Stderr = os.Stderr
 
 	alreadyCleanedUp := false
 	installSuccessful := false
@@ -125,8 +186,10 @@ echo \"METRICS: Took $(($e-$s)) seconds for pip installs\"`, apt, version, venvP
 		}
 	}()
 
+	inst := installation{what: "Python requirements", installer: installer, logfile: venvPath + ".log", diagnoses: pythonDiagnoses, offline: wheelhouse != ""}
+
 	t1s := time.Now()
-	if err := cmd.Run(); err != nil {
+	if err := inst.run(cmd, verbose); err != nil {
 		// Clean up the venv cache directory, since we failed at populating it
 		if err := os.RemoveAll(venvPath); err != nil {
 			fmt.Fprintln(os.Stderr, "Unable to clean up venv cache directory after pip install failure", err)
@@ -137,10 +200,10 @@ echo \"METRICS: Took $(($e-$s)) seconds for pip installs\"`, apt, version, venvP
 	installSuccessful = true
 	t1e := time.Now()
 	if verbose {
-		fmt.Fprintf(os.Stderr, "METRICS: Took %s for pip installs\n", util.RelTime(t1s, t1e))
+		fmt.Fprintf(os.Stderr, "METRICS: Took %s for %s installs\n", util.RelTime(t1s, t1e), installer)
 	}
 
-	return path, nil
+	return path, inUse(venvPath, lockfile, opts.HoldFor)
 }
 
 func getSHA256Sum(requirements []byte) (string, error) {
@@ -0,0 +1,22 @@
+package needs
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"lunchpail.io/pkg/build"
+)
+
+// Without wheels, an offline install must fail, rather than reach a
+// package index
+func TestOfflineWithoutWheels(t *testing.T) {
+	t.Setenv("LUNCHPAIL_VENV_CACHEDIR", t.TempDir())
+	requirements := base64.StdEncoding.EncodeToString([]byte("six==1.16.0\n"))
+
+	_, err := requirementsInstall(context.Background(), "3", requirements, Options{LogOptions: build.LogOptions{}, Offline: true})
+	if err == nil || !strings.Contains(err.Error(), "no wheels were found") {
+		t.Fatalf("expected an error about missing wheels, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package needs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// A run of an installer, e.g. pip or micromamba
+type installation struct {
+	// What we are installing, e.g. "Python requirements"
+	what string
+
+	// e.g. pip, uv, or micromamba
+	installer string
+
+	// Where to keep the output of the installer
+	logfile string
+
+	// Known ways for the installer to fail
+	diagnoses []diagnosis
+
+	// Are we installing without reaching a package index?
+	offline bool
+}
+
+// Run the given installer command. We keep its output, so that we can
+// make sense of any failure, but only show it if asked; on failure,
+// we show its tail and return our diagnosis.
+func (inst installation) run(cmd *exec.Cmd, verbose bool) error {
+	log, err := os.Create(inst.logfile)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	var output bytes.Buffer
+	out := io.MultiWriter(log, &output)
+	if verbose {
+		// Stderr so as not to collide with `lunchpail needs` stdout
+		out = io.MultiWriter(log, &output, os.Stderr)
+	}
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := cmd.Run(); err != nil {
+		if !verbose {
+			// Otherwise, we already showed it
+			showTail(output.String())
+		}
+		return inst.diagnose(output.String(), err)
+	}
+
+	return os.Remove(inst.logfile)
+}
+
+// How much of the output of a failed install to show
+const failureTailLines = 40
+
+// Show the end of the output of a failed install, which is where
+// installers explain themselves
+func showTail(output string) {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > failureTailLines {
+		fmt.Fprintf(os.Stderr, "... (%d lines elided)\n", len(lines)-failureTailLines)
+		lines = lines[len(lines)-failureTailLines:]
+	}
+	fmt.Fprintln(os.Stderr, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,57 @@
+package needs
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallationKeepsOutputOfFailure(t *testing.T) {
+	for _, inst := range []installation{
+		{what: "Python requirements", installer: "pip", diagnoses: pythonDiagnoses},
+		{what: "a conda environment", installer: "micromamba"},
+	} {
+		inst.logfile = filepath.Join(t.TempDir(), "install.log")
+
+		cmd := exec.Command("/bin/sh", "-c", "echo resolving; echo 'no such package: foo' 1>&2; echo 'METRICS: Took 1s'; exit 1")
+		err := inst.run(cmd, false)
+
+		var e InstallError
+		if !errors.As(err, &e) {
+			t.Fatalf("%s: expected an InstallError, got %v", inst.installer, err)
+		}
+		if e.Reason != "no such package: foo" {
+			t.Errorf("%s: expected the last thing the installer had to say, got %q", inst.installer, e.Reason)
+		}
+		if !strings.HasPrefix(e.Error(), "Unable to install "+inst.what+" via "+inst.installer) || !strings.Contains(e.Error(), inst.logfile) {
+			t.Errorf("%s: unexpected message %q", inst.installer, e.Error())
+		}
+		if b, err := os.ReadFile(inst.logfile); err != nil || !strings.Contains(string(b), "resolving") {
+			t.Errorf("%s: expected the full output to be kept, got %q %v", inst.installer, b, err)
+		}
+	}
+}
+
+func TestInstallationDiagnosesFailure(t *testing.T) {
+	inst := installation{what: "Python requirements", installer: "pip", logfile: filepath.Join(t.TempDir(), "install.log"), diagnoses: pythonDiagnoses}
+
+	cmd := exec.Command("/bin/sh", "-c", "echo 'ERROR: No matching distribution found for nosuchpkg==1.0'; exit 1")
+	var e InstallError
+	if err := inst.run(cmd, false); !errors.As(err, &e) || e.Reason != "no matching distribution for nosuchpkg==1.0" || e.Hint == "" {
+		t.Fatalf("expected a diagnosis with a hint, got %v", err)
+	}
+}
+
+func TestInstallationRemovesOutputOfSuccess(t *testing.T) {
+	inst := installation{what: "a conda environment", installer: "micromamba", logfile: filepath.Join(t.TempDir(), "install.log")}
+
+	if err := inst.run(exec.Command("/bin/sh", "-c", "echo done"), false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(inst.logfile); !os.IsNotExist(err) {
+		t.Errorf("expected the output of a successful install to be removed, got %v", err)
+	}
+}
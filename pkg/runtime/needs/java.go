@@ -0,0 +1,9 @@
+package needs
+
+import "context"
+
+// Find and install (if needed) a Java runtime
+// @return the directory to add to PATH, if any
+func InstallJava(ctx context.Context, version string, opts Options) (string, error) {
+	return installIfMissing(ctx, "java", version, installJava, opts)
+}
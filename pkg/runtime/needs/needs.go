@@ -4,4 +4,14 @@ import "lunchpail.io/pkg/build"
 
 type Options struct {
 	build.LogOptions
+
+	// Install Python requirements only from bundled wheels, never
+	// from a package index
+	Offline bool
+
+	// Where those wheels are, if not carried by this executable
+	Wheelhouse string
+
+	// Keep the venv from being pruned until this process exits
+	HoldFor int
 }
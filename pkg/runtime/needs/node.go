@@ -0,0 +1,116 @@
+package needs
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+// Find and install (if needed) node, and the packages of the given
+// base64-encoded package.json
+// @return the directory to add to PATH
+func InstallNode(ctx context.Context, version, requirements string, opts Options) (string, error) {
+	dir, err := installIfMissing(ctx, "node", version, installNode, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if requirements != "" {
+		return nodeModulesInstall(ctx, dir, requirements, opts.Verbose)
+	}
+	return dir, nil
+}
+
+// Install the packages into a cache directory keyed by the contents
+// of package.json. Node does not look for modules on PATH, so we
+// return a directory with a `node` that points it to them.
+func nodeModulesInstall(ctx context.Context, nodeDir, requirements string, verbose bool) (string, error) {
+	packageJson, err := base64.StdEncoding.DecodeString(requirements)
+	if err != nil {
+		return "", err
+	}
+
+	if nodeDir != "" {
+		os.Setenv("PATH", nodeDir+":"+os.Getenv("PATH"))
+	}
+	node, err := exec.LookPath("node")
+	if err != nil {
+		return "", err
+	}
+
+	cachedir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sha, err := getSHA256Sum(packageJson)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cachedir, "lunchpail", "node", sha)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	lockfile, err := os.OpenFile(filepath.Join(dir, "lock.txt"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer lockfile.Close()
+	if err := syscall.Flock(int(lockfile.Fd()), syscall.LOCK_EX); err != nil {
+		return "", err
+	}
+
+	bin := filepath.Join(dir, "bin")
+	if _, err := os.Stat(filepath.Join(bin, "node")); err == nil {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Skipping npm install since node modules exist\n")
+		}
+		return bin, nil
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), packageJson, 0644); err != nil {
+		return "", err
+	}
+
+	cmd := exec.CommandContext(ctx, "npm", "install", "--omit=dev")
+	cmd.Dir = dir
+	cmd.Stdout = os.Stderr // Stderr so as not to collide with `lunchpail needs` stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(filepath.Join(dir, "node_modules"))
+		return "", err
+	}
+
+	if err := os.MkdirAll(bin, 0755); err != nil {
+		return "", err
+	}
+	wrapper := fmt.Sprintf(`#!/bin/sh
+NODE_PATH=%s${NODE_PATH:+:$NODE_PATH} exec %s "$@"
+`, filepath.Join(dir, "node_modules"), node)
+
+	return bin, os.WriteFile(filepath.Join(bin, "node"), []byte(wrapper), 0755)
+}
+
+// Install (if needed) the given language runtime
+// @return the directory to add to PATH, if any
+func installIfMissing(ctx context.Context, exe, version string, install func(context.Context, string, bool) (string, error), opts Options) (string, error) {
+	path, err := exec.LookPath(exe)
+	if err == nil {
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "needs found %s\n", path)
+		}
+		return "", nil
+	} else if !errors.Is(err, exec.ErrNotFound) {
+		return "", err
+	}
+
+	if opts.Verbose {
+		fmt.Fprintf(os.Stderr, "needs installing %s %s\n", exe, version)
+	}
+	return install(ctx, version, opts.Verbose)
+}
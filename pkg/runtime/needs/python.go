@@ -20,7 +20,7 @@ func InstallPython(ctx context.Context, version, requirements string, opts Optio
 	}
 	if requirements != "" {
 		//returns bin path where installed
-		return requirementsInstall(ctx, version, requirements, opts.Verbose)
+		return requirementsInstall(ctx, version, requirements, opts)
 	}
 	return "", nil
 }
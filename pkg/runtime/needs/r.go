@@ -0,0 +1,9 @@
+package needs
+
+import "context"
+
+// Find and install (if needed) R
+// @return the directory to add to PATH, if any
+func InstallR(ctx context.Context, version string, opts Options) (string, error) {
+	return installIfMissing(ctx, "Rscript", version, installR, opts)
+}
@@ -0,0 +1,54 @@
+package needs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// The version of rclone we install, unless asked for another
+const RcloneVersion = "v1.68.2"
+
+// Find and install (if needed) the rclone executable
+// @return the directory enclosing the rclone executable
+func InstallRclone(ctx context.Context, version string, opts Options) (string, error) {
+	if version == "" || version == "latest" {
+		version = RcloneVersion
+	} else if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+
+	// We may have installed rclone in a special place. Before we
+	// can call LookPath, make sure that special place is on PATH.
+	dir, err := bindir()
+	if err != nil {
+		return "", err
+	}
+
+	if dir != "" {
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "needs rclone adding dir to PATH=%s\n", dir)
+		}
+		os.Setenv("PATH", os.Getenv("PATH")+":"+dir)
+	}
+
+	path, err := exec.LookPath("rclone")
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			if opts.Verbose {
+				fmt.Fprintln(os.Stderr, "needs rclone installing rclone")
+			}
+			return installRclone(ctx, version, opts.Verbose)
+		}
+		return "", err
+	}
+
+	if opts.Verbose {
+		fmt.Fprintln(os.Stderr, "needs rclone found rclone", path)
+	}
+	return filepath.Dir(path), nil
+}
@@ -108,7 +108,7 @@ func AddFromS3(ctx context.Context, run queue.RunContext, fullpath, endpoint, ac
 
 	group, gctx := errgroup.WithContext(ctx)
 
-	origin, err := NewS3ClientFromOptions(gctx, S3ClientOptions{endpoint, accessKeyId, secretAccessKey})
+	origin, err := NewS3ClientFromOptions(gctx, S3ClientOptions{Endpoint: endpoint, AccessKeyID: accessKeyId, SecretAccessKey: secretAccessKey})
 	if err != nil {
 		return err
 	}
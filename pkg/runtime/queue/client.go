@@ -2,6 +2,7 @@ package queue
 
 import (
 	"context"
+	"net/url"
 	"os"
 	"strings"
 
@@ -31,16 +32,47 @@ type S3ClientStop struct {
 // Initialize minio client object
 func NewS3Client(ctx context.Context) (S3Client, error) {
 	endpoint := os.Getenv("lunchpail_queue_endpoint")
-	accessKeyID := os.Getenv("lunchpail_queue_accessKeyID")
-	secretAccessKey := os.Getenv("lunchpail_queue_secretAccessKey")
+	creds, err := CredentialsFromEnv()
+	if err != nil {
+		return S3Client{}, err
+	}
 
-	return NewS3ClientFromOptions(ctx, S3ClientOptions{endpoint, accessKeyID, secretAccessKey})
+	return NewS3ClientFromOptions(ctx, S3ClientOptions{
+		Endpoint:        endpoint,
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+		CA:              os.Getenv("lunchpail_queue_ca"),
+	})
+}
+
+// The queue credentials, either from the environment or from the
+// credentials file it names
+func CredentialsFromEnv() (queue.Credentials, error) {
+	creds := queue.Credentials{
+		AccessKeyID:     os.Getenv("lunchpail_queue_accessKeyID"),
+		SecretAccessKey: os.Getenv("lunchpail_queue_secretAccessKey"),
+		SessionToken:    os.Getenv("lunchpail_queue_sessionToken"),
+	}
+
+	if file := os.Getenv(queue.CredentialsFileEnvVar); file != "" && creds.AccessKeyID == "" {
+		return queue.ReadCredentials(file)
+	}
+
+	return creds, nil
 }
 
 type S3ClientOptions struct {
 	Endpoint        string
 	AccessKeyID     string
 	SecretAccessKey string
+
+	// For short-lived credentials
+	SessionToken string
+
+	// PEM-encoded certificate authority to trust, in addition to
+	// the system roots
+	CA string
 }
 
 // Initialize minio client object from options
@@ -53,9 +85,15 @@ func NewS3ClientFromOptions(ctx context.Context, opts S3ClientOptions) (S3Client
 	opts.Endpoint = strings.Replace(opts.Endpoint, "https://", "", 1)
 	opts.Endpoint = strings.Replace(opts.Endpoint, "http://", "", 1)
 
+	transport, err := TransportFor(opts.CA)
+	if err != nil {
+		return S3Client{}, err
+	}
+
 	client, err := minio.New(opts.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(opts.AccessKeyID, opts.SecretAccessKey, ""),
-		Secure: useSSL,
+		Creds:     credentials.NewStaticV4(opts.AccessKeyID, opts.SecretAccessKey, opts.SessionToken),
+		Secure:    useSSL,
+		Transport: transport,
 	})
 	if err != nil {
 		return S3Client{}, err
@@ -71,22 +109,17 @@ func NewS3ClientFromOptions(ctx context.Context, opts S3ClientOptions) (S3Client
 
 // Client for a given run in the given backend
 func NewS3ClientForRun(ctx context.Context, backend be.Backend, run queue.RunContext, queue queue.Spec, opts build.LogOptions) (S3ClientStop, error) {
-	endpoint, accessKeyId, secretAccessKey, bucket, stop, err := backend.AccessQueue(ctx, run, queue, opts)
+	endpoint, accessKeyId, secretAccessKey, bucket, ca, stop, err := backend.AccessQueue(ctx, run, queue, opts)
 	if err != nil {
 		return S3ClientStop{}, err
 	}
 
 	// We might be on the client, and so need to replace a docker host ip with localhost
-	if os.Getenv("LUNCHPAIL_RUN") == "" && strings.Contains(endpoint, "http://host.docker.internal") || strings.Contains(endpoint, "http://172.17.0.1") {
-		words := strings.Split(endpoint, ":")
-		port := "9000"
-		if len(words) == 3 {
-			port = words[2]
-		}
-		endpoint = "http://localhost:" + port
+	if os.Getenv("LUNCHPAIL_RUN") == "" {
+		endpoint = localhostForDockerHost(endpoint)
 	}
 
-	c, err := NewS3ClientFromOptions(ctx, S3ClientOptions{Endpoint: endpoint, AccessKeyID: accessKeyId, SecretAccessKey: secretAccessKey})
+	c, err := NewS3ClientFromOptions(ctx, S3ClientOptions{Endpoint: endpoint, AccessKeyID: accessKeyId, SecretAccessKey: secretAccessKey, CA: ca})
 	if err != nil {
 		return S3ClientStop{}, err
 	}
@@ -100,3 +133,19 @@ func NewS3ClientForRun(ctx context.Context, backend be.Backend, run queue.RunCon
 	run.Bucket = c.Paths.Bucket
 	return S3ClientStop{c, run, stop}, nil
 }
+
+// Replace a docker host in the given endpoint with localhost, keeping
+// the scheme (http or https) and port
+func localhostForDockerHost(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Hostname() != "host.docker.internal" && u.Hostname() != "172.17.0.1") {
+		return endpoint
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "9000"
+	}
+	u.Host = "localhost:" + port
+	return u.String()
+}
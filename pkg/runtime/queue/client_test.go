@@ -0,0 +1,23 @@
+package queue
+
+import "testing"
+
+func TestLocalhostForDockerHost(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"http://host.docker.internal:9100", "http://localhost:9100"},
+		{"https://host.docker.internal:9100", "https://localhost:9100"},
+		{"https://172.17.0.1:9100", "https://localhost:9100"},
+		{"http://host.docker.internal", "http://localhost:9000"},
+		{"https://minio.example.com:9000", "https://minio.example.com:9000"},
+		{"localhost:9000", "localhost:9000"},
+	}
+
+	for _, tt := range tests {
+		if got := localhostForDockerHost(tt.endpoint); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.endpoint, tt.want, got)
+		}
+	}
+}
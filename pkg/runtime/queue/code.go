@@ -0,0 +1,108 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"lunchpail.io/pkg/be"
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/queue"
+)
+
+// Upload the binary code (e.g. compiled executables) of the given
+// applications to the run's queue, for workers that cannot take it
+// from a ConfigMap, e.g. those on Kubernetes, whose ConfigMaps are
+// limited to 1MiB
+func UploadCode(ctx context.Context, backend be.Backend, run queue.RunContext, que queue.Spec, apps []hlir.Application, opts build.LogOptions) error {
+	c, err := NewS3ClientForRun(ctx, backend, run, que, opts)
+	if err != nil {
+		return err
+	}
+	defer c.Stop()
+	run.Bucket = c.RunContext.Bucket
+
+	if err := c.Mkdirp(run.Bucket); err != nil {
+		return err
+	}
+
+	return c.PutCode(run, apps, opts)
+}
+
+// Upload the binary code of the given applications, and then mark it
+// as ready
+func (c S3Client) PutCode(run queue.RunContext, apps []hlir.Application, opts build.LogOptions) error {
+	for _, app := range apps {
+		for _, code := range app.Spec.Code {
+			if !code.IsBinary() {
+				// Text code travels with the application
+				continue
+			}
+
+			dst := path.Join(run.AsFile(queue.Code), app.Metadata.Name, filepath.Base(code.Name))
+			if opts.Verbose {
+				fmt.Fprintf(os.Stderr, "Uploading %s to s3 %s\n", code.Name, dst)
+			}
+			if err := c.PutBytes(run.Bucket, dst, []byte(code.Source)); err != nil {
+				return fmt.Errorf("Unable to upload the code of %s: %v", app.Metadata.Name, err)
+			}
+		}
+	}
+
+	// Only now may workers go ahead and download
+	return c.Touch(run.Bucket, run.AsFile(queue.CodeReadyMarker))
+}
+
+// Download, into the given directory, the binary code of the given
+// application, once the run's queue has all of it
+func DownloadCode(ctx context.Context, run queue.RunContext, app, dir string, opts build.LogOptions) error {
+	c, err := NewS3Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.GetCode(run, app, dir, opts)
+}
+
+// Download, into the given directory, the binary code of the given
+// application, waiting until it is all uploaded
+func (c S3Client) GetCode(run queue.RunContext, app, dir string, opts build.LogOptions) error {
+	if opts.Verbose {
+		fmt.Fprintf(os.Stderr, "Waiting for the code of %s in bucket=%s\n", app, run.Bucket)
+	}
+	if err := c.WaitTillExists(run.Bucket, run.AsFile(queue.CodeReadyMarker)); err != nil {
+		return err
+	} else if err := c.context.Err(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	prefix := path.Join(run.AsFile(queue.Code), app) + "/"
+	for o := range c.ListObjects(run.Bucket, prefix, true) {
+		if o.Err != nil {
+			return o.Err
+		}
+
+		local := filepath.Join(dir, strings.TrimPrefix(o.Key, prefix))
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "Downloading s3 %s to %s\n", o.Key, local)
+		}
+		if err := c.Download(run.Bucket, o.Key, local); err != nil {
+			return fmt.Errorf("Unable to download the code of %s: %v", app, err)
+		}
+
+		// e.g. compiled executables
+		if err := os.Chmod(local, 0755); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package queue_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/hlir"
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/runtime/queue/queuetest"
+)
+
+func TestCodeRoundTrip(t *testing.T) {
+	c := queuetest.NewClient(t)
+	if err := c.Mkdirp("test"); err != nil {
+		t.Fatal(err)
+	}
+	run := queue.RunContext{Bucket: "test", RunName: "r"}
+
+	var app hlir.Application
+	app.Metadata.Name = "app"
+	app.Spec.Code = []hlir.Code{
+		{Name: "main", Source: "#!/bin/sh"},
+		{Name: "main-linux-amd64", Source: "\x7fELF\xff"},
+	}
+	var other hlir.Application
+	other.Metadata.Name = "other"
+	other.Spec.Code = []hlir.Code{{Name: "main-linux-arm64", Source: "\x7fELF\xfe"}}
+
+	if err := c.PutCode(run, []hlir.Application{app, other}, build.LogOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.ExistsNow("test", run.AsFile(queue.CodeReadyMarker)) {
+		t.Fatal("expected the code to be marked ready")
+	}
+
+	dir := t.TempDir()
+	if err := c.GetCode(run, "app", dir, build.LogOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the binary code of this application
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Fatalf("expected only the binary code of app, got %v %v", entries, err)
+	}
+	exe := filepath.Join(dir, "main-linux-amd64")
+	if b, err := os.ReadFile(exe); err != nil || string(b) != "\x7fELF\xff" {
+		t.Errorf("expected the executable, got %q %v", b, err)
+	}
+	if info, err := os.Stat(exe); err != nil || info.Mode().Perm()&0111 == 0 {
+		t.Errorf("expected the executable to be executable, got %v %v", info.Mode(), err)
+	}
+}
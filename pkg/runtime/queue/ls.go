@@ -35,6 +35,8 @@ func Ls(ctx context.Context, backend be.Backend, run queue.RunContext, path stri
 		prefix = wildcard.AsFile(queue.FinishedWithFailed)
 	case "blobs":
 		prefix = wildcard.AsFile(queue.Blobs)
+	case "logs":
+		prefix = wildcard.AsFile(queue.Logs)
 	case "meta":
 		prefix = wildcard.AsFile(queue.Meta)
 	default:
@@ -0,0 +1,149 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/runtime/needs"
+)
+
+// How long to wait for a mount to appear
+var mountTimeout = 60 * time.Second
+
+// Expose the given bucket/path as a read-only filesystem at
+// localPath, fetching data lazily as it is read. The rclone process
+// that serves the mount outlives us, until Unmount.
+func Mount(ctx context.Context, remotePath, localPath string, opts build.LogOptions) error {
+	ca := os.Getenv("lunchpail_queue_ca")
+	endpoint := os.Getenv("lunchpail_queue_endpoint")
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		if ca != "" {
+			endpoint = "https://" + endpoint
+		} else {
+			endpoint = "http://" + endpoint
+		}
+	}
+
+	creds, err := CredentialsFromEnv()
+	if err != nil {
+		return err
+	}
+
+	dir, err := needs.InstallRclone(ctx, "latest", needs.Options{LogOptions: opts})
+	if err != nil {
+		return err
+	}
+	rclone := "rclone"
+	if dir != "" {
+		rclone = filepath.Join(dir, "rclone")
+	}
+	if _, err := needs.InstallFuse(ctx, needs.Options{LogOptions: opts}); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(localPath, 0755); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Mounting remotePath=%s localPath=%s\n", remotePath, localPath)
+
+	// NOT CommandContext, as rclone must outlive us
+	args := []string{"mount", ":s3:" + remotePath, localPath, "--read-only", "--dir-cache-time", "1h"}
+	if ca != "" {
+		// rclone reads the certificate authority only as it
+		// starts, so we need not keep this file around
+		cafile, err := writeTemp("lunchpail-queue-ca-*.pem", ca)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(cafile)
+		args = append(args, "--ca-cert", cafile)
+	}
+	cmd := exec.Command(rclone, args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"RCLONE_S3_PROVIDER=Other",
+		"RCLONE_S3_ENDPOINT="+endpoint,
+		"RCLONE_S3_ACCESS_KEY_ID="+creds.AccessKeyID,
+		"RCLONE_S3_SECRET_ACCESS_KEY="+creds.SecretAccessKey,
+		"RCLONE_S3_SESSION_TOKEN="+creds.SessionToken,
+	)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.After(mountTimeout)
+	for {
+		if isMountPoint(localPath) {
+			return nil
+		}
+
+		select {
+		case err := <-exited:
+			return fmt.Errorf("Unable to mount %s: %v", remotePath, err)
+		case <-deadline:
+			cmd.Process.Kill()
+			return fmt.Errorf("Timed out waiting to mount %s", remotePath)
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
+
+// Tear down a mount made by Mount, which also stops its rclone process
+func Unmount(ctx context.Context, localPath string, opts build.LogOptions) error {
+	if !isMountPoint(localPath) {
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "Not unmounting localPath=%s, as it is not mounted\n", localPath)
+		}
+		return nil
+	}
+
+	cmdline, err := needs.InstallFuse(ctx, needs.Options{LogOptions: opts})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Unmounting localPath=%s\n", localPath)
+	cmd := exec.CommandContext(ctx, cmdline[0], append(cmdline[1:], localPath)...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Write content to a new temporary file, returning its path
+func writeTemp(pattern, content string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(content); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// Does path reside on a different device than its parent?
+func isMountPoint(path string) bool {
+	var self, parent syscall.Stat_t
+	if err := syscall.Stat(path, &self); err != nil {
+		return false
+	}
+	if err := syscall.Stat(filepath.Dir(filepath.Clean(path)), &parent); err != nil {
+		return false
+	}
+	return self.Dev != parent.Dev
+}
@@ -0,0 +1,161 @@
+// Package queuetest provides a fake S3 server for tests of code
+// that uses the queue
+package queuetest
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"lunchpail.io/pkg/runtime/queue"
+)
+
+// Just enough of S3 to exercise the S3Client: objects may be put
+// (honoring If-None-Match), stat'd, fetched, listed, and removed
+type fakeS3 struct {
+	sync.Mutex
+	objects map[string]string
+}
+
+type fakeListing struct {
+	XMLName        xml.Name `xml:"ListBucketResult"`
+	Name           string
+	Prefix         string
+	IsTruncated    bool
+	Contents       []fakeObject
+	CommonPrefixes []fakePrefix
+}
+
+type fakeObject struct {
+	Key  string
+	Size int
+	ETag string
+}
+
+type fakePrefix struct {
+	Prefix string
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Query().Has("location"):
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">us-east-1</LocationConstraint>`)
+
+	case key == "" && r.Method == http.MethodGet:
+		prefix := r.URL.Query().Get("prefix")
+		listing := fakeListing{Name: bucket, Prefix: prefix}
+		keys := []string{}
+		for k := range s.objects {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			recursive := r.URL.Query().Get("delimiter") == ""
+			if strings.HasPrefix(k, prefix) && (recursive || !strings.Contains(strings.TrimPrefix(k, prefix), "/")) {
+				listing.Contents = append(listing.Contents, fakeObject{Key: k, Size: len(s.objects[k]), ETag: `"x"`})
+			}
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		xml.NewEncoder(w).Encode(listing)
+
+	case r.Method == http.MethodPut:
+		if _, exists := s.objects[key]; exists && r.Header.Get("If-None-Match") == "*" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold</Message></Error>`)
+			return
+		}
+		content, err := readBody(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.objects[key] = content
+		w.Header().Set("ETag", `"x"`)
+
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		content, exists := s.objects[key]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			}
+			return
+		}
+		w.Header().Set("ETag", `"x"`)
+		w.Header().Set("Last-Modified", "Mon, 19 Oct 2026 00:00:00 GMT")
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, content)
+		}
+
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// Without TLS, the client streams the content in signed chunks,
+// each "<hex size>;chunk-signature=<signature>\r\n<data>\r\n", and
+// ending with a chunk of size zero
+func readBody(r *http.Request) (string, error) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return string(b), nil
+	}
+
+	var content strings.Builder
+	rest := string(b)
+	for {
+		header, after, ok := strings.Cut(rest, "\r\n")
+		if !ok {
+			return "", fmt.Errorf("Malformed chunk %q", rest)
+		}
+		hexSize, _, _ := strings.Cut(header, ";")
+		size, err := strconv.ParseInt(hexSize, 16, 64)
+		if err != nil {
+			return "", err
+		} else if size == 0 {
+			return content.String(), nil
+		} else if int64(len(after)) < size+2 {
+			return "", fmt.Errorf("Truncated chunk %q", after)
+		}
+		content.WriteString(after[:size])
+		rest = after[size+2:]
+	}
+}
+
+// A client of a fresh, empty, fake S3 server
+func NewClient(t *testing.T) queue.S3Client {
+	t.Helper()
+
+	server := httptest.NewServer(&fakeS3{objects: map[string]string{}})
+	t.Cleanup(server.Close)
+
+	c, err := queue.NewS3ClientFromOptions(context.Background(), queue.S3ClientOptions{
+		Endpoint:        server.URL,
+		AccessKeyID:     "ak",
+		SecretAccessKey: "sk",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
@@ -7,8 +7,10 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -47,6 +49,12 @@ func (s3 S3Client) Exists(bucket, prefix, file string) bool {
 	}
 }
 
+// As with Exists, but without waiting for the server to come up
+func (s3 S3Client) ExistsNow(bucket, filePath string) bool {
+	_, err := s3.client.StatObject(s3.context, bucket, filePath, minio.StatObjectOptions{})
+	return err == nil
+}
+
 func (s3 S3Client) Copyto(sourceBucket, source, destBucket, dest string) error {
 	src := minio.CopySrcOptions{
 		Bucket: sourceBucket,
@@ -197,6 +205,60 @@ func (s3 S3Client) Mark(bucket, filePath, marker string) error {
 	}
 }
 
+// As with Mark, but only if `filePath` does not yet exist. Of several
+// racing callers, exactly one will create the file.
+// @return whether we created the file
+func (s3 S3Client) MarkIfAbsent(bucket, filePath, marker string) (bool, error) {
+	opts := minio.PutObjectOptions{}
+	opts.SetMatchETagExcept("*") // i.e. If-None-Match: *
+	for {
+		if _, err := s3.client.PutObject(s3.context, bucket, filePath, strings.NewReader(marker), int64(len(marker)), opts); err == nil {
+			return true, nil
+		} else if minio.ToErrorResponse(err).StatusCode == http.StatusPreconditionFailed {
+			return false, nil
+		} else if !s3.retryOnError(err) {
+			return false, err
+		}
+	}
+}
+
+// Claim `filePath` for `claimant`, unless someone else already has.
+// Of several racing claimants, exactly one will win.
+// @return the winning claimant
+func (s3 S3Client) Claim(bucket, filePath, claimant string) (string, error) {
+	if created, err := s3.MarkIfAbsent(bucket, filePath, claimant); err != nil {
+		return "", err
+	} else if created {
+		return claimant, nil
+	}
+
+	return s3.Get(bucket, filePath)
+}
+
+// Mark the first of `prefix/1`, `prefix/2`, ... that does not yet
+// exist. Racing callers will each be given a different number.
+// @return the number we marked
+func (s3 S3Client) MarkNext(bucket, prefix, marker string) (int, error) {
+	prior, err := s3.Lsf(bucket, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	for n := len(prior) + 1; ; n++ {
+		if created, err := s3.MarkIfAbsent(bucket, filepath.Join(prefix, strconv.Itoa(n)), marker); err != nil {
+			return 0, err
+		} else if created {
+			return n, nil
+		}
+	}
+}
+
+// Upload the given bytes as `filePath`, without retrying on error
+func (s3 S3Client) PutBytes(bucket, filePath string, b []byte) error {
+	_, err := s3.client.PutObject(s3.context, bucket, filePath, bytes.NewReader(b), int64(len(b)), minio.PutObjectOptions{})
+	return err
+}
+
 func (s3 S3Client) StreamingUpload(bucket, filePath string, reader io.Reader) error {
 	// Warning: without PartSize, the minio client-go allocates a ridiculously massive buffer.
 	// Double Warning: if you provide PartSize < 5Mi, you get immediate failure.
@@ -221,6 +283,11 @@ func (s3 S3Client) Get(bucket, filePath string) (string, error) {
 	return content.String(), nil
 }
 
+// Open a reader over the content of the given object
+func (s3 S3Client) Open(bucket, filePath string) (io.ReadCloser, error) {
+	return s3.client.GetObject(s3.context, bucket, filePath, minio.GetObjectOptions{})
+}
+
 func (s3 S3Client) Cat(bucket, filePath string) error {
 	s, err := s3.client.GetObject(s3.context, bucket, filePath, minio.GetObjectOptions{})
 	if err != nil {
@@ -0,0 +1,99 @@
+package queue_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"lunchpail.io/pkg/runtime/queue/queuetest"
+)
+
+func TestMarkIfAbsent(t *testing.T) {
+	c := queuetest.NewClient(t)
+
+	if created, err := c.MarkIfAbsent("test", "claim", "w0"); err != nil || !created {
+		t.Fatalf("expected to create claim, got created=%v err=%v", created, err)
+	}
+	if created, err := c.MarkIfAbsent("test", "claim", "w1"); err != nil || created {
+		t.Fatalf("expected existing claim to be kept, got created=%v err=%v", created, err)
+	}
+	if owner, err := c.Get("test", "claim"); err != nil || owner != "w0" {
+		t.Fatalf("expected claim to be w0, got %q err=%v", owner, err)
+	}
+}
+
+func TestMarkNextRace(t *testing.T) {
+	c := queuetest.NewClient(t)
+
+	const racers = 8
+	var wg sync.WaitGroup
+	numbers := make(chan int, racers)
+	for i := range racers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := c.MarkNext("test", "attempts/task.1", fmt.Sprintf("pool/w%d", i))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			numbers <- n
+		}()
+	}
+	wg.Wait()
+	close(numbers)
+
+	seen := map[int]bool{}
+	for n := range numbers {
+		if seen[n] {
+			t.Fatalf("attempt %d was given out twice", n)
+		}
+		seen[n] = true
+	}
+	for n := 1; n <= racers; n++ {
+		if !seen[n] {
+			t.Fatalf("expected attempts 1..%d, got %v", racers, seen)
+		}
+	}
+}
+
+func TestClaimRace(t *testing.T) {
+	c := queuetest.NewClient(t)
+
+	const racers = 8
+	var wg sync.WaitGroup
+	winners := make(chan string, racers)
+	for i := range racers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			me := fmt.Sprintf("pool/w%d", i)
+			owner, err := c.Claim("test", "claims/task.1", me)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if owner == me {
+				winners <- me
+			}
+		}()
+	}
+	wg.Wait()
+	close(winners)
+
+	var won []string
+	for w := range winners {
+		won = append(won, w)
+	}
+	if len(won) != 1 {
+		t.Fatalf("expected exactly one winner, got %v", won)
+	}
+	if owner, err := c.Get("test", "claims/task.1"); err != nil || owner != won[0] {
+		t.Fatalf("expected the claim to record %s, got %q err=%v", won[0], owner, err)
+	}
+
+	// A claimant that already won should still win when it asks again
+	if owner, err := c.Claim("test", "claims/task.1", won[0]); err != nil || owner != won[0] {
+		t.Fatalf("expected %s to keep its claim, got %q err=%v", won[0], owner, err)
+	}
+}
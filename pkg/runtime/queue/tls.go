@@ -0,0 +1,33 @@
+package queue
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// An http transport that trusts the given PEM-encoded certificate
+// authority, or nil (i.e. the default transport) if ca is ""
+func TransportFor(ca string) (http.RoundTripper, error) {
+	if ca == "" {
+		return nil, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM([]byte(ca)) {
+		return nil, fmt.Errorf("Invalid queue certificate authority")
+	}
+
+	transport, err := minio.DefaultTransport(true)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig.RootCAs = pool
+
+	return transport, nil
+}
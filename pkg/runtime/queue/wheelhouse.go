@@ -0,0 +1,96 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"lunchpail.io/pkg/be"
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/queue"
+)
+
+// Upload the given wheelhouse to the run's queue, for workers whose
+// executable does not carry it, e.g. those on Kubernetes, which run a
+// generic lunchpail
+func UploadWheelhouse(ctx context.Context, backend be.Backend, run queue.RunContext, que queue.Spec, wheelhouse string, opts build.LogOptions) error {
+	c, err := NewS3ClientForRun(ctx, backend, run, que, opts)
+	if err != nil {
+		return err
+	}
+	defer c.Stop()
+	run.Bucket = c.RunContext.Bucket
+
+	if err := c.Mkdirp(run.Bucket); err != nil {
+		return err
+	}
+
+	return c.PutWheelhouse(run, wheelhouse, opts)
+}
+
+// Upload the given wheelhouse, keeping its layout, and then mark it
+// as ready
+func (c S3Client) PutWheelhouse(run queue.RunContext, wheelhouse string, opts build.LogOptions) error {
+	prefix := run.AsFile(queue.Wheelhouse)
+	if err := filepath.WalkDir(wheelhouse, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(wheelhouse, path)
+		if err != nil {
+			return err
+		}
+
+		dst := filepath.ToSlash(filepath.Join(prefix, rel))
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "Uploading %s to s3 %s\n", path, dst)
+		}
+		return c.Upload(run.Bucket, path, dst)
+	}); err != nil {
+		return fmt.Errorf("Unable to upload Python wheels: %v", err)
+	}
+
+	// Only now may workers go ahead and install
+	return c.Touch(run.Bucket, run.AsFile(queue.WheelhouseReadyMarker))
+}
+
+// Download, into the given directory, the wheels for the given
+// requirements on this platform, once the run's queue has all of
+// them. Returns where the wheels are.
+func DownloadWheelhouse(ctx context.Context, run queue.RunContext, requirements []byte, dir string, opts build.LogOptions) (string, error) {
+	c, err := NewS3Client(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return c.GetWheelhouse(run, requirements, runtime.GOOS, runtime.GOARCH, dir, opts)
+}
+
+// Download, into the given directory, the wheels for the given
+// requirements and platform, waiting until they are all uploaded
+func (c S3Client) GetWheelhouse(run queue.RunContext, requirements []byte, goos, goarch, dir string, opts build.LogOptions) (string, error) {
+	if opts.Verbose {
+		fmt.Fprintf(os.Stderr, "Waiting for Python wheels in bucket=%s\n", run.Bucket)
+	}
+	if err := c.WaitTillExists(run.Bucket, run.AsFile(queue.WheelhouseReadyMarker)); err != nil {
+		return "", err
+	} else if err := c.context.Err(); err != nil {
+		return "", err
+	}
+
+	remote := filepath.ToSlash(filepath.Join(run.AsFile(queue.Wheelhouse), build.WheelhouseKey(requirements, goos, goarch)))
+	if err := c.DownloadFolder(run.Bucket, remote+"/", dir); err != nil {
+		return "", fmt.Errorf("Unable to download Python wheels: %v", err)
+	}
+
+	wheelhouse := filepath.Join(dir, filepath.FromSlash(remote))
+	if _, err := os.Stat(wheelhouse); err != nil {
+		return "", fmt.Errorf("This application carries no Python wheels for its requirements on %s/%s. Re-build with --python-platform %s/%s", goos, goarch, goos, goarch)
+	}
+
+	return wheelhouse, nil
+}
@@ -0,0 +1,79 @@
+package queue_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/runtime/queue/queuetest"
+)
+
+func TestWheelhouseRoundTrip(t *testing.T) {
+	c := queuetest.NewClient(t)
+	if err := c.Mkdirp("test"); err != nil {
+		t.Fatal(err)
+	}
+	run := queue.RunContext{Bucket: "test", RunName: "r"}
+	requirements := []byte("six==1.16.0\n")
+
+	// A wheelhouse as bundled at build time, with wheels for two
+	// platforms
+	wheelhouse := t.TempDir()
+	for _, platform := range []string{"linux-amd64", "linux-arm64"} {
+		dir := filepath.Join(wheelhouse, filepath.Dir(build.WheelhouseKey(requirements, "linux", "amd64")), platform)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "six-1.16.0-py2.py3-none-any.whl"), []byte(platform), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := c.PutWheelhouse(run, wheelhouse, build.LogOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.ExistsNow("test", run.AsFile(queue.WheelhouseReadyMarker)) {
+		t.Fatal("expected the wheelhouse to be marked ready")
+	}
+
+	got, err := c.GetWheelhouse(run, requirements, "linux", "arm64", t.TempDir(), build.LogOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(filepath.Join(got, "six-1.16.0-py2.py3-none-any.whl")); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "linux-arm64" {
+		t.Errorf("expected the linux-arm64 wheel, got %q", b)
+	}
+	if entries, err := os.ReadDir(got); err != nil || len(entries) != 1 {
+		t.Errorf("expected only the wheels for linux-arm64, got %v %v", entries, err)
+	}
+}
+
+func TestWheelhouseForOtherPlatform(t *testing.T) {
+	c := queuetest.NewClient(t)
+	if err := c.Mkdirp("test"); err != nil {
+		t.Fatal(err)
+	}
+	run := queue.RunContext{Bucket: "test", RunName: "r"}
+	requirements := []byte("six==1.16.0\n")
+
+	wheelhouse := t.TempDir()
+	dir := filepath.Join(wheelhouse, build.WheelhouseKey(requirements, "linux", "amd64"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(filepath.Join(dir, "six.whl"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutWheelhouse(run, wheelhouse, build.LogOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := c.GetWheelhouse(run, requirements, "linux", "arm64", t.TempDir(), build.LogOptions{})
+	if err == nil || !strings.Contains(err.Error(), "--python-platform linux/arm64") {
+		t.Fatalf("expected an error suggesting --python-platform, got %v", err)
+	}
+}
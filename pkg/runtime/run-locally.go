@@ -66,5 +66,5 @@ func RunLocally(ctx context.Context, component string, lowerir string, opts buil
 		return err
 	}
 
-	return shell.Spawn(ctx, c, ir, opts)
+	return shell.Spawn(ctx, c, ir, shell.SpawnOptions{LogOptions: opts})
 }
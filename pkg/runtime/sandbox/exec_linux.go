@@ -0,0 +1,183 @@
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Not (yet) defined by x/sys/unix
+const (
+	landlockRuleNetPort = 2
+)
+
+type landlockNetPortAttr struct {
+	AllowedAccess uint64
+	Port          uint64
+}
+
+// Filesystem access rights, by the Landlock ABI version that
+// introduced them
+var fsAccessByAbi = []uint64{
+	1: unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE | unix.LANDLOCK_ACCESS_FS_READ_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_DIR | unix.LANDLOCK_ACCESS_FS_REMOVE_DIR | unix.LANDLOCK_ACCESS_FS_REMOVE_FILE |
+		unix.LANDLOCK_ACCESS_FS_MAKE_CHAR | unix.LANDLOCK_ACCESS_FS_MAKE_DIR | unix.LANDLOCK_ACCESS_FS_MAKE_REG |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SOCK | unix.LANDLOCK_ACCESS_FS_MAKE_FIFO | unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SYM,
+	2: unix.LANDLOCK_ACCESS_FS_REFER,
+	3: unix.LANDLOCK_ACCESS_FS_TRUNCATE,
+	5: unix.LANDLOCK_ACCESS_FS_IOCTL_DEV,
+}
+
+// The subset of rights that apply to files, rather than directories
+const fileAccess = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE | unix.LANDLOCK_ACCESS_FS_READ_FILE |
+	unix.LANDLOCK_ACCESS_FS_TRUNCATE | unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+
+const readAccess = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_READ_DIR
+
+// The network access rights we restrict, available as of ABI netAbi
+const netAccess = unix.LANDLOCK_ACCESS_NET_BIND_TCP | unix.LANDLOCK_ACCESS_NET_CONNECT_TCP
+
+// The Landlock ABI version supported by this kernel, or an error if
+// Landlock is not supported
+func abi() (int, error) {
+	v, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		return 0, fmt.Errorf("Sandboxing requires a Linux kernel with Landlock enabled: %v", errno)
+	}
+	return int(v), nil
+}
+
+// The first Landlock ABI version able to confine network access
+const netAbi = 4
+
+// Error if this host cannot sandbox processes. Unless
+// allowUnconfinedNetwork, this includes confining their network
+// access.
+func Available(allowUnconfinedNetwork bool) error {
+	version, err := abi()
+	if err != nil {
+		return err
+	}
+	return checkNetAbi(version, allowUnconfinedNetwork)
+}
+
+func checkNetAbi(version int, allowUnconfinedNetwork bool) error {
+	if version < netAbi && !allowUnconfinedNetwork {
+		return fmt.Errorf("Sandboxing requires a Linux kernel able to confine network access (Landlock ABI %d < %d). To sandbox only the filesystem, pass --sandbox-allow-unconfined-network", version, netAbi)
+	}
+	return nil
+}
+
+// Replace this process with argv, confined by the given policy
+func Exec(policy Policy, argv []string) error {
+	path, err := exec.LookPath(argv[0])
+	if err != nil {
+		return err
+	}
+
+	// Landlock confines only the calling thread, so we must
+	// execve from the same thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := restrict(policy); err != nil {
+		return err
+	}
+
+	return syscall.Exec(path, argv, os.Environ())
+}
+
+func restrict(policy Policy) error {
+	version, err := abi()
+	if err != nil {
+		return err
+	}
+	if err := checkNetAbi(version, policy.AllowUnconfinedNetwork); err != nil {
+		return err
+	}
+
+	var handledFs uint64
+	for v, access := range fsAccessByAbi {
+		if v <= version {
+			handledFs |= access
+		}
+	}
+
+	attr := unix.LandlockRulesetAttr{Access_fs: handledFs}
+	if version >= netAbi {
+		attr.Access_net = netAccess
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: network access is not confined, as this kernel does not support it (Landlock ABI %d < %d)\n", version, netAbi)
+	}
+
+	fd, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("Unable to create sandbox ruleset: %v", errno)
+	}
+	ruleset := int(fd)
+	defer unix.Close(ruleset)
+
+	for _, path := range policy.ReadOnly {
+		if err := allowPath(ruleset, path, readAccess&handledFs); err != nil {
+			return err
+		}
+	}
+	for _, path := range policy.ReadWrite {
+		if err := allowPath(ruleset, path, handledFs); err != nil {
+			return err
+		}
+	}
+
+	if version >= netAbi {
+		for _, port := range policy.ConnectPorts {
+			rule := landlockNetPortAttr{AllowedAccess: unix.LANDLOCK_ACCESS_NET_CONNECT_TCP, Port: uint64(port)}
+			if _, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(ruleset), landlockRuleNetPort, uintptr(unsafe.Pointer(&rule)), 0, 0, 0); errno != 0 {
+				return fmt.Errorf("Unable to allow port %d in sandbox: %v", port, errno)
+			}
+		}
+	}
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return err
+	}
+
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, uintptr(ruleset), 0, 0); errno != 0 {
+		return fmt.Errorf("Unable to enter sandbox: %v", errno)
+	}
+
+	return nil
+}
+
+// Allow the given access beneath the given path. Paths that do not
+// exist are skipped.
+func allowPath(ruleset int, path string, access uint64) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if !info.IsDir() {
+		access &= fileAccess
+	}
+
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	rule := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(fd)}
+	if _, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(ruleset), unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(&rule)), 0, 0, 0); errno != 0 {
+		return fmt.Errorf("Unable to allow %s in sandbox: %v", path, errno)
+	}
+
+	return nil
+}
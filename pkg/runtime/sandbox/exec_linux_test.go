@@ -0,0 +1,98 @@
+package sandbox
+
+import (
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestFailClosedWithoutNetworkConfinement(t *testing.T) {
+	if err := checkNetAbi(3, false); err == nil {
+		t.Fatal("expected an error on kernels that cannot confine network access")
+	}
+	if err := checkNetAbi(3, true); err != nil {
+		t.Fatalf("expected the opt-out to allow unconfined network access, got %v", err)
+	}
+	if err := checkNetAbi(netAbi, false); err != nil {
+		t.Fatalf("expected no error on kernels that confine network access, got %v", err)
+	}
+}
+
+// Landlock is irrevocable, so we confine a child process, which runs
+// TestRestrictHelper
+func TestRestrict(t *testing.T) {
+	if err := Available(false); err != nil {
+		t.Skip(err)
+	}
+
+	allowed := t.TempDir()
+	forbidden := t.TempDir()
+	for _, dir := range []string{allowed, forbidden} {
+		if err := os.WriteFile(filepath.Join(dir, "f"), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var ports []string
+	for range 2 {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+		ports = append(ports, strconv.Itoa(l.Addr().(*net.TCPAddr).Port))
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRestrictHelper$")
+	cmd.Env = append(os.Environ(),
+		"SANDBOX_TEST_ALLOWED="+allowed,
+		"SANDBOX_TEST_FORBIDDEN="+forbidden,
+		"SANDBOX_TEST_ALLOWED_PORT="+ports[0],
+		"SANDBOX_TEST_FORBIDDEN_PORT="+ports[1],
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
+
+func TestRestrictHelper(t *testing.T) {
+	allowed := os.Getenv("SANDBOX_TEST_ALLOWED")
+	if allowed == "" {
+		t.Skip("run by TestRestrict")
+	}
+	forbidden := os.Getenv("SANDBOX_TEST_FORBIDDEN")
+	allowedPort, _ := strconv.Atoi(os.Getenv("SANDBOX_TEST_ALLOWED_PORT"))
+	forbiddenPort := os.Getenv("SANDBOX_TEST_FORBIDDEN_PORT")
+
+	// Landlock confines only the calling thread
+	runtime.LockOSThread()
+
+	if err := restrict(Policy{ReadOnly: []string{allowed}, ConnectPorts: []int{allowedPort}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.ReadFile(filepath.Join(allowed, "f")); err != nil {
+		t.Errorf("expected to read beneath an allowed path, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(allowed, "f"), []byte("y"), 0644); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("expected not to write beneath a read-only path, got %v", err)
+	}
+	if _, err := os.ReadFile(filepath.Join(forbidden, "f")); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("expected not to read beneath other paths, got %v", err)
+	}
+
+	if c, err := net.Dial("tcp", "127.0.0.1:"+strconv.Itoa(allowedPort)); err != nil {
+		t.Errorf("expected to connect to an allowed port, got %v", err)
+	} else {
+		c.Close()
+	}
+	if c, err := net.Dial("tcp", "127.0.0.1:"+forbiddenPort); err == nil {
+		c.Close()
+		t.Error("expected not to connect to other ports")
+	}
+}
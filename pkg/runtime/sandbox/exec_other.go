@@ -0,0 +1,18 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Error if this host cannot sandbox processes
+func Available(allowUnconfinedNetwork bool) error {
+	return fmt.Errorf("Sandboxing is supported only on Linux, not %s", runtime.GOOS)
+}
+
+// Replace this process with argv, confined by the given policy
+func Exec(policy Policy, argv []string) error {
+	return Available(policy.AllowUnconfinedNetwork)
+}
@@ -0,0 +1,80 @@
+package sandbox
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// What a sandboxed process may touch. Anything else on the
+// filesystem is off limits, as is connecting to any other TCP port.
+//
+// Note that network access is confined by port, not by host: the
+// process may connect to any host on an allowed port. UDP (and thus
+// DNS) is not confined at all.
+type Policy struct {
+	// Paths the process may read, write, and execute
+	ReadWrite []string
+
+	// Paths the process may read and execute
+	ReadOnly []string
+
+	// TCP ports to which the process may connect
+	ConnectPorts []int
+
+	// Run even if this kernel cannot confine network access, in
+	// which case the process may connect anywhere. Otherwise, we
+	// refuse to run on such kernels.
+	AllowUnconfinedNetwork bool
+}
+
+// The command line that runs argv confined by this policy, using the
+// given lunchpail executable
+func (policy Policy) Wrap(exe string, argv []string) []string {
+	cmdline := []string{exe, "component", "sandbox"}
+	for _, path := range policy.ReadWrite {
+		cmdline = append(cmdline, "--rw", path)
+	}
+	for _, path := range policy.ReadOnly {
+		cmdline = append(cmdline, "--ro", path)
+	}
+	for _, port := range policy.ConnectPorts {
+		cmdline = append(cmdline, "--port", strconv.Itoa(port))
+	}
+	if policy.AllowUnconfinedNetwork {
+		cmdline = append(cmdline, "--allow-unconfined-network")
+	}
+
+	return append(append(cmdline, "--"), argv...)
+}
+
+// The port of the given endpoint, accounting for the default ports of
+// http and https. The endpoint may also be a scheme-less host:port,
+// as the local backend uses.
+func PortOf(endpoint string) (int, error) {
+	if !strings.Contains(endpoint, "://") {
+		if _, port, err := net.SplitHostPort(endpoint); err == nil {
+			return strconv.Atoi(port)
+		}
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return -1, err
+	}
+
+	if u.Port() != "" {
+		return strconv.Atoi(u.Port())
+	}
+
+	switch u.Scheme {
+	case "https":
+		return 443, nil
+	case "http":
+		return 80, nil
+	}
+
+	return -1, fmt.Errorf("Unable to determine the port of endpoint %s", endpoint)
+}
@@ -0,0 +1,42 @@
+package sandbox
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	policy := Policy{ReadWrite: []string{"/work"}, ReadOnly: []string{"/usr"}, ConnectPorts: []int{9000}}
+
+	got := policy.Wrap("/bin/lunchpail", []string{"python3", "main.py", "--rw"})
+	want := []string{"/bin/lunchpail", "component", "sandbox", "--rw", "/work", "--ro", "/usr", "--port", "9000", "--", "python3", "main.py", "--rw"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	policy.AllowUnconfinedNetwork = true
+	if got := policy.Wrap("/bin/lunchpail", []string{"true"}); !slices.Contains(got, "--allow-unconfined-network") {
+		t.Fatalf("expected the opt-out to be passed along, got %v", got)
+	}
+}
+
+func TestPortOf(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     int
+		wantErr  bool
+	}{
+		{"http://localhost:9000", 9000, false},
+		{"https://minio.example.com", 443, false},
+		{"http://minio.example.com", 80, false},
+		{"localhost:9000", 9000, false},
+		{"minio.example.com", -1, true},
+	}
+
+	for _, tt := range tests {
+		got, err := PortOf(tt.endpoint)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: expected %d (error %v), got %d (error %v)", tt.endpoint, tt.want, tt.wantErr, got, err)
+		}
+	}
+}
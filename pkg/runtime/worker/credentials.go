@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/runtime/minio"
+	s3 "lunchpail.io/pkg/runtime/queue"
+)
+
+// Queue credentials that let the handler write only what its role
+// requires, so that a buggy handler cannot clobber the rest of the
+// run. These are minted by the queue server, which alone holds the
+// root credentials needed to do so; we only relay them.
+type handlerCredentials struct {
+	client  s3.S3Client
+	run     queue.RunContext
+	role    minio.Role
+	mu      sync.Mutex
+	current minio.VendedCredentials
+}
+
+// Obtain scoped queue credentials for the handler, or nil if the
+// queue is not one we manage (and so we have no way to mint them), in
+// which case the handler inherits our credentials
+func scopedCredentialsForHandler(ctx context.Context, client s3.S3Client, opts Options) (*handlerCredentials, error) {
+	if !opts.ScopedCredentials {
+		return nil, nil
+	}
+
+	role := minio.WorkerRole
+	if opts.Dispatcher {
+		role = minio.DispatcherRole
+	}
+
+	creds := &handlerCredentials{client: client, run: opts.RunContext, role: role}
+	if _, err := creds.get(ctx); err != nil {
+		return nil, fmt.Errorf("Unable to obtain scoped queue credentials for the handler: %v", err)
+	}
+
+	return creds, nil
+}
+
+// The current credentials, requesting new ones if they are about to
+// expire
+func (creds *handlerCredentials) get(ctx context.Context) (queue.Credentials, error) {
+	creds.mu.Lock()
+	defer creds.mu.Unlock()
+
+	if creds.current.AccessKeyID == "" || creds.current.IsExpiring() {
+		v, err := minio.RequestCredentials(ctx, creds.client, creds.run, creds.role)
+		if err != nil {
+			return queue.Credentials{}, err
+		}
+		creds.current = v
+	}
+
+	return creds.current.Credentials, nil
+}
+
+func isQueueCredential(env string) bool {
+	for _, name := range []string{"lunchpail_queue_accessKeyID", "lunchpail_queue_secretAccessKey", "lunchpail_queue_sessionToken", queue.CredentialsFileEnvVar} {
+		if strings.HasPrefix(env, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// The environment for the handler, with our queue credentials
+// swapped out for its scoped credentials
+func (p taskProcessor) handlerEnv() ([]string, error) {
+	if p.handlerCreds == nil {
+		// Inherit our environment
+		return nil, nil
+	}
+
+	v, err := p.handlerCreds.get(p.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to refresh scoped queue credentials for the handler: %v", err)
+	}
+
+	return append(slices.DeleteFunc(os.Environ(), isQueueCredential),
+		"lunchpail_queue_accessKeyID="+v.AccessKeyID,
+		"lunchpail_queue_secretAccessKey="+v.SecretAccessKey,
+		"lunchpail_queue_sessionToken="+v.SessionToken,
+	), nil
+}
@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/runtime/minio"
+	s3 "lunchpail.io/pkg/runtime/queue"
+	"lunchpail.io/pkg/runtime/queue/queuetest"
+)
+
+var testRun = queue.RunContext{Bucket: "test", RunName: "r", PoolName: "p", WorkerName: "w0"}
+
+// Play the part of the queue server, answering the next credentials
+// request with `answer`
+func answerNextRequest(t *testing.T, c s3.S3Client, answer minio.VendedCredentials) {
+	t.Helper()
+
+	b, err := json.Marshal(answer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for !c.ExistsNow(testRun.Bucket, testRun.AsFile(queue.CredentialsRequest)) {
+			time.Sleep(100 * time.Millisecond)
+		}
+		c.Rm(testRun.Bucket, testRun.AsFile(queue.CredentialsRequest))
+		c.PutBytes(testRun.Bucket, testRun.AsFile(queue.ScopedCredentials), b)
+	}()
+}
+
+func TestHandlerEnvHasOnlyScopedCredentials(t *testing.T) {
+	t.Setenv("lunchpail_queue_accessKeyID", "R00TKEY")
+	t.Setenv("lunchpail_queue_secretAccessKey", "R00TSECRET")
+	t.Setenv(queue.CredentialsFileEnvVar, "/path/to/credentials.json")
+
+	c := queuetest.NewClient(t)
+	answerNextRequest(t, c, minio.VendedCredentials{
+		Credentials: queue.Credentials{AccessKeyID: "scoped", SecretAccessKey: "scopedsecret", SessionToken: "token"},
+		Expiration:  time.Now().Add(time.Hour),
+	})
+
+	creds, err := scopedCredentialsForHandler(context.Background(), c, Options{ScopedCredentials: true, RunContext: testRun})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := taskProcessor{ctx: context.Background(), handlerCreds: creds}.handlerEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"lunchpail_queue_accessKeyID=scoped", "lunchpail_queue_secretAccessKey=scopedsecret", "lunchpail_queue_sessionToken=token"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("expected handler env to contain %s", want)
+		}
+	}
+	for _, v := range env {
+		if strings.Contains(v, "R00T") || strings.HasPrefix(v, queue.CredentialsFileEnvVar+"=") {
+			t.Errorf("expected handler env not to contain our credentials, found %s", v)
+		}
+	}
+}
+
+func TestNoFallbackToRootCredentials(t *testing.T) {
+	c := queuetest.NewClient(t)
+	answerNextRequest(t, c, minio.VendedCredentials{Error: "STS is not enabled"})
+
+	if _, err := scopedCredentialsForHandler(context.Background(), c, Options{ScopedCredentials: true, RunContext: testRun}); err == nil {
+		t.Fatal("expected an error when the queue server cannot mint credentials")
+	}
+}
+
+func TestUnmanagedQueueInheritsCredentials(t *testing.T) {
+	creds, err := scopedCredentialsForHandler(context.Background(), s3.S3Client{}, Options{RunContext: testRun})
+	if err != nil || creds != nil {
+		t.Fatalf("expected no scoped credentials, got %v err=%v", creds, err)
+	}
+
+	if env, err := (taskProcessor{handlerCreds: creds}).handlerEnv(); err != nil || env != nil {
+		t.Fatalf("expected the handler to inherit our environment, got %v err=%v", env, err)
+	}
+}
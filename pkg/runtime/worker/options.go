@@ -15,6 +15,26 @@ type Options struct {
 	// Gunzip inputs before passing them to the worker logic
 	Gunzip bool
 
+	// Claim each task upon completion, and discard our output if
+	// a speculative copy of the task finished first
+	Speculate bool
+
+	// The handler is a task dispatcher, whose logs we ship as such,
+	// and which affects the scope of the queue credentials we give it
+	Dispatcher bool
+
+	// Ask the queue server to mint scoped queue credentials for the
+	// handler, rather than letting it inherit ours
+	ScopedCredentials bool
+
+	// Confine the handler to its workdir, its task files, and the
+	// queue
+	Sandbox bool
+
+	// With Sandbox, run the handler even if this kernel cannot
+	// confine its network access
+	SandboxUnconfinedNet bool
+
 	hlir.CallingConvention
 	queue.RunContext
 	StartupDelay    int
@@ -17,7 +17,9 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/runtime/logs"
 	s3 "lunchpail.io/pkg/runtime/queue"
+	"lunchpail.io/pkg/runtime/sandbox"
 	"lunchpail.io/pkg/util"
 )
 
@@ -29,6 +31,13 @@ type taskProcessor struct {
 	opts              Options
 	lockfile          string
 	backgroundS3Tasks *errgroup.Group
+	shipper           *logs.Shipper
+
+	// Queue credentials for the handler, or nil to let it inherit ours
+	handlerCreds *handlerCredentials
+
+	// Confine the handler to this policy, if not nil
+	sandbox *sandbox.Policy
 }
 
 // Process one task by invoking the given `handler` command line on
@@ -79,6 +88,15 @@ func (p taskProcessor) process(task string) error {
 	defer stdoutWriter.Close()
 	defer stderrWriter.Close()
 
+	// If a speculative copy of this task finishes first, we will
+	// cancel the handler and discard our output
+	taskCtx, cancel := context.WithCancel(p.ctx)
+	defer cancel()
+	if p.opts.Speculate {
+		go p.watchForRivalClaim(taskCtx, cancel, taskContext)
+	}
+	lost := false
+
 	// Here is where we invoke the underlying task handler
 	var stdin io.Reader
 	handlerArgs := p.handler[1:]
@@ -142,20 +160,53 @@ func (p taskProcessor) process(task string) error {
 		handlerArgs = append(handlerArgs, p.lockfile)                                                 // argv[4] is the lockfile
 		// Note: we will RemoveAll(localoutbox) in handleOutbox
 
-		defer p.handleOutbox(taskContext, inprogress, localoutbox, doneMovingToProcessing)
+		defer func() {
+			if lost {
+				p.discardOutbox(taskContext, inprogress, localoutbox, doneMovingToProcessing)
+			} else {
+				p.handleOutbox(taskContext, inprogress, localoutbox, doneMovingToProcessing)
+			}
+		}()
 	}
 
-	handlercmd := exec.CommandContext(p.ctx, p.handler[0], handlerArgs...)
+	handlercmd := exec.CommandContext(taskCtx, p.handler[0], handlerArgs...)
 	handlercmd.Stdin = stdin
-	handlercmd.Stderr = io.MultiWriter(os.Stderr, stderrWriter)
-	handlercmd.Stdout = io.MultiWriter(os.Stdout, stdoutWriter)
+	if env, err := p.handlerEnv(); err != nil {
+		p.failBeforeLaunch(taskContext, stderrWriter, err)
+		return nil
+	} else {
+		handlercmd.Env = env
+	}
+	if p.sandbox != nil {
+		if err := p.confine(handlercmd); err != nil {
+			p.failBeforeLaunch(taskContext, stderrWriter, fmt.Errorf("Unable to sandbox the handler: %v", err))
+			return nil
+		}
+	}
+	// Tee the handler's output to our own stdout/stderr, prefixing
+	// each line with the task and attempt, and shipping it to the
+	// log archive attributed to this task
+	attempt := p.recordAttempt(taskContext)
+	handlerStdout := p.shipper.Stdout(task, attempt)
+	handlerStderr := p.shipper.Stderr(task, attempt)
+	defer handlerStdout.Close()
+	defer handlerStderr.Close()
+	handlercmd.Stderr = io.MultiWriter(handlerStderr, stderrWriter)
+	handlercmd.Stdout = io.MultiWriter(handlerStdout, stdoutWriter)
 	TaskStartTime := time.Now()
 	if p.opts.LogOptions.Verbose {
 		fmt.Fprintf(os.Stderr, "METRICS: Took %s for worker to get to starting task\n", util.RelTime(p.opts.WorkerStartTime, TaskStartTime))
 	}
-	if err := handlercmd.Run(); err != nil {
+	if err := handlercmd.Run(); err != nil && (taskCtx.Err() == nil || p.ctx.Err() != nil) {
 		fmt.Fprintln(os.Stderr, "Handler launch failed:", err)
 	}
+
+	if p.opts.Speculate && !p.claim(taskContext) {
+		fmt.Fprintf(os.Stderr, "Discarding output of task %s, as a speculative copy finished first\n", task)
+		lost = true
+		return nil
+	}
+
 	// Clean things up
 	p.handleExitCode(taskContext, handlercmd.ProcessState.ExitCode())
 
@@ -184,6 +235,65 @@ func (p taskProcessor) streamStdout(taskContext queue.RunContext) (*io.PipeWrite
 	return stdoutWriter, stderrWriter, stdoutReader
 }
 
+// Record that we are attempting the given task, returning which
+// attempt this is (starting from 1). A task may be attempted more
+// than once, e.g. if the workstealer reassigns it after a worker dies.
+func (p taskProcessor) recordAttempt(taskContext queue.RunContext) int {
+	attempt, err := p.client.MarkNext(taskContext.Bucket, taskContext.AsFile(queue.TaskAttempts), taskContext.PoolName+"/"+taskContext.WorkerName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Internal Error recording attempt of task %s: %v\n", taskContext.Task, err)
+		return 0
+	}
+
+	return attempt
+}
+
+// The worker that has claimed the given task, if any
+func (p taskProcessor) claimant(taskContext queue.RunContext) (string, bool) {
+	claim := taskContext.AsFile(queue.TaskClaim)
+	if !p.client.Exists(taskContext.Bucket, claim, "") {
+		return "", false
+	}
+
+	owner, err := p.client.Get(taskContext.Bucket, claim)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Internal Error reading claim on task %s: %v\n", taskContext.Task, err)
+		return "", false
+	}
+	return owner, true
+}
+
+// Claim the given task as ours, unless some other copy of it has
+// already been claimed. Only the claimant may publish its output.
+func (p taskProcessor) claim(taskContext queue.RunContext) bool {
+	me := taskContext.PoolName + "/" + taskContext.WorkerName
+	owner, err := p.client.Claim(taskContext.Bucket, taskContext.AsFile(queue.TaskClaim), me)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Internal Error claiming task %s: %v\n", taskContext.Task, err)
+		return false
+	}
+	return owner == me
+}
+
+// Cancel the task if some other copy of it finishes first
+func (p taskProcessor) watchForRivalClaim(ctx context.Context, cancel context.CancelFunc, taskContext queue.RunContext) {
+	me := taskContext.PoolName + "/" + taskContext.WorkerName
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(max(1, p.opts.PollingInterval)) * time.Second):
+			if owner, ok := p.claimant(taskContext); ok && owner != me {
+				if p.opts.LogOptions.Verbose {
+					fmt.Fprintf(os.Stderr, "Cancelling task %s, as %s finished it first\n", taskContext.Task, owner)
+				}
+				cancel()
+				return
+			}
+		}
+	}
+}
+
 // Report and upload exit code
 func (p taskProcessor) handleExitCode(taskContext queue.RunContext, exitCode int) {
 	p.backgroundS3Tasks.Go(func() error {
@@ -201,6 +311,14 @@ func (p taskProcessor) handleExitCode(taskContext queue.RunContext, exitCode int
 	}
 }
 
+// Record that the given task failed before we could launch its
+// handler, so that it is not silently dropped
+func (p taskProcessor) failBeforeLaunch(taskContext queue.RunContext, stderr io.Writer, err error) {
+	fmt.Fprintf(os.Stderr, "Internal Error launching handler for task %s: %v\n", taskContext.Task, err)
+	fmt.Fprintln(stderr, err)
+	p.handleExitCode(taskContext, 1)
+}
+
 // Upload output from task processing
 func (p taskProcessor) handleOutbox(taskContext queue.RunContext, inprogress, localoutbox string, doneMovingToProcessing chan struct{}) {
 	outputFiles, err := os.ReadDir(localoutbox)
@@ -244,6 +362,18 @@ func (p taskProcessor) handleOutbox(taskContext queue.RunContext, inprogress, lo
 	}
 }
 
+// Throw away output from a task whose speculative copy won
+func (p taskProcessor) discardOutbox(taskContext queue.RunContext, inprogress, localoutbox string, doneMovingToProcessing chan struct{}) {
+	if err := os.RemoveAll(localoutbox); err != nil {
+		fmt.Fprintln(os.Stderr, "Internal Error removing local outbox:", err)
+	}
+
+	p.backgroundS3Tasks.Go(func() error {
+		<-doneMovingToProcessing
+		return p.client.Rm(taskContext.Bucket, inprogress)
+	})
+}
+
 func gunzip(filename string) (string, error) {
 	// Open the gzip file
 	gzipFile, err := os.Open(filename)
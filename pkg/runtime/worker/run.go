@@ -6,6 +6,8 @@ import (
 	"os"
 	"time"
 
+	"lunchpail.io/pkg/lunchpail"
+	"lunchpail.io/pkg/runtime/logs"
 	s3 "lunchpail.io/pkg/runtime/queue"
 )
 
@@ -33,5 +35,16 @@ func Run(ctx context.Context, handler []string, opts Options) error {
 		return err
 	}
 
-	return startWatch(ctx, handler, client, opts)
+	component := lunchpail.WorkersComponent
+	if opts.Dispatcher {
+		component = lunchpail.DispatcherComponent
+	}
+
+	shipper, err := logs.Start(ctx, client, opts.RunContext, component, opts.LogOptions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unable to ship logs to the queue: %v\n", err)
+	}
+	defer shipper.Stop()
+
+	return startWatch(ctx, handler, client, shipper, opts)
 }
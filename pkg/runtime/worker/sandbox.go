@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+
+	"lunchpail.io/pkg/runtime/sandbox"
+)
+
+// System paths that handlers may read
+var systemPaths = []string{"/bin", "/sbin", "/usr", "/lib", "/lib32", "/lib64", "/etc", "/opt", "/nix", "/proc", "/sys"}
+
+// Devices that handlers may read and write. Others, e.g. disks, are
+// off limits.
+var devicePaths = []string{"/dev/null", "/dev/zero", "/dev/full", "/dev/tty", "/dev/shm", "/dev/pts"}
+
+// Devices that handlers may read
+var readOnlyDevicePaths = []string{"/dev/random", "/dev/urandom"}
+
+// Confine the handler to our workdir (which holds the code and
+// datasets of the application), its task files, and the queue
+// endpoint, or nil if we were not asked to sandbox the handler
+func sandboxPolicyForHandler(opts Options, localdir, lockfile string) (*sandbox.Policy, error) {
+	if !opts.Sandbox {
+		return nil, nil
+	}
+
+	if err := sandbox.Available(opts.SandboxUnconfinedNet); err != nil {
+		return nil, err
+	}
+
+	workdir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := handlerPolicy(workdir, localdir, lockfile, os.Getenv("lunchpail_queue_endpoint"))
+	if err != nil {
+		return nil, err
+	}
+	policy.AllowUnconfinedNetwork = opts.SandboxUnconfinedNet
+
+	return &policy, nil
+}
+
+func handlerPolicy(workdir, localdir, lockfile, endpoint string) (sandbox.Policy, error) {
+	port, err := sandbox.PortOf(endpoint)
+	if err != nil {
+		return sandbox.Policy{}, err
+	}
+
+	policy := sandbox.Policy{
+		ReadWrite:    append([]string{workdir, localdir, lockfile}, devicePaths...),
+		ReadOnly:     append(slices.Clone(systemPaths), readOnlyDevicePaths...),
+		ConnectPorts: []int{port},
+	}
+
+	// The handler may use lunchpail, e.g. to dispatch tasks, and
+	// anything installed by `lunchpail needs` (which it finds via
+	// PATH)
+	if exe, err := os.Executable(); err == nil {
+		policy.ReadOnly = append(policy.ReadOnly, filepath.Dir(exe))
+	}
+	policy.ReadOnly = append(policy.ReadOnly, filepath.SplitList(os.Getenv("PATH"))...)
+	if cachedir, err := os.UserCacheDir(); err == nil {
+		policy.ReadOnly = append(policy.ReadOnly, filepath.Join(cachedir, "lunchpail"))
+	}
+	if venvs := os.Getenv("LUNCHPAIL_VENV_CACHEDIR"); venvs != "" {
+		policy.ReadOnly = append(policy.ReadOnly, venvs)
+	}
+
+	// Our queue credentials file is deliberately absent: the
+	// handler gets its own scoped credentials via its environment
+
+	return policy, nil
+}
+
+// Run the handler command confined by our sandbox policy
+func (p taskProcessor) confine(cmd *exec.Cmd) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd.Args = p.sandbox.Wrap(exe, cmd.Args)
+	cmd.Path = exe
+	cmd.Err = nil
+
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, "TMPDIR="+p.localdir)
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"lunchpail.io/pkg/ir/queue"
+)
+
+func TestHandlerPolicy(t *testing.T) {
+	t.Setenv(queue.CredentialsFileEnvVar, "/secrets/credentials.json")
+
+	policy, err := handlerPolicy("/work", "/tmp/local", "/tmp/lock", "https://queue:9443")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/work", "/tmp/local", "/tmp/lock", "/dev/null"} {
+		if !slices.Contains(policy.ReadWrite, path) {
+			t.Errorf("expected the handler to be able to write %s", path)
+		}
+	}
+	if !slices.Contains(policy.ReadOnly, "/dev/urandom") || slices.Contains(policy.ReadWrite, "/dev/urandom") {
+		t.Error("expected the handler to be able only to read /dev/urandom")
+	}
+
+	// Not all of /dev, e.g. disks
+	for _, path := range slices.Concat(policy.ReadWrite, policy.ReadOnly) {
+		if path == "/dev" || path == "/dev/" {
+			t.Errorf("expected the handler not to have access to all of /dev")
+		}
+		if strings.Contains(path, "credentials") {
+			t.Errorf("expected the handler not to have access to our queue credentials, found %s", path)
+		}
+	}
+
+	if !slices.Equal(policy.ConnectPorts, []int{9443}) {
+		t.Errorf("expected the handler to connect only to the queue port, got %v", policy.ConnectPorts)
+	}
+	if policy.AllowUnconfinedNetwork {
+		t.Error("expected the handler policy to fail closed without network confinement")
+	}
+}
+
+func TestHandlerPolicyNeedsQueuePort(t *testing.T) {
+	// The local backend gives a scheme-less host:port
+	policy, err := handlerPolicy("/work", "/tmp/local", "/tmp/lock", "localhost:9000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(policy.ConnectPorts, []int{9000}) {
+		t.Errorf("expected the handler to connect only to the queue port, got %v", policy.ConnectPorts)
+	}
+
+	if _, err := handlerPolicy("/work", "/tmp/local", "/tmp/lock", "queue"); err == nil {
+		t.Fatal("expected an error for a queue endpoint without a port")
+	}
+}
+
+func TestNoSandbox(t *testing.T) {
+	if policy, err := sandboxPolicyForHandler(Options{}, "/tmp/local", "/tmp/lock"); err != nil || policy != nil {
+		t.Fatalf("expected no sandbox policy, got %v err=%v", policy, err)
+	}
+}
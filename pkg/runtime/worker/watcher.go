@@ -13,10 +13,11 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/runtime/logs"
 	s3 "lunchpail.io/pkg/runtime/queue"
 )
 
-func startWatch(ctx context.Context, handler []string, client s3.S3Client, opts Options) error {
+func startWatch(ctx context.Context, handler []string, client s3.S3Client, shipper *logs.Shipper, opts Options) error {
 	if opts.LogOptions.Verbose {
 		defer func() {
 			fmt.Fprintf(os.Stderr, "Worker exiting step=%d pool=%s worker=%s\n", opts.RunContext.Step, opts.RunContext.PoolName, opts.RunContext.WorkerName)
@@ -79,8 +80,18 @@ func startWatch(ctx context.Context, handler []string, client s3.S3Client, opts
 		s = 3
 	}
 
+	sandbox, err := sandboxPolicyForHandler(opts, localdir, lockfile.Name())
+	if err != nil {
+		return err
+	}
+
+	handlerCreds, err := scopedCredentialsForHandler(ctx, client, opts)
+	if err != nil {
+		return err
+	}
+
 	backgroundS3Tasks, _ := errgroup.WithContext(ctx)
-	p := taskProcessor{ctx, client, handler, localdir, opts, lockfile.Name(), backgroundS3Tasks}
+	p := taskProcessor{ctx, client, handler, localdir, opts, lockfile.Name(), backgroundS3Tasks, shipper, handlerCreds, sandbox}
 
 	sleepNextTime := false
 	tasks, errs := client.Listen(opts.RunContext.Bucket, inboxPrefix, "", false)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize/english"
 
@@ -204,6 +205,66 @@ func (c client) rebalance(m queuestreamer.Step) bool {
 	return false
 }
 
+// As part of speculatively re-executing a straggling Task, copy it
+// from the processing folder of the original Worker to the inbox of
+// an idle Worker
+func (c client) copyToWorkerInbox(step int, task string, from, to queuestreamer.Worker) error {
+	processingFilePath := c.RunContext.ForStep(step).ForPool(from.Pool).ForWorker(from.Name).ForTask(task).AsFile(queue.AssignedAndProcessing)
+	workerInboxFilePath := c.RunContext.ForStep(step).ForPool(to.Pool).ForWorker(to.Name).ForTask(task).AsFile(queue.AssignedAndPending)
+	if c.LogOptions.Verbose {
+		fmt.Fprintf(os.Stderr, "Uploading copied file: %s -> %s\n", processingFilePath, workerInboxFilePath)
+	}
+
+	return c.s3.Copyto(c.RunContext.Bucket, processingFilePath, c.RunContext.Bucket, workerInboxFilePath)
+}
+
+// Launch a speculative copy of straggling Tasks on idle Workers.
+// Workers claim a Task upon completion, so that whichever copy
+// finishes first wins, and the other copy's output is discarded.
+func (c client) speculateStragglers(m queuestreamer.Step) {
+	if !c.speculate || m.Index != c.RunContext.Step || len(m.UnassignedTasks) > 0 || len(m.Stragglers) == 0 {
+		// Speculation is not enabled, or this step is
+		// another build's concern, or we have better uses
+		// for idle workers, or there is nothing to speculate
+		return
+	}
+
+	idle := []queuestreamer.Worker{}
+	for _, worker := range m.LiveWorkers {
+		if len(worker.AssignedTasks) == 0 && len(worker.ProcessingTasks) == 0 && !worker.KillfilePresent {
+			idle = append(idle, worker)
+		}
+	}
+
+	for _, straggler := range m.Stragglers {
+		if len(idle) == 0 {
+			break
+		}
+
+		k := fmt.Sprintf("%d/%s", m.Index, straggler.Task)
+		if c.speculated[k] {
+			continue
+		}
+		c.speculated[k] = true
+
+		worker := idle[0]
+		idle = idle[1:]
+
+		fmt.Fprintf(
+			os.Stderr,
+			"Speculatively re-executing step=%d task=%s on worker=%s, as it has been running for %s on worker=%s\n",
+			m.Index,
+			straggler.Task,
+			strings.Replace(worker.Name, c.RunContext.RunName+"-", "", 1),
+			straggler.Elapsed.Round(time.Second),
+			strings.Replace(straggler.Worker, c.RunContext.RunName+"-", "", 1),
+		)
+		if err := c.copyToWorkerInbox(m.Index, straggler.Task, queuestreamer.Worker{Pool: straggler.Pool, Name: straggler.Worker}, worker); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+	}
+}
+
 // Touch kill files in the worker inboxes.
 func (c client) touchKillFiles(m queuestreamer.Step) {
 	for _, worker := range m.LiveWorkers {
@@ -243,5 +304,6 @@ func (c client) assess(model queuestreamer.Model, m queuestreamer.Step) {
 		}
 
 		c.reassignDeadWorkerTasks(m)
+		c.speculateStragglers(m)
 	}
 }
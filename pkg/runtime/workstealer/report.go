@@ -37,6 +37,15 @@ func (c client) report(m queuestreamer.Step) error {
 			len(worker.AssignedTasks), len(worker.ProcessingTasks), worker.NSuccess, worker.NFail, worker.Pool, worker.Name, c.RunContext.RunName,
 		)
 	}
+	if m.Durations.N > 0 {
+		fmt.Fprintf(writer, "lunchpail.io\tdurations\t%s\t\t\t\t\t%s\n", m.Durations, c.RunContext.RunName)
+	}
+	for _, straggler := range m.Stragglers {
+		fmt.Fprintf(
+			writer, "lunchpail.io\tstraggler\t%s\t%s\t\t\t%s/%s\t%s\n",
+			straggler.Task, straggler.Elapsed.Round(time.Second), straggler.Pool, straggler.Worker, c.RunContext.RunName,
+		)
+	}
 	fmt.Fprintln(writer, "lunchpail.io\t---")
 
 	writer.Flush()
@@ -11,8 +11,12 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"lunchpail.io/pkg/build"
+	"lunchpail.io/pkg/ir/hlir"
 	"lunchpail.io/pkg/ir/queue"
+	"lunchpail.io/pkg/lunchpail"
 	"lunchpail.io/pkg/observe/queuestreamer"
+	"lunchpail.io/pkg/runtime/alerts"
+	"lunchpail.io/pkg/runtime/logs"
 	s3 "lunchpail.io/pkg/runtime/queue"
 	"lunchpail.io/pkg/util"
 )
@@ -24,6 +28,12 @@ type Options struct {
 	// Automatically tear down the run when all output has been consumed?
 	SelfDestruct bool
 
+	// Where to send notifications of run events
+	Alerts []hlir.AlertSpec
+
+	// Re-execute straggling tasks on idle workers
+	Speculate bool
+
 	build.LogOptions
 }
 
@@ -32,6 +42,12 @@ type client struct {
 	queue.RunContext
 	pathPatterns queuestreamer.PathPatterns
 	build.LogOptions
+
+	// Re-execute straggling tasks on idle workers
+	speculate bool
+
+	// Stragglers we have already re-executed, indexed by step/task
+	speculated map[string]bool
 }
 
 func printenv() {
@@ -46,8 +62,19 @@ func Run(ctx context.Context, run queue.RunContext, opts Options) error {
 		return err
 	}
 
+	shipper, err := logs.Start(ctx, s3, run, lunchpail.WorkStealerComponent, opts.LogOptions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: unable to ship logs to the queue: %v\n", err)
+	}
+	defer shipper.Stop()
+
 	group, gctx := errgroup.WithContext(ctx)
 
+	monitor, err := alerts.NewMonitor(gctx, s3, run, opts.Alerts)
+	if err != nil {
+		return err
+	}
+
 	// Set up a streamer of Models to modelChan. We will tell the
 	// streamer when we want it to terminate via doneChan.
 	modelChan := make(chan queuestreamer.Model)
@@ -63,7 +90,7 @@ func Run(ctx context.Context, run queue.RunContext, opts Options) error {
 		return err
 	})
 
-	c := client{s3, run, queuestreamer.NewPathPatterns(run), opts.LogOptions}
+	c := client{s3, run, queuestreamer.NewPathPatterns(run), opts.LogOptions, opts.Speculate, make(map[string]bool)}
 	fmt.Fprintln(os.Stderr, "Workstealer starting")
 	if opts.Verbose {
 		fmt.Fprintf(os.Stderr, "Run: %v\n", run)
@@ -76,14 +103,19 @@ func Run(ctx context.Context, run queue.RunContext, opts Options) error {
 	// chatter to S3.
 	debounced := debounce.New(200 * time.Millisecond)
 
+	monitor.Start()
+
 	var mu sync.Mutex
 	for model := range modelChan {
 		debounced(func() {
 			mu.Lock()
 			defer mu.Unlock()
 
+			monitor.Update(model)
+
 			if readyToBye(model) {
 				fmt.Fprintln(os.Stderr, "All work for this run has been completed, all workers have terminated")
+				monitor.Complete()
 				// notify the streamer we are done
 				if opts.Verbose {
 					fmt.Fprintln(os.Stderr, "Workstealer assessor has initiated a shutdown")
@@ -107,9 +139,15 @@ func Run(ctx context.Context, run queue.RunContext, opts Options) error {
 		})
 	}
 
+	// Make sure any final notifications have been delivered
+	monitor.Wait()
+
 	// Drop a final breadcrumb indicating we are ready to tear
 	// down all associated resources
 	if opts.SelfDestruct {
+		// The queue may disappear once we touch AllDone, so ship what we have now
+		shipper.Flush()
+
 		fmt.Fprintf(os.Stderr, "Instructing the run to self-destruct bucket=%s file=%s\n", c.RunContext.Bucket, c.RunContext.AsFile(queue.AllDoneMarker))
 		if err := s3.Touch(c.RunContext.Bucket, c.RunContext.AsFile(queue.AllDoneMarker)); err != nil {
 			fmt.Fprintf(os.Stderr, "Unable to touch AllDone file\n%v\n", err)
@@ -0,0 +1,98 @@
+package util
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Tar up the given directory into a gzipped tarball that depends only
+// on the names, modes, and content of the files: entries are sorted,
+// owners are anonymized, and every timestamp is `mtime`. Files and
+// directories for which `skip` returns true are left out.
+func TarReproducibly(tarball, dir string, skip func(name string) bool, mtime time.Time) error {
+	f, err := os.Create(tarball)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Note: a zero gzip header has no name or timestamp
+	gzw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gzw)
+
+	// Note: WalkDir visits entries in lexical order
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		if rel != "." && skip(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			// e.g. symlinks, which Untar() ignores anyway
+			return nil
+		}
+
+		header := &tar.Header{
+			Name:    "./" + filepath.ToSlash(rel),
+			Mode:    int64(info.Mode().Perm()),
+			ModTime: mtime,
+		}
+		if info.IsDir() {
+			header.Typeflag = tar.TypeDir
+			header.Name += "/"
+		} else {
+			header.Typeflag = tar.TypeReg
+			header.Size = info.Size()
+		}
+		if rel == "." {
+			header.Name = "./"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	} else if err := gzw.Close(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}